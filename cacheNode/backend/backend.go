@@ -3,12 +3,16 @@ package backend
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"sync"
 
+	"github.com/hcl/cdn/cacheNode/cachePolicy"
 	"github.com/hcl/cdn/cacheNode/common"
 	"github.com/hcl/cdn/cacheNode/config"
 	"github.com/hcl/cdn/cacheNode/observability"
+	coCfg "github.com/hcl/cdn/common/config"
+	"github.com/hcl/cdn/common/helper"
 )
 
 type Backend struct {
@@ -25,14 +29,20 @@ func (b *Backend) Do(req *http.Request) (response *http.Response, err error) {
 		return
 	}
 	fmt.Println(modReq)
-	response, err = getObject(b.ctx, modReq)
+	response, err = fetch(b.ctx, modReq, ds, b.cfg, b.observabilityHanlder)
 	if err != nil {
 		return
 	}
-	response, err = headerRewriter(req, response, ds)
+	response, err = headerRewriter(req, response, ds, b.cfg.NodeName())
 	if err != nil {
 		return
 	}
+	addCompressionVary(response, ds)
+	dropCORSVary(response, ds)
+	if !cachePolicy.IsStorable(req, response) {
+		slog.Info("BE Response must not be cached, not saving to store", "url", helper.GetString(req), "status", response.StatusCode)
+		return
+	}
 	response, copyresp, err := forker(response)
 	if err != nil {
 		return
@@ -40,7 +50,7 @@ func (b *Backend) Do(req *http.Request) (response *http.Response, err error) {
 	b.wg.Add(1)
 	go func(wg *sync.WaitGroup) {
 		defer wg.Done()
-		save(b.ctx, copyresp, req, b.store)
+		save(b.ctx, copyresp, req, ds, b.store)
 	}(b.wg)
 	return
 }
@@ -51,19 +61,37 @@ func (b *Backend) ReDo(req *http.Request, oldResp *http.Response) (response *htt
 		return
 	}
 	fmt.Println(modReq)
-	//Add the Request Headers based on resp
-	//IF oldResp had ETAG Header is available - include If-Match in modReq
-	//if oldResp had Last-Modified-Since is available - include If-Unmodified-Since in modReq
-	response, err = getObject(b.ctx, modReq)
+	//Revalidate the stale copy, ETag goes as If-None-Match and Last-Modified as If-Modified-Since
+	addValidators(modReq, oldResp)
+	response, err = fetch(b.ctx, modReq, ds, b.cfg, b.observabilityHanlder)
 	if err != nil {
 		return
 	}
-	//304 Not Modified return
-	// If recache required? should storage be updated?
-	response, err = headerRewriter(req, response, ds)
+	//304 Not Modified: serve the stale copy again, only its metadata is updated in storage
+	if response.StatusCode == http.StatusNotModified && oldResp != nil {
+		var fresh http.Header
+		response, fresh, err = revalidated(req, oldResp, response, ds, b.cfg.NodeName())
+		if err != nil {
+			return
+		}
+		storedHeader := response.Header.Clone()
+		b.wg.Add(1)
+		go func(wg *sync.WaitGroup) {
+			defer wg.Done()
+			updateMetadata(b.ctx, fresh, storedHeader, req, ds, b.store)
+		}(b.wg)
+		return
+	}
+	response, err = headerRewriter(req, response, ds, b.cfg.NodeName())
 	if err != nil {
 		return
 	}
+	addCompressionVary(response, ds)
+	dropCORSVary(response, ds)
+	if !cachePolicy.IsStorable(req, response) {
+		slog.Info("BE Response must not be cached, not saving to store", "url", helper.GetString(req), "status", response.StatusCode)
+		return
+	}
 	response, copyresp, err := forker(response)
 	if err != nil {
 		return
@@ -71,7 +99,27 @@ func (b *Backend) ReDo(req *http.Request, oldResp *http.Response) (response *htt
 	b.wg.Add(1)
 	go func(wg *sync.WaitGroup) {
 		defer wg.Done()
-		save(b.ctx, copyresp, req, b.store)
+		save(b.ctx, copyresp, req, ds, b.store)
 	}(b.wg)
 	return
 }
+
+// addCompressionVary marks the response the frontend compresses for the clients as varying on Accept-Encoding,
+// the identity copy and the compressed copies are then stored as variants of the object
+func addCompressionVary(resp *http.Response, ds *coCfg.DeliveryService) {
+	if ds == nil || resp.StatusCode != http.StatusOK {
+		return
+	}
+	if cachePolicy.Compressible(&ds.Compression, resp.Header) {
+		cachePolicy.AddVary(resp.Header, "Accept-Encoding")
+	}
+}
+
+// dropCORSVary keeps one stored copy for all the Origins of a DS with a CORS policy,
+// the frontend sets the Access-Control headers for each client
+func dropCORSVary(resp *http.Response, ds *coCfg.DeliveryService) {
+	if ds == nil || !ds.CORS.Enabled {
+		return
+	}
+	cachePolicy.RemoveVary(resp.Header, "Origin")
+}
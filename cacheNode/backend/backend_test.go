@@ -10,6 +10,7 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"strconv"
+	"strings"
 	"sync"
 	"testing"
 
@@ -19,6 +20,8 @@ import (
 	commonConfig "github.com/hcl/cdn/common/config"
 
 	"github.com/hcl/cdn/cacheNode/observability"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 	// "github.com/hcl/cdn/cacheNode/storage"
 )
 
@@ -38,7 +41,8 @@ func TestBackend_Do(t *testing.T) {
 	Port, _ := strconv.Atoi(tsURL.Port())
 	fmt.Println(IPadd, Port)
 
-	ctx := context.Background()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 	wg := &sync.WaitGroup{}
 	Rules := []commonConfig.RewriteRule{
 		{
@@ -135,7 +139,8 @@ func TestBackend_Do(t *testing.T) {
 	Port, _ := strconv.Atoi(tsURL.Port())
 	fmt.Println(IPadd, Port)
 
-	ctx := context.Background()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 	wg := &sync.WaitGroup{}
 
 	ds_tmp := commonConfig.DeliveryService{Name: "DS1", ClientURL: "http://example.com", OriginURL: "http://originurl.com", RewriteRules: []commonConfig.RewriteRule{}}
@@ -189,3 +194,369 @@ func TestBackend_Do(t *testing.T) {
 
 }
 */
+
+// patchRecorder is a store which records the metadata updates
+type patchRecorder struct {
+	mu      sync.Mutex
+	patches []*http.Request
+}
+
+func (p *patchRecorder) Do(req *http.Request) (*http.Response, error) {
+	if req.Method == http.MethodPatch {
+		p.mu.Lock()
+		p.patches = append(p.patches, req)
+		p.mu.Unlock()
+	}
+	return &http.Response{StatusCode: http.StatusOK, Header: make(http.Header)}, nil
+}
+
+func TestBackend_ReDoNotModified(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.Header().Set("Cache-Control", "max-age=120")
+			w.Header().Set("ETag", `"v1"`)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Write([]byte("new content"))
+	}))
+	defer ts.Close()
+	tsURL, _ := url.Parse(ts.URL)
+	Port, _ := strconv.Atoi(tsURL.Port())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+	ds_tmp := commonConfig.DeliveryService{Name: "DS1", ClientURL: "http://example.com", OriginURL: "http://originurl.com"}
+	dss := commonConfig.DeliveryServices{Version: 1, ServiceList: []commonConfig.DeliveryService{ds_tmp}}
+	cfg := config.RunConfig{
+		Valid:    true,
+		Filename: "test",
+		Node: &commonConfig.CacheNode{
+			IP:         "192.168.1.1",
+			Port:       8080,
+			Type:       commonConfig.CacheNodeEdge,
+			ParentIP:   tsURL.Hostname(),
+			ParentPort: Port},
+		ServiceList: &dss,
+	}
+	store := &patchRecorder{}
+	backhandler, err := backend.Init(ctx, wg, &cfg, store, nil)
+	if err != nil {
+		t.Fatalf("Backend Initialization failed")
+	}
+
+	oldResp := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader("cached content")),
+	}
+	oldResp.Header.Set("ETag", `"v1"`)
+	oldResp.Header.Set("Cache-Control", "max-age=10")
+	oldResp.Header.Set("Age", "50")
+	oldResp.Header.Set("Content-Length", "14")
+
+	req, _ := http.NewRequest("GET", "http://example.com/a.txt", nil)
+	resp, err := backhandler.ReDo(req, oldResp)
+	if err != nil {
+		t.Fatalf("ReDo failed: %v", err)
+	}
+	cancel()
+	wg.Wait()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "cached content" {
+		t.Errorf("expected the cached content, got %q", body)
+	}
+	if resp.Header.Get("Cache-Control") != "max-age=120" || resp.Header.Get("Age") != "0" {
+		t.Errorf("expected fresh headers, got %v", resp.Header)
+	}
+	if len(store.patches) != 1 {
+		t.Fatalf("expected one metadata update, got %d", len(store.patches))
+	}
+	if got := store.patches[0].Header.Get("Cache-Control"); got != "max-age=120" {
+		t.Errorf("unexpected Cache-Control in metadata update %q", got)
+	}
+}
+
+// A stale HEAD hit comes from storage without body, the 304 of the origin still revalidates it
+func TestBackend_ReDoNotModifiedHead(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.Header().Set("Cache-Control", "max-age=120")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v2"`)
+	}))
+	defer ts.Close()
+	tsURL, _ := url.Parse(ts.URL)
+	Port, _ := strconv.Atoi(tsURL.Port())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+	ds_tmp := commonConfig.DeliveryService{Name: "DS1", ClientURL: "http://example.com", OriginURL: "http://originurl.com"}
+	dss := commonConfig.DeliveryServices{Version: 1, ServiceList: []commonConfig.DeliveryService{ds_tmp}}
+	cfg := config.RunConfig{
+		Valid:    true,
+		Filename: "test",
+		Node: &commonConfig.CacheNode{
+			IP:         "192.168.1.1",
+			Port:       8080,
+			Type:       commonConfig.CacheNodeEdge,
+			ParentIP:   tsURL.Hostname(),
+			ParentPort: Port},
+		ServiceList: &dss,
+	}
+	store := &patchRecorder{}
+	backhandler, err := backend.Init(ctx, wg, &cfg, store, nil)
+	if err != nil {
+		t.Fatalf("Backend Initialization failed")
+	}
+
+	oldResp := &http.Response{StatusCode: http.StatusOK, Header: make(http.Header)}
+	oldResp.Header.Set("ETag", `"v1"`)
+	oldResp.Header.Set("Cache-Control", "max-age=10")
+	oldResp.Header.Set("Age", "50")
+	oldResp.Header.Set("Content-Length", "14")
+
+	req, _ := http.NewRequest(http.MethodHead, "http://example.com/a.txt", nil)
+	resp, err := backhandler.ReDo(req, oldResp)
+	if err != nil {
+		t.Fatalf("ReDo failed: %v", err)
+	}
+	cancel()
+	wg.Wait()
+
+	if resp.StatusCode != http.StatusOK || resp.Body == nil {
+		t.Fatalf("expected 200 with an empty body, got %d", resp.StatusCode)
+	}
+	if resp.Header.Get("Cache-Control") != "max-age=120" || resp.Header.Get("Content-Length") != "14" {
+		t.Errorf("expected the stored headers refreshed, got %v", resp.Header)
+	}
+	if len(store.patches) != 1 {
+		t.Errorf("expected one metadata update, got %d", len(store.patches))
+	}
+}
+
+func TestBackend_DoParentH2C(t *testing.T) {
+	var mu sync.Mutex
+	conns := make(map[string]bool)
+	h2s := &http2.Server{}
+	ts := httptest.NewServer(h2c.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor != 2 {
+			t.Errorf("expected HTTP/2 from the edge, got %s", r.Proto)
+		}
+		mu.Lock()
+		conns[r.RemoteAddr] = true
+		mu.Unlock()
+		w.Write([]byte("from " + r.Host))
+	}), h2s))
+	defer ts.Close()
+	tsURL, _ := url.Parse(ts.URL)
+	Port, _ := strconv.Atoi(tsURL.Port())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+	dss := commonConfig.DeliveryServices{Version: 1, ServiceList: []commonConfig.DeliveryService{
+		{Name: "DS1", ClientURL: "http://example.com", OriginURL: "http://originurl.com"},
+		{Name: "DS2", ClientURL: "http://example.org", OriginURL: "http://originurl.org"},
+	}}
+	cfg := config.RunConfig{
+		Valid:    true,
+		Filename: "test",
+		Node: &commonConfig.CacheNode{
+			IP:         "192.168.1.1",
+			Port:       8080,
+			Type:       commonConfig.CacheNodeEdge,
+			ParentIP:   tsURL.Hostname(),
+			ParentPort: Port,
+			ParentH2C:  true},
+		ServiceList: &dss,
+	}
+	store := &backendTestMock.RequestHandlerMock{HttpStatuscode: http.StatusOK, Header: map[string][]string{}}
+	backhandler, err := backend.Init(ctx, wg, &cfg, store, nil)
+	if err != nil {
+		t.Fatalf("Backend Initialization failed")
+	}
+
+	for _, host := range []string{"example.com", "example.org", "example.com"} {
+		req, _ := http.NewRequest("GET", "http://"+host+"/a.txt", nil)
+		resp, err := backhandler.Do(req)
+		if err != nil {
+			t.Fatalf("Do failed: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != "from "+host {
+			t.Errorf("unexpected body %q", body)
+		}
+	}
+	cancel()
+	wg.Wait()
+	if len(conns) != 1 {
+		t.Errorf("expected one multiplexed connection to the parent, got %d", len(conns))
+	}
+
+	// Back to HTTP/1.1 for the other tests
+	cfg.Node.ParentH2C = false
+	backend.Init(ctx, wg, &cfg, store, nil)
+}
+
+func TestBackend_DoRewritePhases(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Seen-Forwarded", r.Header.Get("X-Forwarded-For"))
+		w.Header().Set("X-Seen-Cookie", r.Header.Get("Cookie"))
+		w.Header().Set("Server", "origin/1.2")
+		w.Write([]byte("content"))
+	}))
+	defer ts.Close()
+	tsURL, _ := url.Parse(ts.URL)
+	Port, _ := strconv.Atoi(tsURL.Port())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+	rules := []commonConfig.RewriteRule{
+		{HeaderName: "X-Forwarded-For", Operation: commonConfig.HdrReWriteOpAppend, Value: "${client_ip}", Phase: commonConfig.HdrReWritePhaseRequest},
+		{HeaderName: "Cookie", Operation: commonConfig.HdrReWriteOpDelete, Phase: commonConfig.HdrReWritePhaseRequest, IfPath: `\.txt$`},
+		{HeaderName: "Server", Operation: commonConfig.HdrReWriteOpReplace, Pattern: `/[0-9.]+$`, Value: ""},
+		{HeaderName: "X-Served-By", Operation: commonConfig.HdrReWriteOpAdd, Value: "${node_name}", Phase: commonConfig.HdrReWritePhaseClient},
+	}
+	dss := commonConfig.DeliveryServices{Version: 1, ServiceList: []commonConfig.DeliveryService{
+		{Name: "DS1", ClientURL: "http://example.com", OriginURL: "http://originurl.com", RewriteRules: rules},
+	}}
+	cfg := config.RunConfig{
+		Valid:    true,
+		Filename: "test",
+		Node: &commonConfig.CacheNode{
+			Name:       "edge1",
+			IP:         "192.168.1.1",
+			Port:       8080,
+			Type:       commonConfig.CacheNodeEdge,
+			ParentIP:   tsURL.Hostname(),
+			ParentPort: Port},
+		ServiceList: &dss,
+	}
+	store := &backendTestMock.RequestHandlerMock{HttpStatuscode: http.StatusOK, Header: map[string][]string{}}
+	backhandler, err := backend.Init(ctx, wg, &cfg, store, nil)
+	if err != nil {
+		t.Fatalf("Backend Initialization failed")
+	}
+
+	req, _ := http.NewRequest("GET", "http://example.com/a.txt", nil)
+	req.RemoteAddr = "10.1.2.3:40000"
+	req.Header.Set("X-Forwarded-For", "192.0.2.1")
+	req.Header.Set("Cookie", "session=1")
+	resp, err := backhandler.Do(req)
+	if err != nil {
+		t.Fatalf("Do failed: %v", err)
+	}
+	io.ReadAll(resp.Body)
+	resp.Body.Close()
+	cancel()
+	wg.Wait()
+
+	if got := resp.Header.Get("X-Seen-Forwarded"); got != "192.0.2.1, 10.1.2.3" {
+		t.Errorf("unexpected X-Forwarded-For upstream %q", got)
+	}
+	if got := resp.Header.Get("X-Seen-Cookie"); got != "" {
+		t.Errorf("expected the cookie to be removed upstream, got %q", got)
+	}
+	if got := resp.Header.Get("Server"); got != "origin" {
+		t.Errorf("unexpected Server %q", got)
+	}
+	if got := resp.Header.Get("X-Served-By"); got != "" {
+		t.Errorf("client phase rules are applied by the frontend, got %q", got)
+	}
+	if req.Header.Get("X-Forwarded-For") != "192.0.2.1" || req.Header.Get("Cookie") == "" {
+		t.Errorf("the client request must not be changed, got %v", req.Header)
+	}
+}
+
+func TestParentMapperOriginPath(t *testing.T) {
+	dss := commonConfig.DeliveryServices{Version: 1, ServiceList: []commonConfig.DeliveryService{
+		{Name: "DS1", ClientURL: "http://cdn.example.com/assets", OriginURL: "https://origin.example.com/static/v2", URLRewrites: []commonConfig.URLRewriteRule{
+			{Pattern: `^/assets/(.*)\.jpg$`, Replacement: "/assets/$1.webp", UpstreamOnly: true},
+		}},
+	}}
+	cfg := config.RunConfig{
+		Valid:       true,
+		Filename:    "test",
+		Node:        &commonConfig.CacheNode{IP: "192.168.1.1", Port: 8080, Type: commonConfig.CacheNodeMid},
+		ServiceList: &dss,
+	}
+	for path, want := range map[string]string{
+		"/assets/x.js?v=1":  "https://origin.example.com/static/v2/x.js?v=1",
+		"/assets/img/a.jpg": "https://origin.example.com/static/v2/img/a.webp",
+	} {
+		req, _ := http.NewRequest("GET", "http://cdn.example.com"+path, nil)
+		mapped, resp, _, err := backend.ParentMapper(context.Background(), req, &cfg)
+		if err != nil || resp != nil {
+			t.Fatalf("ParentMapper failed: %v %v", err, resp)
+		}
+		if mapped.URL.String() != want {
+			t.Errorf("%s: got %s, want %s", path, mapped.URL, want)
+		}
+		if req.URL.Path != strings.Split(path, "?")[0] {
+			t.Errorf("the client request must not be changed, got %s", req.URL)
+		}
+	}
+
+	// the edge keeps the client URL for its parent
+	cfg.Node.Type = commonConfig.CacheNodeEdge
+	cfg.Node.ParentIP = "127.0.0.1"
+	cfg.Node.ParentPort = 9000
+	req, _ := http.NewRequest("GET", "http://cdn.example.com/assets/img/a.jpg", nil)
+	mapped, _, _, _ := backend.ParentMapper(context.Background(), req, &cfg)
+	if mapped.URL.Path != "/assets/img/a.jpg" {
+		t.Errorf("expected the client path towards the parent, got %s", mapped.URL)
+	}
+}
+
+func TestBackend_DoCORSVary(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Vary", "Origin, X-Device")
+		w.Header().Set("Access-Control-Allow-Origin", r.Header.Get("Origin"))
+		w.Write([]byte("font"))
+	}))
+	defer ts.Close()
+	tsURL, _ := url.Parse(ts.URL)
+	Port, _ := strconv.Atoi(tsURL.Port())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+	dss := commonConfig.DeliveryServices{Version: 1, ServiceList: []commonConfig.DeliveryService{
+		{Name: "DS1", ClientURL: "http://example.com", OriginURL: "http://originurl.com", CORS: commonConfig.CORS{Enabled: true, AllowOrigins: []string{"*"}}},
+	}}
+	cfg := config.RunConfig{
+		Valid:    true,
+		Filename: "test",
+		Node: &commonConfig.CacheNode{
+			IP:         "192.168.1.1",
+			Port:       8080,
+			Type:       commonConfig.CacheNodeEdge,
+			ParentIP:   tsURL.Hostname(),
+			ParentPort: Port},
+		ServiceList: &dss,
+	}
+	store := &backendTestMock.RequestHandlerMock{HttpStatuscode: http.StatusOK, Header: map[string][]string{}}
+	backhandler, err := backend.Init(ctx, wg, &cfg, store, nil)
+	if err != nil {
+		t.Fatalf("Backend Initialization failed")
+	}
+	req, _ := http.NewRequest("GET", "http://example.com/font.woff2", nil)
+	req.Header.Set("Origin", "https://www.example.org")
+	resp, err := backhandler.Do(req)
+	if err != nil {
+		t.Fatalf("Do failed: %v", err)
+	}
+	io.ReadAll(resp.Body)
+	resp.Body.Close()
+	cancel()
+	wg.Wait()
+	if got := resp.Header.Get("Vary"); got != "X-Device" {
+		t.Errorf("expected one stored copy for all the Origins, got Vary %q", got)
+	}
+}
@@ -0,0 +1,135 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/hcl/cdn/cacheNode/observability"
+	coCfg "github.com/hcl/cdn/common/config"
+)
+
+// Defaults of the circuit breaker when the DS does not set them
+const (
+	defaultFailureThreshold = 5
+	defaultCooldown         = 30 * time.Second
+)
+
+// States of a circuit
+const (
+	circuitClosed   = "closed"    // fetches sent
+	circuitOpen     = "open"      // fetches failed without being sent until the cooldown is over
+	circuitHalfOpen = "half-open" // one trial fetch sent, it closes or opens the circuit again
+)
+
+// errCircuitOpen fails the fetches to an upstream host whose circuit is open
+var errCircuitOpen = errors.New("circuit open")
+
+type circuit struct {
+	state    string
+	failures int // failed fetches in a row
+	openedAt time.Time
+	trial    bool // the trial fetch of the half-open circuit is in progress
+}
+
+// circuitBreaker keeps the circuit of each upstream host, the host the request is sent to: the origin, or
+// the host of the DS when the node fetches through its parents
+type circuitBreaker struct {
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+var breakers = &circuitBreaker{circuits: make(map[string]*circuit)}
+
+// allow returns errCircuitOpen when the fetch must not be sent to the host. The first fetch after the
+// cooldown half-opens the circuit and is sent as the trial.
+func (b *circuitBreaker) allow(host string, ds *coCfg.DeliveryService, observabilityHanlder observability.ObservabilityHandler) error {
+	b.mu.Lock()
+	c, ok := b.circuits[host]
+	switch {
+	case !ok || c.state == circuitClosed:
+		b.mu.Unlock()
+		return nil
+	case c.state == circuitHalfOpen, time.Since(c.openedAt) < seconds(ds.CircuitBreaker.Cooldown, defaultCooldown):
+		defer b.mu.Unlock()
+		if c.state == circuitHalfOpen && !c.trial {
+			// the trial fetch was not sent, another one is
+			c.trial = true
+			return nil
+		}
+		return errCircuitOpen
+	}
+	c.state = circuitHalfOpen
+	c.trial = true
+	failures := c.failures
+	b.mu.Unlock()
+	transition(ds, host, circuitOpen, circuitHalfOpen, failures, "", observabilityHanlder)
+	return nil
+}
+
+// done records the outcome of a fetch sent to the host, a failure is an error or a 5xx
+func (b *circuitBreaker) done(ctx context.Context, host string, ds *coCfg.DeliveryService, response *http.Response, err error, observabilityHanlder observability.ObservabilityHandler) {
+	b.mu.Lock()
+	c, ok := b.circuits[host]
+	if ctx.Err() != nil {
+		// node shutting down, not the host failing
+		if ok {
+			c.trial = false
+		}
+		b.mu.Unlock()
+		return
+	}
+	if !ok {
+		c = &circuit{state: circuitClosed}
+		b.circuits[host] = c
+	}
+	from := c.state
+	failure := ""
+	if err != nil {
+		failure = err.Error()
+	} else if response.StatusCode >= http.StatusInternalServerError {
+		failure = response.Status
+	}
+	if failure == "" {
+		c.failures = 0
+		c.state = circuitClosed
+	} else {
+		c.failures++
+		if c.state == circuitHalfOpen || c.failures >= count(ds.CircuitBreaker.FailureThreshold, defaultFailureThreshold) {
+			c.state = circuitOpen
+			c.openedAt = time.Now()
+		}
+	}
+	c.trial = false
+	to, failures := c.state, c.failures
+	if to == circuitClosed && failures == 0 {
+		// nothing to remember about the healthy hosts
+		delete(b.circuits, host)
+	}
+	b.mu.Unlock()
+	if from != to {
+		transition(ds, host, from, to, failures, failure, observabilityHanlder)
+	}
+}
+
+func transition(ds *coCfg.DeliveryService, host string, from string, to string, failures int, failure string, observabilityHanlder observability.ObservabilityHandler) {
+	if to == circuitOpen {
+		slog.Warn("BE circuitBreaker: Circuit opened", "ds", ds.Name, "host", host, "from", from, "failures", failures, "error", failure)
+	} else {
+		slog.Info("BE circuitBreaker: Circuit changed", "ds", ds.Name, "host", host, "from", from, "to", to)
+	}
+	if observabilityHanlder != nil {
+		observabilityHanlder.RecordEventCircuitBreaker(observability.CircuitBreakerEvent{
+			Timestamp: time.Now(),
+			DS:        ds.Name,
+			Host:      host,
+			From:      from,
+			To:        to,
+			Failures:  failures,
+			Error:     failure,
+		})
+	}
+}
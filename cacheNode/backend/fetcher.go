@@ -2,18 +2,180 @@ package backend
 
 import (
 	"context"
+	"errors"
 	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/hcl/cdn/cacheNode/cachePolicy"
+	"github.com/hcl/cdn/cacheNode/config"
+	"github.com/hcl/cdn/cacheNode/observability"
+	coCfg "github.com/hcl/cdn/common/config"
+	"github.com/hcl/cdn/common/helper"
 )
 
-func getObject(ctx context.Context, req *http.Request) (response *http.Response, err error) {
+// fetch gets the object through the parents of the node, from the origins of the DS without parents. The
+// fetch is sent again after a backoff when the retry policy of the DS allows it.
+func fetch(ctx context.Context, req *http.Request, ds *coCfg.DeliveryService, cfg *config.RunConfig, observabilityHanlder observability.ObservabilityHandler) (response *http.Response, err error) {
+	tries := attempts(req, ds)
+	for attempt := 1; ; attempt++ {
+		if list := cfg.Parents(); len(list) > 0 {
+			response, err = fetchParent(ctx, req, ds, cfg, list, observabilityHanlder)
+		} else {
+			response, err = fetchOrigin(ctx, req, ds, observabilityHanlder)
+		}
+		if attempt >= tries || !retry(ctx, &ds.Retry, response, err) {
+			return
+		}
+		wait := backoff(&ds.Retry, attempt)
+		if response != nil {
+			slog.Warn("BE Fetcher: Retrying the fetch", "ds", ds.Name, "attempt", attempt+1, "wait", wait, "status", response.StatusCode)
+			io.Copy(io.Discard, io.LimitReader(response.Body, 64*1024))
+			response.Body.Close()
+		} else {
+			slog.Warn("BE Fetcher: Retrying the fetch", "ds", ds.Name, "attempt", attempt+1, "wait", wait, "error", err)
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// fetchParent gets the object through the parent of its cache key on the ring. A parent which cannot be
+// reached is marked down and the next parent on the ring is tried, then the origin when the node falls
+// back to it.
+func fetchParent(ctx context.Context, req *http.Request, ds *coCfg.DeliveryService, cfg *config.RunConfig, list []coCfg.Parent, observabilityHanlder observability.ObservabilityHandler) (response *http.Response, err error) {
+	var policy *coCfg.CacheKey
+	if ds != nil {
+		policy = &ds.CacheKey
+	}
+	key := cachePolicy.NewKey(req.URL, req.Host, req.Header, policy).String()
+	all, up := parentOrder(list, key)
+	fallback := cfg.OriginFallback() && ds != nil
+	if len(up) == 0 && !fallback {
+		// all down, better try them than fail
+		up = all
+	}
+	for _, addr := range up {
+		response, err = getUpstream(withParent(ctx, addr), req, ds, observabilityHanlder)
+		if errors.Is(err, errCircuitOpen) {
+			// same host through all the parents
+			break
+		}
+		if err == nil || !connectError(err) || ctx.Err() != nil {
+			return
+		}
+		markParentDown(addr, err)
+	}
+	if !fallback || ctx.Err() != nil {
+		return
+	}
+	originReq := req.Clone(ctx)
+	if notFound := mapOrigin(originReq, ds); notFound != nil {
+		return
+	}
+	slog.Warn("BE Fetcher: Parents down, fetching from the origin", "url", helper.GetString(originReq))
+	return fetchOrigin(ctx, originReq, ds, observabilityHanlder)
+}
+
+// fetchOrigin gets the object from the origin the request is mapped to, then from the next origins of the
+// group of the DS when the origin cannot be reached or answers a failover status
+func fetchOrigin(ctx context.Context, req *http.Request, ds *coCfg.DeliveryService, observabilityHanlder observability.ObservabilityHandler) (response *http.Response, err error) {
+	response, err = getUpstream(ctx, req, ds, observabilityHanlder)
+	if ds == nil || len(ds.OriginGroup.Origins) == 0 {
+		return
+	}
+	tried := map[string]bool{req.URL.Host: true}
+	for _, origin := range origins.order(ds) {
+		if !failover(ctx, req, ds, response, err) {
+			return
+		}
+		originURL, err1 := url.Parse(origin.URL)
+		if err1 != nil || tried[originURL.Host] {
+			continue
+		}
+		tried[originURL.Host] = true
+		if response != nil {
+			slog.Warn("BE Fetcher: Failing over to the next origin", "ds", ds.Name, "origin", origin.URL, "status", response.StatusCode)
+			response.Body.Close()
+		} else {
+			slog.Warn("BE Fetcher: Failing over to the next origin", "ds", ds.Name, "origin", origin.URL, "error", err)
+		}
+		next := req.Clone(ctx)
+		useOrigin(next.URL, origin.URL)
+		response, err = getUpstream(ctx, next, ds, observabilityHanlder)
+	}
+	return
+}
+
+// getUpstream sends the request to its host unless the circuit of the host is open
+func getUpstream(ctx context.Context, req *http.Request, ds *coCfg.DeliveryService, observabilityHanlder observability.ObservabilityHandler) (response *http.Response, err error) {
+	breaker := ds != nil && ds.CircuitBreaker.Enabled
+	host := req.URL.Host
+	if breaker {
+		if err = breakers.allow(host, ds, observabilityHanlder); err != nil {
+			slog.Warn("BE circuitBreaker: Circuit open, fetch not sent", "ds", ds.Name, "host", host)
+			return
+		}
+	}
+	start := time.Now()
+	response, err = getObject(ctx, req, ds)
+	recordFetch(ctx, req, ds, start, response, err, observabilityHanlder)
+	if breaker {
+		breakers.done(ctx, host, ds, response, err, observabilityHanlder)
+	}
+	return
+}
+
+// recordFetch records the backend event of the fetch, a failed fetch with the kind of its error e.g. tls_verify
+func recordFetch(ctx context.Context, req *http.Request, ds *coCfg.DeliveryService, start time.Time, response *http.Response, err error, observabilityHanlder observability.ObservabilityHandler) {
+	if observabilityHanlder == nil || ctx.Err() != nil {
+		return
+	}
+	event := observability.BackendEvent{
+		Timestamp:      time.Now(),
+		OriginServerIP: req.URL.Host,
+		URL:            helper.GetString(req),
+		ResponseTime:   int(time.Since(start).Milliseconds()),
+	}
+	if addr, ok := parentOf(ctx); ok {
+		event.OriginServerIP = addr
+	}
+	if ds != nil {
+		event.DS = ds.Name
+	}
+	if err != nil {
+		event.Error = errorKind(err)
+		if event.Error == "" {
+			event.Error = "other"
+		}
+		slog.Warn("BE Fetcher: Upstream fetch failed", "ds", event.DS, "upstream", event.OriginServerIP, "kind", event.Error, "error", err)
+	} else {
+		event.TTFB = event.ResponseTime
+		event.StatusCode = response.StatusCode
+		if response.ContentLength > 0 {
+			event.Bytes = int(response.ContentLength)
+		}
+	}
+	observabilityHanlder.RecordEventBackend(event)
+}
+
+// getObject sends the request with the transport & the timeouts of the DS
+func getObject(ctx context.Context, req *http.Request, ds *coCfg.DeliveryService) (response *http.Response, err error) {
 	slog.Info("BE Fetcher:", "req", req)
 
-	client := http.Client{Transport: &transport}
+	ctx, timeouts := withTimeouts(withDeliveryService(ctx, ds), ds)
+	client := http.Client{Transport: roundTripper}
 	newReq := req.WithContext(ctx)
 	response, err = client.Do(newReq)
 	if err != nil {
+		timeouts(nil)
 		var body string
 		if response != nil && response.Body != nil {
 			bodybytes, _ := io.ReadAll(response.Body)
@@ -23,6 +185,7 @@ func getObject(ctx context.Context, req *http.Request) (response *http.Response,
 		slog.Error("BE Fetcher: Error during HTTP request", "error", err, "body", body)
 		return
 	}
+	timeouts(response)
 	slog.Info("BE Fetcher: Received HTTP response with status", "status", response.Status)
 	return
 }
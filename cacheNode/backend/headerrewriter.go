@@ -5,6 +5,7 @@ import (
 	"log/slog"
 	"net/http"
 
+	"github.com/hcl/cdn/cacheNode/headerRewrite"
 	coCfg "github.com/hcl/cdn/common/config"
 	"github.com/hcl/cdn/common/helper"
 )
@@ -19,23 +20,11 @@ func printHeaders(headers http.Header) string {
 	return headersString
 }
 
-func headerRewriter(req *http.Request, resp *http.Response, ds *coCfg.DeliveryService) (updatedResponse *http.Response, err error) {
+// headerRewriter applies the response phase rules of the DS to the response from upstream, before it is stored
+func headerRewriter(req *http.Request, resp *http.Response, ds *coCfg.DeliveryService, nodeName string) (updatedResponse *http.Response, err error) {
 	slog.Info(" BE HeaderRewriter got the request:", "url", helper.GetString(req))
 	updatedResponse = resp
-	for _, rule := range ds.RewriteRules {
-		switch rule.Operation {
-		case coCfg.HdrReWriteOpAdd:
-			updatedResponse.Header.Set(rule.HeaderName, rule.Value)
-		case coCfg.HdrReWriteOpOverwrite:
-			if _, exists := resp.Header[rule.HeaderName]; exists {
-				updatedResponse.Header.Set(rule.HeaderName, rule.Value)
-			}
-		case coCfg.HdrReWriteOpDelete:
-			updatedResponse.Header.Del(rule.HeaderName)
-		default:
-			slog.Error("BE HeaderRewriter invalid Action")
-		}
-	}
+	headerRewrite.Apply(ds.RewriteRules, coCfg.HdrReWritePhaseResponse, updatedResponse.Header, req, headerRewrite.NewVars(req, ds.Name, nodeName))
 
 	headersString := printHeaders(resp.Header)
 	slog.Info("BE  updated HeaderRewriter ", "header", headersString)
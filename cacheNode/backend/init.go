@@ -2,33 +2,34 @@ package backend
 
 import (
 	"context"
-	"fmt"
 	"log/slog"
 	"net/http"
-	"net/url"
 	"sync"
 	"time"
 
 	"github.com/hcl/cdn/cacheNode/common"
 	"github.com/hcl/cdn/cacheNode/config"
 	"github.com/hcl/cdn/cacheNode/observability"
+	"golang.org/x/net/http2"
 )
 
 var transport = http.Transport{
+	Proxy:                 parentProxy,
 	IdleConnTimeout:       10 * time.Second,
 	ResponseHeaderTimeout: 10 * time.Second,
+	ForceAttemptHTTP2:     true, //HTTP/2 with HTTPS origins
 }
 
-func Init(ctx context.Context, wg *sync.WaitGroup, cfg *config.RunConfig, storage common.RequestHandler, observabilityHanlder observability.ObservabilityHandler) (common.CachedRequestHandler, error) {
-	if cfg.ParentIp() != "" {
-		parentUrl := fmt.Sprintf("http://%s:%d", cfg.ParentIp(), cfg.ParentPort())
-		slog.Info("BE ParentMapper: Assigned Parent url", "url", parentUrl)
-
-		url, err := url.Parse(parentUrl)
-		if err != nil {
+// roundTripper sends the upstream requests, the ones going through a parent to the parent of the request
+var roundTripper http.RoundTripper = &transport
 
-		}
-		transport.Proxy = http.ProxyURL(url)
+func Init(ctx context.Context, wg *sync.WaitGroup, cfg *config.RunConfig, storage common.RequestHandler, observabilityHanlder observability.ObservabilityHandler) (common.CachedRequestHandler, error) {
+	for _, parent := range cfg.Parents() {
+		slog.Info("BE ParentMapper: Assigned Parent", "ip", parent.IP, "port", parent.Port)
 	}
+	roundTripper = &parentTransport{cfg: cfg, h2c: make(map[string]*http2.Transport)}
+	// the probes run while the node has no parents, which may change with the config
+	wg.Add(1)
+	go probeOrigins(ctx, wg, cfg, observabilityHanlder)
 	return &Backend{ctx: ctx, wg: wg, cfg: cfg, store: storage, observabilityHanlder: observabilityHanlder}, nil
 }
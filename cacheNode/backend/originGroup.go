@@ -0,0 +1,343 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hcl/cdn/cacheNode/config"
+	"github.com/hcl/cdn/cacheNode/observability"
+	coCfg "github.com/hcl/cdn/common/config"
+)
+
+// Defaults of the origin group when the DS does not set them
+const (
+	defaultHealthInterval = 10 * time.Second
+	defaultHealthTimeout  = 5 * time.Second
+	defaultUnhealthyAfter = 3
+	defaultHealthyAfter   = 2
+)
+
+// how often the prober looks for the origins due for a probe
+const probeTick = time.Second
+
+// OriginStatus is the health of one origin of a DS
+type OriginStatus struct {
+	DS        string
+	URL       string
+	Backup    bool
+	Healthy   bool      // true until the probes mark it down
+	LastCheck time.Time // zero if not probed
+	LastError string    // why the last probe failed, "" if it passed
+}
+
+type originKey struct {
+	ds  string
+	url string
+}
+
+type originState struct {
+	OriginStatus
+	passes    int // probes passed in a row
+	fails     int // probes failed in a row
+	nextProbe time.Time
+}
+
+// originHealth keeps the health of the origins of all the DS, the prober updates it and the fetches skip
+// the origins marked down. Origins not probed are healthy.
+type originHealth struct {
+	mu     sync.RWMutex
+	states map[originKey]*originState
+}
+
+var origins = &originHealth{states: make(map[originKey]*originState)}
+
+// OriginHealth returns the health of the origins of the DS, of all the DS if ds is ""
+func OriginHealth(ds string) []OriginStatus {
+	origins.mu.RLock()
+	defer origins.mu.RUnlock()
+	var ret []OriginStatus
+	for key, state := range origins.states {
+		if ds == "" || key.ds == ds {
+			ret = append(ret, state.OriginStatus)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].DS != ret[j].DS {
+			return ret[i].DS < ret[j].DS
+		}
+		return ret[i].URL < ret[j].URL
+	})
+	return ret
+}
+
+// healthy reports if the origin may be fetched from, h.mu is held
+func (h *originHealth) healthy(ds string, origin string) bool {
+	state, ok := h.states[originKey{ds, origin}]
+	return !ok || state.Healthy
+}
+
+// order returns the origins of the DS in the order to fetch from: the healthy primaries, then the healthy
+// backups, each tier shuffled by weight. All the origins are returned when none is healthy.
+func (h *originHealth) order(ds *coCfg.DeliveryService) []coCfg.Origin {
+	var primaries, backups []coCfg.Origin
+	h.mu.RLock()
+	for _, origin := range ds.OriginGroup.Origins {
+		if !h.healthy(ds.Name, origin.URL) {
+			continue
+		}
+		if origin.Backup {
+			backups = append(backups, origin)
+		} else {
+			primaries = append(primaries, origin)
+		}
+	}
+	h.mu.RUnlock()
+	if len(primaries) == 0 && len(backups) == 0 {
+		slog.Warn("BE originGroup: No healthy origin, trying all", "ds", ds.Name)
+		for _, origin := range ds.OriginGroup.Origins {
+			if origin.Backup {
+				backups = append(backups, origin)
+			} else {
+				primaries = append(primaries, origin)
+			}
+		}
+	}
+	return append(weightedShuffle(primaries), weightedShuffle(backups)...)
+}
+
+func weight(origin coCfg.Origin) int {
+	if origin.Weight <= 0 {
+		return 1
+	}
+	return origin.Weight
+}
+
+// weightedShuffle orders the origins at random, an origin comes first in proportion to its weight
+func weightedShuffle(list []coCfg.Origin) []coCfg.Origin {
+	rest := append([]coCfg.Origin(nil), list...)
+	ret := make([]coCfg.Origin, 0, len(list))
+	for len(rest) > 0 {
+		total := 0
+		for _, origin := range rest {
+			total += weight(origin)
+		}
+		n := rand.Intn(total)
+		i := 0
+		for n >= weight(rest[i]) {
+			n -= weight(rest[i])
+			i++
+		}
+		ret = append(ret, rest[i])
+		rest = append(rest[:i], rest[i+1:]...)
+	}
+	return ret
+}
+
+// useOrigin points the URL to the scheme & host of the origin, the path is kept
+func useOrigin(u *url.URL, origin string) error {
+	originURL, err := url.Parse(origin)
+	if err != nil {
+		return err
+	}
+	u.Scheme = originURL.Scheme
+	u.Host = originURL.Host
+	return nil
+}
+
+// connectError reports if the request failed before reaching the origin or the parent, e.g. refused or unresolved
+func connectError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && (opErr.Op == "dial" || opErr.Op == "proxyconnect")
+}
+
+// failover reports if the fetch is retried on the next origin of the group: the origin could not be
+// reached or its circuit is open, or answered a failover status to a request safe to send again
+func failover(ctx context.Context, req *http.Request, ds *coCfg.DeliveryService, response *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		return connectError(err) || errors.Is(err, errCircuitOpen)
+	}
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return false
+	}
+	for _, status := range ds.OriginGroup.FailoverStatuses {
+		if response.StatusCode == status {
+			return true
+		}
+	}
+	return false
+}
+
+// probeOrigins runs the health probes of the origin groups until ctx is done. The probes belong to the
+// nodes fetching from the origins, the edges behind a parent leave them to the parent.
+func probeOrigins(ctx context.Context, wg *sync.WaitGroup, cfg *config.RunConfig, observabilityHanlder observability.ObservabilityHandler) {
+	defer wg.Done()
+	slog.Info("BE originGroup: Starting origin health probes")
+	ticker := time.NewTicker(probeTick)
+	defer ticker.Stop()
+	for {
+		if len(cfg.Parents()) == 0 {
+			origins.probeDue(ctx, wg, cfg, observabilityHanlder)
+		}
+		select {
+		case <-ctx.Done():
+			slog.Info("BE originGroup: Origin health probes exiting")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// probeDue starts the probes of the origins due for one, the origins no longer configured are forgotten
+func (h *originHealth) probeDue(ctx context.Context, wg *sync.WaitGroup, cfg *config.RunConfig, observabilityHanlder observability.ObservabilityHandler) {
+	now := time.Now()
+	live := make(map[originKey]bool)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, ds := range cfg.DSList() {
+		group := ds.OriginGroup
+		for _, origin := range group.Origins {
+			key := originKey{ds.Name, origin.URL}
+			live[key] = true
+			state, ok := h.states[key]
+			if !ok {
+				state = &originState{OriginStatus: OriginStatus{DS: ds.Name, URL: origin.URL, Healthy: true}}
+				h.states[key] = state
+			}
+			state.Backup = origin.Backup
+			if group.HealthPath == "" {
+				// not probed anymore, fetched from again
+				state.Healthy = true
+				state.LastError = ""
+				continue
+			}
+			if now.Before(state.nextProbe) {
+				continue
+			}
+			state.nextProbe = now.Add(seconds(group.HealthInterval, defaultHealthInterval))
+			wg.Add(1)
+			go func(ds coCfg.DeliveryService, origin coCfg.Origin) {
+				defer wg.Done()
+				h.probe(ctx, &ds, origin, observabilityHanlder)
+			}(ds, origin)
+		}
+	}
+	for key := range h.states {
+		if !live[key] {
+			delete(h.states, key)
+		}
+	}
+}
+
+// probeOrigin sends the probe with the transport of the DS, e.g. its CA bundle & client certificate
+func probeOrigin(ctx context.Context, ds *coCfg.DeliveryService, origin string, healthPath string) (*http.Response, error) {
+	originURL, err := url.Parse(origin)
+	if err != nil {
+		return nil, err
+	}
+	ref, err := url.Parse(healthPath)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(withDeliveryService(ctx, ds), http.MethodGet, originURL.ResolveReference(ref).String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	client := http.Client{
+		Transport: roundTripper,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return client.Do(req)
+}
+
+func seconds(n int, def time.Duration) time.Duration {
+	if n > 0 {
+		return time.Duration(n) * time.Second
+	}
+	return def
+}
+
+func count(n int, def int) int {
+	if n > 0 {
+		return n
+	}
+	return def
+}
+
+// probe sends a GET for the health path to the origin, a 2xx or 3xx passes
+func (h *originHealth) probe(ctx context.Context, service *coCfg.DeliveryService, origin coCfg.Origin, observabilityHanlder observability.ObservabilityHandler) {
+	ds, group := service.Name, &service.OriginGroup
+	probeCtx, cancel := context.WithTimeout(ctx, seconds(group.HealthTimeout, defaultHealthTimeout))
+	defer cancel()
+	start := time.Now()
+	probeErr := ""
+	resp, err := probeOrigin(probeCtx, service, origin.URL, group.HealthPath)
+	if err != nil {
+		probeErr = err.Error()
+		if kind := errorKind(err); kind == "tls_verify" || kind == "tls_handshake" {
+			probeErr = kind + ": " + probeErr
+		}
+	} else {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+		resp.Body.Close()
+		if resp.StatusCode >= http.StatusBadRequest {
+			probeErr = resp.Status
+		}
+	}
+	if ctx.Err() != nil {
+		// node shutting down
+		return
+	}
+	elapsed := time.Since(start)
+
+	h.mu.Lock()
+	state, ok := h.states[originKey{ds, origin.URL}]
+	if !ok {
+		h.mu.Unlock()
+		return
+	}
+	state.LastCheck = time.Now()
+	state.LastError = probeErr
+	if probeErr == "" {
+		state.passes++
+		state.fails = 0
+		if !state.Healthy && state.passes >= count(group.HealthyAfter, defaultHealthyAfter) {
+			state.Healthy = true
+			slog.Info("BE originGroup: Origin healthy again", "ds", ds, "origin", origin.URL)
+		}
+	} else {
+		state.fails++
+		state.passes = 0
+		if state.Healthy && state.fails >= count(group.UnhealthyAfter, defaultUnhealthyAfter) {
+			state.Healthy = false
+			slog.Warn("BE originGroup: Origin marked down", "ds", ds, "origin", origin.URL, "error", probeErr)
+		}
+	}
+	healthy := state.Healthy
+	h.mu.Unlock()
+
+	if observabilityHanlder != nil {
+		observabilityHanlder.RecordEventOriginHealth(observability.OriginHealthEvent{
+			Timestamp:    time.Now(),
+			DS:           ds,
+			Origin:       origin.URL,
+			Backup:       origin.Backup,
+			Healthy:      healthy,
+			ResponseTime: int(elapsed.Milliseconds()),
+			Error:        probeErr,
+		})
+	}
+}
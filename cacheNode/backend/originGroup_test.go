@@ -0,0 +1,152 @@
+package backend_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hcl/cdn/cacheNode/backend"
+	"github.com/hcl/cdn/cacheNode/backend/backendTestMock"
+	"github.com/hcl/cdn/cacheNode/config"
+	commonConfig "github.com/hcl/cdn/common/config"
+)
+
+func midConfig(ds commonConfig.DeliveryService) *config.RunConfig {
+	return &config.RunConfig{
+		Valid:       true,
+		Filename:    "test",
+		Node:        &commonConfig.CacheNode{IP: "192.168.1.1", Port: 8080, Type: commonConfig.CacheNodeMid},
+		ServiceList: &commonConfig.DeliveryServices{Version: 1, ServiceList: []commonConfig.DeliveryService{ds}},
+	}
+}
+
+func TestBackend_DoOriginFailover(t *testing.T) {
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+	var busyHits atomic.Int32
+	busy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		busyHits.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer busy.Close()
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("from backup " + r.URL.Path))
+	}))
+	defer up.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+	cfg := midConfig(commonConfig.DeliveryService{
+		Name: "DS1", ClientURL: "http://example.com", OriginURL: "http://originurl.com/v1",
+		OriginGroup: commonConfig.OriginGroup{
+			Origins: []commonConfig.Origin{
+				{URL: down.URL},
+				{URL: busy.URL, Weight: 2},
+				{URL: up.URL, Backup: true},
+			},
+			FailoverStatuses: []int{http.StatusServiceUnavailable},
+		},
+	})
+	store := &backendTestMock.RequestHandlerMock{HttpStatuscode: http.StatusOK, Header: map[string][]string{}}
+	backhandler, err := backend.Init(ctx, wg, cfg, store, nil)
+	if err != nil {
+		t.Fatalf("Backend Initialization failed")
+	}
+
+	req, _ := http.NewRequest("GET", "http://example.com/a.txt", nil)
+	resp, err := backhandler.Do(req)
+	if err != nil {
+		t.Fatalf("Do failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	cancel()
+	wg.Wait()
+	if resp.StatusCode != http.StatusOK || string(body) != "from backup /v1/a.txt" {
+		t.Errorf("expected the backup after the primaries failed, got %d %q", resp.StatusCode, body)
+	}
+	if busyHits.Load() != 1 {
+		t.Errorf("expected one fetch from the busy primary, got %d", busyHits.Load())
+	}
+}
+
+func TestOriginHealthProbes(t *testing.T) {
+	var primaryDown atomic.Bool
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/health" && primaryDown.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer primary.Close()
+	backup := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer backup.Close()
+	primaryURL, _ := url.Parse(primary.URL)
+	backupURL, _ := url.Parse(backup.URL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+	defer cancel()
+	cfg := midConfig(commonConfig.DeliveryService{
+		Name: "DS2", ClientURL: "http://example.com", OriginURL: "http://originurl.com",
+		OriginGroup: commonConfig.OriginGroup{
+			Origins:        []commonConfig.Origin{{URL: primary.URL}, {URL: backup.URL, Backup: true}},
+			HealthPath:     "/health",
+			HealthInterval: 1,
+			UnhealthyAfter: 1,
+			HealthyAfter:   1,
+		},
+	})
+	if _, err := backend.Init(ctx, wg, cfg, &backendTestMock.RequestHandlerMock{}, nil); err != nil {
+		t.Fatalf("Backend Initialization failed")
+	}
+	mappedHost := func() string {
+		req, _ := http.NewRequest("GET", "http://example.com/a.txt", nil)
+		mapped, _, _, _ := backend.ParentMapper(ctx, req, cfg)
+		return mapped.URL.Host
+	}
+	waitHealth := func(healthy bool) backend.OriginStatus {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			for _, status := range backend.OriginHealth("DS2") {
+				if status.URL == primary.URL && status.Healthy == healthy && !status.LastCheck.IsZero() {
+					return status
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatalf("primary origin not reported healthy=%t: %+v", healthy, backend.OriginHealth("DS2"))
+		return backend.OriginStatus{}
+	}
+
+	waitHealth(true)
+	if host := mappedHost(); host != primaryURL.Host {
+		t.Errorf("expected the healthy primary, mapped to %s", host)
+	}
+	primaryDown.Store(true)
+	if status := waitHealth(false); status.LastError == "" {
+		t.Error("expected the failed probe in the status")
+	}
+	if host := mappedHost(); host != backupURL.Host {
+		t.Errorf("expected the backup while the primary is down, mapped to %s", host)
+	}
+	primaryDown.Store(false)
+	waitHealth(true)
+	if host := mappedHost(); host != primaryURL.Host {
+		t.Errorf("expected the primary once healthy again, mapped to %s", host)
+	}
+	if got := len(backend.OriginHealth("")); got != 2 {
+		t.Errorf("expected the health of both origins, got %d", got)
+	}
+}
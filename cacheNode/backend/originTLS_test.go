@@ -0,0 +1,131 @@
+package backend_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hcl/cdn/cacheNode/backend"
+	"github.com/hcl/cdn/cacheNode/backend/backendTestMock"
+	"github.com/hcl/cdn/cacheNode/config"
+	commonConfig "github.com/hcl/cdn/common/config"
+)
+
+// clientCertificate returns a self signed client certificate & its key in PEM
+func clientCertificate(t *testing.T) (certPEM string, keyPEM string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "edge"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+}
+
+func TestBackend_DoOriginTLS(t *testing.T) {
+	certPEM, keyPEM := clientCertificate(t)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AppendCertsFromPEM([]byte(certPEM))
+	origin := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+		w.Write([]byte("secure " + r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	origin.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	origin.StartTLS()
+	defer origin.Close()
+	caPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: origin.Certificate().Raw}))
+
+	// one DS per trust setting towards the same origin
+	services := []commonConfig.DeliveryService{
+		{Name: "noCA", ClientURL: "http://noca.example.com", OriginURL: origin.URL},
+		{Name: "wrongName", ClientURL: "http://wrongname.example.com", OriginURL: origin.URL,
+			OriginTLS: commonConfig.OriginTLS{CABundle: caPEM, ServerName: "wrong.internal"}},
+		{Name: "noCert", ClientURL: "http://nocert.example.com", OriginURL: origin.URL,
+			OriginTLS: commonConfig.OriginTLS{CABundle: caPEM}},
+		{Name: "mTLS", ClientURL: "http://mtls.example.com", OriginURL: origin.URL,
+			OriginTLS: commonConfig.OriginTLS{CABundle: caPEM, ServerName: "example.com", MinVersion: "1.2", ClientCertificate: certPEM, ClientKey: keyPEM}},
+	}
+	cfg := &config.RunConfig{
+		Valid:       true,
+		Filename:    "test",
+		Node:        &commonConfig.CacheNode{IP: "192.168.1.1", Port: 8080, Type: commonConfig.CacheNodeMid},
+		ServiceList: &commonConfig.DeliveryServices{Version: 1, ServiceList: services},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+	defer cancel()
+	events := &recordedEvents{}
+	store := &backendTestMock.RequestHandlerMock{HttpStatuscode: http.StatusOK, Header: map[string][]string{}}
+	backhandler, err := backend.Init(ctx, wg, cfg, store, events)
+	if err != nil {
+		t.Fatalf("Backend Initialization failed")
+	}
+
+	for _, tc := range []struct {
+		host string
+		kind string // kind of the failure, "" if served
+	}{
+		{"noca.example.com", "tls_verify"},
+		{"wrongname.example.com", "tls_verify"},
+		{"nocert.example.com", "tls_handshake"},
+		{"mtls.example.com", ""},
+	} {
+		req, _ := http.NewRequest("GET", "http://"+tc.host+"/a.txt", nil)
+		resp, err := backhandler.Do(req)
+		if tc.kind == "" {
+			if err != nil {
+				t.Errorf("%s: Do failed: %v", tc.host, err)
+				continue
+			}
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if string(body) != "secure edge" {
+				t.Errorf("%s: expected the origin to see the client certificate, got %q", tc.host, body)
+			}
+		} else if err == nil {
+			resp.Body.Close()
+			t.Errorf("%s: expected the handshake to fail", tc.host)
+		}
+	}
+
+	events.mu.Lock()
+	defer events.mu.Unlock()
+	got := make(map[string]string)
+	for _, e := range events.backends {
+		got[e.DS] = e.Error
+	}
+	want := map[string]string{"noCA": "tls_verify", "wrongName": "tls_verify", "noCert": "tls_handshake", "mTLS": ""}
+	for ds, kind := range want {
+		if got[ds] != kind {
+			t.Errorf("%s: expected the backend error %q, got %q", ds, kind, got[ds])
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package backend
+
+import (
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/net/http2"
+)
+
+// parentConnPool multiplexes the requests to the parent on one h2c connection, whatever their host.
+// Requests beyond the stream limit of the parent wait for a free stream instead of opening connections.
+// One request dials a new connection at a time, the others wait for it.
+type parentConnPool struct {
+	t       *http2.Transport
+	addr    string
+	mu      sync.Mutex
+	cc      *http2.ClientConn
+	dialing chan struct{} // closed once the dial in progress ends, nil when none
+}
+
+func (p *parentConnPool) GetClientConn(req *http.Request, addr string) (*http2.ClientConn, error) {
+	for {
+		p.mu.Lock()
+		if p.cc != nil && p.cc.CanTakeNewRequest() {
+			cc := p.cc
+			p.mu.Unlock()
+			return cc, nil
+		}
+		dialing := p.dialing
+		if dialing == nil {
+			dialing = make(chan struct{})
+			p.dialing = dialing
+			p.mu.Unlock()
+			cc, err := p.dial(req)
+			p.mu.Lock()
+			if err == nil {
+				p.cc = cc
+			}
+			p.dialing = nil
+			close(dialing)
+			p.mu.Unlock()
+			return cc, err
+		}
+		p.mu.Unlock()
+		select {
+		case <-dialing:
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+// dial opens a new h2c connection to the parent within the connect timeout of the DS of the request
+func (p *parentConnPool) dial(req *http.Request) (*http2.ClientConn, error) {
+	dialer := &net.Dialer{Timeout: connectTimeout(deliveryServiceOf(req.Context())), KeepAlive: 30 * time.Second}
+	conn, err := dialer.DialContext(req.Context(), "tcp", p.addr)
+	if err != nil {
+		slog.Error("BE ParentConn: Failed to connect to parent", "addr", p.addr, "error", err)
+		return nil, err
+	}
+	cc, err := p.t.NewClientConn(conn)
+	if err != nil {
+		conn.Close()
+		slog.Error("BE ParentConn: Failed to start h2c connection", "addr", p.addr, "error", err)
+		return nil, err
+	}
+	slog.Info("BE ParentConn: New h2c connection to parent", "addr", p.addr)
+	return cc, nil
+}
+
+func (p *parentConnPool) MarkDead(cc *http2.ClientConn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cc == cc {
+		p.cc = nil
+	}
+}
+
+// newParentH2CTransport returns the transport speaking cleartext HTTP/2 to the parent at addr
+func newParentH2CTransport(addr string) *http2.Transport {
+	t := &http2.Transport{
+		AllowHTTP:                  true,
+		StrictMaxConcurrentStreams: true,
+		IdleConnTimeout:            transport.IdleConnTimeout,
+		ReadIdleTimeout:            30 * time.Second,
+	}
+	t.ConnPool = &parentConnPool{t: t, addr: addr}
+	return t
+}
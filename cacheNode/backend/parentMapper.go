@@ -11,6 +11,8 @@ import (
 	"strconv"
 
 	"github.com/hcl/cdn/cacheNode/config"
+	"github.com/hcl/cdn/cacheNode/headerRewrite"
+	"github.com/hcl/cdn/cacheNode/urlRewrite"
 	coCfg "github.com/hcl/cdn/common/config"
 	"github.com/hcl/cdn/common/helper"
 )
@@ -19,6 +21,11 @@ func ParentMapper(ctx context.Context, req *http.Request, cfg *config.RunConfig)
 	mappedReq = req.Clone(ctx)
 	mappedReq.RequestURI = ""
 	mappedReq.Host = ""
+	// Always fill the cache with the full object, client ranges and
+	// preconditions are served by the frontend
+	for _, hdr := range []string{"Range", "If-Range", "If-Match", "If-None-Match", "If-Modified-Since", "If-Unmodified-Since"} {
+		mappedReq.Header.Del(hdr)
+	}
 
 	//takes the http request as input
 	slog.Info("BE ParentMapper: Received a request", "url", helper.GetString(req))
@@ -30,18 +37,34 @@ func ParentMapper(ctx context.Context, req *http.Request, cfg *config.RunConfig)
 		response.Status = strconv.Itoa(http.StatusNotFound) + " Content Not Found"
 		return
 	}
-	if cfg.ParentIp() == "" {
-		orign_url, err1 := url.Parse(ds.OriginURL)
-		if err1 != nil {
-			response = &http.Response{}
-			response.StatusCode = http.StatusNotFound
-			response.Status = strconv.Itoa(http.StatusNotFound) + " Content Not Found"
-			return
-		}
-		mappedReq.URL.Scheme = orign_url.Scheme
-		mappedReq.URL.Host = orign_url.Host
-		slog.Info("BE ParentMapper: Assigned Origin url", "url", helper.GetString(mappedReq))
+	// request phase rules on the request going upstream
+	headerRewrite.Apply(ds.RewriteRules, coCfg.HdrReWritePhaseRequest, mappedReq.Header, req, headerRewrite.NewVars(req, ds.Name, cfg.NodeName()))
+	if len(cfg.Parents()) == 0 {
+		response = mapOrigin(mappedReq, ds)
+		return
+	}
+	return
+}
+
+// mapOrigin points the request to the origin of the DS, it returns the response to send when the DS has
+// no valid origin
+func mapOrigin(mappedReq *http.Request, ds *coCfg.DeliveryService) (response *http.Response) {
+	orign_url, err1 := url.Parse(ds.OriginURL)
+	if err1 == nil && len(ds.OriginGroup.Origins) > 0 {
+		// first origin of the group in its order, the fetch fails over to the next ones
+		orign_url, err1 = url.Parse(origins.order(ds)[0].URL)
+	}
+	if err1 != nil {
+		response = &http.Response{}
+		response.StatusCode = http.StatusNotFound
+		response.Status = strconv.Itoa(http.StatusNotFound) + " Content Not Found"
 		return
 	}
+	mappedReq.URL.Scheme = orign_url.Scheme
+	mappedReq.URL.Host = orign_url.Host
+	// upstream only rewrites, then the path of the ClientURL maps to the path of the OriginURL
+	urlRewrite.Rewrite(ds.URLRewrites, true, mappedReq.URL)
+	urlRewrite.MapOriginPath(ds, mappedReq.URL)
+	slog.Info("BE ParentMapper: Assigned Origin url", "url", helper.GetString(mappedReq))
 	return
 }
@@ -0,0 +1,181 @@
+package backend
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/binary"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hcl/cdn/cacheNode/config"
+	coCfg "github.com/hcl/cdn/common/config"
+	"golang.org/x/net/http2"
+)
+
+// points of each parent on the ring, they spread the objects evenly over the parents
+const parentRingPoints = 100
+
+// how long a parent which could not be reached is skipped
+var parentDownTime = 10 * time.Second
+
+type parentKey struct{}
+
+// withParent returns the context of a request going through the parent at addr
+func withParent(ctx context.Context, addr string) context.Context {
+	return context.WithValue(ctx, parentKey{}, addr)
+}
+
+// parentOf returns the parent the request goes through, false when it goes to the origin
+func parentOf(ctx context.Context) (string, bool) {
+	addr, ok := ctx.Value(parentKey{}).(string)
+	return addr, ok
+}
+
+// parentProxy is the proxy of the requests going through a parent
+func parentProxy(req *http.Request) (*url.URL, error) {
+	if addr, ok := parentOf(req.Context()); ok {
+		return &url.URL{Scheme: "http", Host: addr}, nil
+	}
+	return nil, nil
+}
+
+// parentTransport sends the requests going through a parent over h2c when the node talks h2c to its
+// parents, one transport per parent, and the other requests with the transport of their DS
+type parentTransport struct {
+	cfg *config.RunConfig
+	mu  sync.Mutex
+	h2c map[string]*http2.Transport
+}
+
+func (p *parentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	addr, ok := parentOf(req.Context())
+	if !ok || !p.cfg.ParentH2C() {
+		t, err := upstreams.transport(p.cfg, deliveryServiceOf(req.Context()))
+		if err != nil {
+			return nil, err
+		}
+		return t.RoundTrip(req)
+	}
+	p.mu.Lock()
+	t, ok := p.h2c[addr]
+	if !ok {
+		slog.Info("BE ParentMapper: Using h2c towards parent", "addr", addr)
+		t = newParentH2CTransport(addr)
+		p.h2c[addr] = t
+	}
+	p.mu.Unlock()
+	return roundTripWithinTTFB(t, req)
+}
+
+// roundTripWithinTTFB sends the request, it fails when the response headers do not come within the
+// TTFBTimeout of the DS. The request is cancelled once the body of its response is closed.
+func roundTripWithinTTFB(t http.RoundTripper, req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithCancel(req.Context())
+	timer := time.AfterFunc(ttfbTimeout(deliveryServiceOf(ctx)), cancel)
+	resp, err := t.RoundTrip(req.WithContext(ctx))
+	if !timer.Stop() {
+		if err == nil {
+			resp.Body.Close()
+		}
+		cancel()
+		return nil, errTTFB
+	}
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &upstreamBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+type ringPoint struct {
+	hash   uint64
+	parent int
+}
+
+// parentRing places the parents on a consistent hash ring, adding or removing a parent moves only the
+// objects of its share of the ring
+type parentRing struct {
+	parents []coCfg.Parent
+	addrs   []string
+	points  []ringPoint
+}
+
+func hash64(s string) uint64 {
+	sum := sha1.Sum([]byte(s))
+	return binary.BigEndian.Uint64(sum[:8])
+}
+
+func newParentRing(parents []coCfg.Parent) *parentRing {
+	r := &parentRing{parents: parents}
+	for i, parent := range parents {
+		addr := net.JoinHostPort(parent.IP, strconv.Itoa(parent.Port))
+		r.addrs = append(r.addrs, addr)
+		for j := 0; j < parentRingPoints; j++ {
+			r.points = append(r.points, ringPoint{hash64(addr + "#" + strconv.Itoa(j)), i})
+		}
+	}
+	sort.Slice(r.points, func(i, j int) bool { return r.points[i].hash < r.points[j].hash })
+	return r
+}
+
+// lookup returns the addresses of all the parents in the order of the ring from the key, the first one
+// stores the object
+func (r *parentRing) lookup(key string) []string {
+	if len(r.points) == 0 {
+		return nil
+	}
+	h := hash64(key)
+	start := sort.Search(len(r.points), func(i int) bool { return r.points[i].hash >= h })
+	ret := make([]string, 0, len(r.addrs))
+	seen := make([]bool, len(r.addrs))
+	for i := 0; i < len(r.points) && len(ret) < len(r.addrs); i++ {
+		point := r.points[(start+i)%len(r.points)]
+		if !seen[point.parent] {
+			seen[point.parent] = true
+			ret = append(ret, r.addrs[point.parent])
+		}
+	}
+	return ret
+}
+
+// parents keeps the ring of the configured parents and the parents marked down
+var parents = struct {
+	mu        sync.Mutex
+	ring      *parentRing
+	downUntil map[string]time.Time
+}{downUntil: make(map[string]time.Time)}
+
+// parentOrder returns the parents for the key in the order of the ring, all of them and the ones not
+// marked down
+func parentOrder(list []coCfg.Parent, key string) (all []string, up []string) {
+	parents.mu.Lock()
+	defer parents.mu.Unlock()
+	if parents.ring == nil || !slices.Equal(parents.ring.parents, list) {
+		parents.ring = newParentRing(slices.Clone(list))
+	}
+	all = parents.ring.lookup(key)
+	now := time.Now()
+	for _, addr := range all {
+		if now.After(parents.downUntil[addr]) {
+			delete(parents.downUntil, addr)
+			up = append(up, addr)
+		}
+	}
+	return
+}
+
+// markParentDown skips the parent for parentDownTime
+func markParentDown(addr string, err error) {
+	parents.mu.Lock()
+	parents.downUntil[addr] = time.Now().Add(parentDownTime)
+	parents.mu.Unlock()
+	slog.Warn("BE ParentMapper: Parent marked down", "addr", addr, "error", err)
+}
@@ -0,0 +1,136 @@
+package backend_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/hcl/cdn/cacheNode/backend"
+	"github.com/hcl/cdn/cacheNode/backend/backendTestMock"
+	"github.com/hcl/cdn/cacheNode/config"
+	commonConfig "github.com/hcl/cdn/common/config"
+)
+
+// parentServer answers as a Mid, with its name & the path of the request
+func parentServer(name string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+		w.Write([]byte(name + " " + r.URL.Path))
+	}))
+}
+
+func parentOf(t *testing.T, ts *httptest.Server) commonConfig.Parent {
+	t.Helper()
+	u, _ := url.Parse(ts.URL)
+	port, _ := strconv.Atoi(u.Port())
+	return commonConfig.Parent{IP: u.Hostname(), Port: port}
+}
+
+func edgeConfig(parents []commonConfig.Parent, originFallback bool, originURL string) *config.RunConfig {
+	ds := commonConfig.DeliveryService{Name: "DS1", ClientURL: "http://example.com", OriginURL: originURL}
+	return &config.RunConfig{
+		Valid:    true,
+		Filename: "test",
+		Node: &commonConfig.CacheNode{
+			IP: "192.168.1.1", Port: 8080, Type: commonConfig.CacheNodeEdge,
+			Parents: parents, OriginFallback: originFallback,
+		},
+		ServiceList: &commonConfig.DeliveryServices{Version: 1, ServiceList: []commonConfig.DeliveryService{ds}},
+	}
+}
+
+func get(t *testing.T, h interface {
+	Do(*http.Request) (*http.Response, error)
+}, path string) string {
+	t.Helper()
+	req, _ := http.NewRequest("GET", "http://example.com"+path, nil)
+	resp, err := h.Do(req)
+	if err != nil {
+		t.Fatalf("Do %s failed: %v", path, err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return string(body)
+}
+
+func TestBackend_DoParentRing(t *testing.T) {
+	mid1 := parentServer("mid1")
+	defer mid1.Close()
+	mid2 := parentServer("mid2")
+	defer mid2.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+	cfg := edgeConfig([]commonConfig.Parent{parentOf(t, mid1), parentOf(t, mid2)}, false, "http://originurl.com")
+	store := &backendTestMock.RequestHandlerMock{HttpStatuscode: http.StatusOK, Header: map[string][]string{}}
+	backhandler, err := backend.Init(ctx, wg, cfg, store, nil)
+	if err != nil {
+		t.Fatalf("Backend Initialization failed")
+	}
+
+	// each object goes through one parent, the objects are spread over both
+	owner := make(map[string]string)
+	count := make(map[string]int)
+	for i := 0; i < 50; i++ {
+		path := fmt.Sprintf("/obj%d", i)
+		var name string
+		fmt.Sscanf(get(t, backhandler, path), "%s", &name)
+		owner[path] = name
+		count[name]++
+		if again := get(t, backhandler, path); again != name+" "+path {
+			t.Errorf("%s went through %s, then %q", path, name, again)
+		}
+	}
+	if count["mid1"] == 0 || count["mid2"] == 0 {
+		t.Fatalf("objects not spread over the parents: %v", count)
+	}
+
+	// the objects of a parent which cannot be reached go to the next parent of the ring, the others stay
+	mid1.Close()
+	for path, name := range owner {
+		if got := get(t, backhandler, path); got != "mid2 "+path {
+			t.Errorf("%s of %s went to %q", path, name, got)
+		}
+	}
+	cancel()
+	wg.Wait()
+}
+
+func TestBackend_DoParentOriginFallback(t *testing.T) {
+	mid := parentServer("mid")
+	mid.Close()
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("origin " + r.URL.Path))
+	}))
+	defer origin.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+	store := &backendTestMock.RequestHandlerMock{HttpStatuscode: http.StatusOK, Header: map[string][]string{}}
+	cfg := edgeConfig([]commonConfig.Parent{parentOf(t, mid)}, true, origin.URL+"/static")
+	backhandler, err := backend.Init(ctx, wg, cfg, store, nil)
+	if err != nil {
+		t.Fatalf("Backend Initialization failed")
+	}
+	// the origin path is mapped as on a node without parent
+	for i := 0; i < 2; i++ {
+		if got := get(t, backhandler, "/a.txt"); got != "origin /static/a.txt" {
+			t.Errorf("expected the origin when the parents are down, got %q", got)
+		}
+	}
+
+	// without fallback the request fails with the parent
+	cfg.Node.OriginFallback = false
+	req, _ := http.NewRequest("GET", "http://example.com/b.txt", nil)
+	if _, err := backhandler.Do(req); err == nil {
+		t.Error("expected the error of the parent without fallback to the origin")
+	}
+	cancel()
+	wg.Wait()
+}
@@ -1,16 +1,17 @@
 package backend
 
 import (
-	"io"
 	"log/slog"
 	"net/http"
 
 	"github.com/hcl/cdn/common/helper"
 )
 
+// forker hands the body to the client and to the store as two readers of one shared body, it is read from
+// the origin once and each reader goes at its own pace. Collapsed requests share the client reader.
 func forker(resp *http.Response) (origresp *http.Response, copyresp *http.Response, err error) {
 	slog.Info(" BE forker receives the response", "url", helper.GetString(resp.Request))
-	dupReader := helper.NewDupReadCloser(resp.Body)
+	body := helper.NewSharedBody(resp.Body)
 	//copying values
 	respNew := http.Response{
 		Status:           resp.Status,
@@ -28,8 +29,9 @@ func forker(resp *http.Response) (origresp *http.Response, copyresp *http.Respon
 		Request:          resp.Request,
 		TLS:              resp.TLS,
 	}
-	respNew.Body = io.NopCloser(dupReader.DupRdr())
-	resp.Body = io.NopCloser(dupReader)
+	respNew.Body = body.NewReader()
+	resp.Body = body.NewReader()
+	body.Release()
 	slog.Info(" BE forker responseBody is updated. ")
 	return resp, &respNew, nil
 }
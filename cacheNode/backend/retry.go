@@ -0,0 +1,99 @@
+package backend
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"slices"
+	"syscall"
+	"time"
+
+	coCfg "github.com/hcl/cdn/common/config"
+)
+
+// Defaults of the retry policy when the DS does not set them
+const (
+	defaultBackoff    = 100 * time.Millisecond
+	defaultMaxBackoff = 2 * time.Second
+)
+
+// errors retried when the DS does not list them
+var defaultRetryErrors = []string{"connect", "timeout"}
+
+func milliseconds(n int, def time.Duration) time.Duration {
+	if n > 0 {
+		return time.Duration(n) * time.Millisecond
+	}
+	return def
+}
+
+// attempts returns how many times the request may be fetched, the requests with a body or a method which
+// is not idempotent are fetched once
+func attempts(req *http.Request, ds *coCfg.DeliveryService) int {
+	if ds == nil || ds.Retry.MaxAttempts <= 1 || (req.Body != nil && req.Body != http.NoBody) {
+		return 1
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return ds.Retry.MaxAttempts
+	}
+	return 1
+}
+
+// errorKind classifies the error of a fetch as tls_verify, tls_handshake, connect, timeout or reset, ""
+// for the other errors
+func errorKind(err error) string {
+	var netErr net.Error
+	var opErr *net.OpError
+	var verifyErr *tls.CertificateVerificationError
+	var recordErr tls.RecordHeaderError
+	switch {
+	case errors.As(err, &verifyErr):
+		return "tls_verify"
+	case errors.As(err, &opErr) && opErr.Op == "remote error", errors.As(err, &recordErr):
+		// alert of the origin e.g. client certificate required, or the origin does not speak TLS
+		return "tls_handshake"
+	case connectError(err):
+		return "connect"
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return "reset"
+	}
+	return ""
+}
+
+// retry reports if the fetch is sent again: the error or the status is retried by the DS. A fetch the
+// circuit breaker did not send is not.
+func retry(ctx context.Context, policy *coCfg.RetryPolicy, response *http.Response, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, errCircuitOpen) {
+		return false
+	}
+	if err != nil {
+		retried := policy.Errors
+		if len(retried) == 0 {
+			retried = defaultRetryErrors
+		}
+		kind := errorKind(err)
+		return kind != "" && slices.Contains(retried, kind)
+	}
+	return slices.Contains(policy.Statuses, response.StatusCode)
+}
+
+// backoff returns the wait before the retry following attempt, doubled for each attempt up to the
+// MaxBackoff, half of it random so the nodes do not retry together
+func backoff(policy *coCfg.RetryPolicy, attempt int) time.Duration {
+	wait := milliseconds(policy.Backoff, defaultBackoff)
+	limit := milliseconds(policy.MaxBackoff, defaultMaxBackoff)
+	for i := 1; i < attempt && wait < limit; i++ {
+		wait *= 2
+	}
+	if wait > limit {
+		wait = limit
+	}
+	return wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1))
+}
@@ -0,0 +1,159 @@
+package backend_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hcl/cdn/cacheNode/backend"
+	"github.com/hcl/cdn/cacheNode/backend/backendTestMock"
+	"github.com/hcl/cdn/cacheNode/observability"
+	commonConfig "github.com/hcl/cdn/common/config"
+)
+
+// recordedEvents records the backend & circuit breaker events, the other events are dropped
+type recordedEvents struct {
+	mu       sync.Mutex
+	backends []observability.BackendEvent
+	circuits []observability.CircuitBreakerEvent
+}
+
+func (c *recordedEvents) RecordEventFrontend(observability.FrontendEvent)                     {}
+func (c *recordedEvents) RecordEventStorage(observability.StorageEvent)                       {}
+func (c *recordedEvents) RecordEventStorageDiskMetrics(observability.StorageDiskMetricsEvent) {}
+func (c *recordedEvents) RecordEventOriginHealth(observability.OriginHealthEvent)             {}
+func (c *recordedEvents) RecordEventBackend(e observability.BackendEvent) {
+	c.mu.Lock()
+	c.backends = append(c.backends, e)
+	c.mu.Unlock()
+}
+func (c *recordedEvents) RecordEventCircuitBreaker(e observability.CircuitBreakerEvent) {
+	c.mu.Lock()
+	c.circuits = append(c.circuits, e)
+	c.mu.Unlock()
+}
+
+func (c *recordedEvents) states() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var ret []string
+	for _, e := range c.circuits {
+		ret = append(ret, e.To)
+	}
+	return strings.Join(ret, ",")
+}
+
+func TestBackend_DoRetry(t *testing.T) {
+	var hits atomic.Int32
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) <= 2 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer origin.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+	defer cancel()
+	cfg := midConfig(commonConfig.DeliveryService{
+		Name: "DS1", ClientURL: "http://example.com", OriginURL: origin.URL,
+		Retry: commonConfig.RetryPolicy{MaxAttempts: 3, Statuses: []int{http.StatusBadGateway}, Backoff: 1, MaxBackoff: 5},
+	})
+	store := &backendTestMock.RequestHandlerMock{HttpStatuscode: http.StatusOK, Header: map[string][]string{}}
+	backhandler, err := backend.Init(ctx, wg, cfg, store, nil)
+	if err != nil {
+		t.Fatalf("Backend Initialization failed")
+	}
+
+	if body := get(t, backhandler, "/a.txt"); body != "ok" || hits.Load() != 3 {
+		t.Errorf("expected the third attempt to succeed, got %q after %d attempts", body, hits.Load())
+	}
+
+	// the requests which are not idempotent are sent once
+	hits.Store(0)
+	req, _ := http.NewRequest(http.MethodPost, "http://example.com/form", nil)
+	resp, err := backhandler.Do(req)
+	if err != nil {
+		t.Fatalf("Do failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadGateway || hits.Load() != 1 {
+		t.Errorf("expected one attempt of the POST, got %d after %d attempts", resp.StatusCode, hits.Load())
+	}
+}
+
+func TestBackend_DoCircuitBreaker(t *testing.T) {
+	var hits atomic.Int32
+	var down atomic.Bool
+	down.Store(true)
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if down.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer origin.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+	defer cancel()
+	cfg := midConfig(commonConfig.DeliveryService{
+		Name: "DS1", ClientURL: "http://example.com", OriginURL: origin.URL,
+		CircuitBreaker: commonConfig.CircuitBreaker{Enabled: true, FailureThreshold: 2, Cooldown: 1},
+	})
+	events := &recordedEvents{}
+	store := &backendTestMock.RequestHandlerMock{HttpStatuscode: http.StatusOK, Header: map[string][]string{}}
+	backhandler, err := backend.Init(ctx, wg, cfg, store, events)
+	if err != nil {
+		t.Fatalf("Backend Initialization failed")
+	}
+	do := func() (int, error) {
+		req, _ := http.NewRequest("GET", "http://example.com/a.txt", nil)
+		resp, err := backhandler.Do(req)
+		if err != nil {
+			return 0, err
+		}
+		resp.Body.Close()
+		return resp.StatusCode, nil
+	}
+
+	// the failures in a row open the circuit, the next fetches are not sent
+	for i := 0; i < 2; i++ {
+		if status, err := do(); err != nil || status != http.StatusInternalServerError {
+			t.Fatalf("expected the 500 of the origin, got %d %v", status, err)
+		}
+	}
+	if _, err := do(); err == nil || hits.Load() != 2 {
+		t.Errorf("expected the fetch failed while the circuit is open, got %v after %d fetches", err, hits.Load())
+	}
+
+	// after the cooldown a failed trial opens it again, a successful one closes it
+	time.Sleep(1100 * time.Millisecond)
+	if status, _ := do(); status != http.StatusInternalServerError || hits.Load() != 3 {
+		t.Errorf("expected the trial fetch sent, got %d after %d fetches", status, hits.Load())
+	}
+	if _, err := do(); err == nil {
+		t.Error("expected the circuit open again after the failed trial")
+	}
+	down.Store(false)
+	time.Sleep(1100 * time.Millisecond)
+	for i := 0; i < 2; i++ {
+		if status, err := do(); err != nil || status != http.StatusOK {
+			t.Errorf("expected the circuit closed, got %d %v", status, err)
+		}
+	}
+	if got := events.states(); got != "open,half-open,open,half-open,closed" {
+		t.Errorf("unexpected transitions %s", got)
+	}
+}
@@ -0,0 +1,101 @@
+package backend
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/hcl/cdn/cacheNode/common"
+	coCfg "github.com/hcl/cdn/common/config"
+	"github.com/hcl/cdn/common/helper"
+)
+
+// headers of a 304 Not Modified which never replace the ones of the stored content
+var notModifiedIgnoredHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Content-Length",
+	"Content-Encoding",
+	"Content-Range",
+	"Transfer-Encoding",
+}
+
+// addValidators makes the upstream request conditional on the validators of the stale copy
+func addValidators(req *http.Request, oldResp *http.Response) {
+	if oldResp == nil || oldResp.Header == nil {
+		return
+	}
+	if etag := oldResp.Header.Get("ETag"); etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified := oldResp.Header.Get("Last-Modified"); lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+}
+
+// revalidated serves the stale copy again with the headers of the 304 Not Modified merged in.
+// fresh is the set of headers that the store has to merge into the metadata of the content.
+func revalidated(req *http.Request, oldResp *http.Response, notModified *http.Response, ds *coCfg.DeliveryService, nodeName string) (response *http.Response, fresh http.Header, err error) {
+	slog.Info("BE Revalidator: Content not modified", "url", helper.GetString(req))
+	io.Copy(io.Discard, notModified.Body)
+	notModified.Body.Close()
+
+	fresh = notModified.Header.Clone()
+	for _, hdr := range notModifiedIgnoredHeaders {
+		fresh.Del(hdr)
+	}
+	if fresh.Get("Age") == "" {
+		fresh.Set("Age", "0")
+	}
+	if fresh.Get("Date") == "" {
+		// the age of the stored copy is computed from Date
+		fresh.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	}
+	// rewrite rules were applied to the stored headers, apply them to the fresh ones too
+	if _, err = headerRewriter(req, &http.Response{Header: fresh}, ds, nodeName); err != nil {
+		return
+	}
+
+	if oldResp.Header == nil {
+		oldResp.Header = make(http.Header)
+	}
+	for key, values := range fresh {
+		oldResp.Header[key] = values
+	}
+	oldResp.StatusCode = http.StatusOK
+	oldResp.Status = "200 OK"
+	// storage answers a HEAD without body, the stale copy has none to serve again
+	if req.Method == http.MethodHead && oldResp.Body != nil {
+		oldResp.Body.Close()
+	}
+	if req.Method == http.MethodHead || oldResp.Body == nil {
+		oldResp.Body = http.NoBody
+	}
+	return oldResp, fresh, nil
+}
+
+// updateMetadata merges the fresh headers into the stored metadata, the content itself is not rewritten.
+// storedHeader is the merged header of the stored copy, its Vary selects the variant to update.
+func updateMetadata(ctx context.Context, fresh http.Header, storedHeader http.Header, req *http.Request, ds *coCfg.DeliveryService, store common.RequestHandler) {
+	if store == nil {
+		slog.Info("BE Revalidator Nil Store ignoring metadata update", "url", helper.GetString(req))
+		return
+	}
+	patchreq, err := http.NewRequest(http.MethodPatch, helper.GetString(req), nil)
+	if err != nil {
+		slog.Error("BE Revalidator Error creating patch request to store", "error", err)
+		return
+	}
+	patchreq.Header = fresh.Clone()
+	addVaryRequestHeaders(patchreq, req, storedHeader)
+	addCacheKeyRequestHeaders(patchreq, req, ds)
+	patchreq = patchreq.WithContext(ctx)
+	storeResp, err := store.Do(patchreq)
+	if err != nil {
+		slog.Error("BE Revalidator Error updating metadata in store", "error", err)
+		return
+	}
+	slog.Info("BE Revalidator metadata updated", "url", helper.GetString(req), "status", storeResp.StatusCode)
+}
@@ -5,11 +5,15 @@ import (
 	"log/slog"
 	"net/http"
 
+	"github.com/hcl/cdn/cacheNode/cachePolicy"
 	"github.com/hcl/cdn/cacheNode/common"
+	coCfg "github.com/hcl/cdn/common/config"
 	"github.com/hcl/cdn/common/helper"
 )
 
-func save(ctx context.Context, resp *http.Response, req *http.Request, store common.RequestHandler) {
+func save(ctx context.Context, resp *http.Response, req *http.Request, ds *coCfg.DeliveryService, store common.RequestHandler) {
+	// the reader of the shared body is released whether the object is stored or not
+	defer resp.Body.Close()
 	if store == nil {
 		slog.Info("BE SAVER Nil Store ignoring save", "url", helper.GetString(req))
 		return
@@ -20,7 +24,9 @@ func save(ctx context.Context, resp *http.Response, req *http.Request, store com
 		slog.Error("BE SAVER Error creating post request to store", "error", err)
 		return
 	}
-	postreq.Header = resp.Header
+	postreq.Header = resp.Header.Clone()
+	addVaryRequestHeaders(postreq, req, resp.Header)
+	addCacheKeyRequestHeaders(postreq, req, ds)
 	postreq = postreq.WithContext(ctx)
 	storeResp, err := store.Do(postreq)
 	if err != nil {
@@ -29,3 +35,31 @@ func save(ctx context.Context, resp *http.Response, req *http.Request, store com
 	}
 	slog.Info(" BE Saver Success", "url", helper.GetString(req), "status", storeResp.StatusCode)
 }
+
+// addVaryRequestHeaders hands the request headers the response varies on over to the store,
+// they select the variant of the object. Variants by Accept-Encoding are stored by their content coding,
+// any client accepting that coding is served the same variant.
+func addVaryRequestHeaders(storeReq *http.Request, req *http.Request, respHeader http.Header) {
+	for _, name := range cachePolicy.VaryHeaders(respHeader) {
+		if name == "Accept-Encoding" {
+			storeReq.Header.Set(cachePolicy.VaryRequestHeaderPrefix+name, cachePolicy.ContentCoding(respHeader))
+			continue
+		}
+		if values := req.Header.Values(name); len(values) > 0 {
+			storeReq.Header[cachePolicy.VaryRequestHeaderPrefix+name] = values
+		}
+	}
+}
+
+// addCacheKeyRequestHeaders hands the request headers and cookies the cache key of the DS depends on
+// over to the store, they select the stored object along with the URL
+func addCacheKeyRequestHeaders(storeReq *http.Request, req *http.Request, ds *coCfg.DeliveryService) {
+	if ds == nil {
+		return
+	}
+	for _, name := range cachePolicy.KeyHeaders(&ds.CacheKey) {
+		if values := req.Header.Values(name); len(values) > 0 {
+			storeReq.Header[cachePolicy.VaryRequestHeaderPrefix+name] = values
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package backend
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hcl/cdn/cacheNode/config"
+	coCfg "github.com/hcl/cdn/common/config"
+)
+
+// Defaults of the upstream timeouts when the DS does not set them
+const (
+	defaultConnectTimeout      = 10 * time.Second
+	defaultTLSHandshakeTimeout = 10 * time.Second
+	defaultTTFBTimeout         = 10 * time.Second
+)
+
+// ttfbError fails an upstream request whose response headers did not come within the TTFBTimeout of the DS,
+// a timeout like the one of the ResponseHeaderTimeout of the transports
+type ttfbError struct{}
+
+func (ttfbError) Error() string   { return "upstream timeout awaiting response headers" }
+func (ttfbError) Timeout() bool   { return true }
+func (ttfbError) Temporary() bool { return true }
+
+var errTTFB net.Error = ttfbError{}
+
+// errIdleRead fails the body of an upstream response which did not send data for the IdleReadTimeout of the DS
+var errIdleRead = errors.New("upstream idle read timeout")
+
+type dsKey struct{}
+
+// withDeliveryService returns the context of a request fetched for the DS
+func withDeliveryService(ctx context.Context, ds *coCfg.DeliveryService) context.Context {
+	return context.WithValue(ctx, dsKey{}, ds)
+}
+
+// deliveryServiceOf returns the DS the request is fetched for, nil if none
+func deliveryServiceOf(ctx context.Context) *coCfg.DeliveryService {
+	ds, _ := ctx.Value(dsKey{}).(*coCfg.DeliveryService)
+	return ds
+}
+
+// upstreamSettings are the settings of a DS building its transport
+type upstreamSettings struct {
+	upstream  coCfg.Upstream
+	originTLS coCfg.OriginTLS
+}
+
+type upstreamTransport struct {
+	settings upstreamSettings
+	t        *http.Transport
+}
+
+// upstreamPool keeps one transport per DS setting its upstream timeouts, connections or origin TLS, the
+// other DS share transport. A transport is replaced when the settings of its DS change, the requests in
+// progress finish on the old one and its connections are closed once idle.
+type upstreamPool struct {
+	mu         sync.Mutex
+	transports map[string]*upstreamTransport
+}
+
+var upstreams = &upstreamPool{transports: make(map[string]*upstreamTransport)}
+
+// TLS versions of the OriginTLS of a DS
+var tlsVersions = map[string]uint16{
+	"":    tls.VersionTLS12,
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// originTLSConfig returns the client TLS config towards the HTTPS origins of a DS
+func originTLSConfig(originTLS *coCfg.OriginTLS) (*tls.Config, error) {
+	version, ok := tlsVersions[originTLS.MinVersion]
+	if !ok {
+		return nil, fmt.Errorf("unknown TLS version %q", originTLS.MinVersion)
+	}
+	ret := &tls.Config{ServerName: originTLS.ServerName, MinVersion: version}
+	if originTLS.CABundle != "" {
+		ret.RootCAs = x509.NewCertPool()
+		if !ret.RootCAs.AppendCertsFromPEM([]byte(originTLS.CABundle)) {
+			return nil, errors.New("no certificate in the CA bundle")
+		}
+	}
+	if originTLS.ClientCertificate != "" || originTLS.ClientKey != "" {
+		cert, err := tls.X509KeyPair([]byte(originTLS.ClientCertificate), []byte(originTLS.ClientKey))
+		if err != nil {
+			return nil, err
+		}
+		ret.Certificates = []tls.Certificate{cert}
+	}
+	return ret, nil
+}
+
+// connectTimeout is the ConnectTimeout of the DS, the default without DS. The h2c connections to the
+// parents are shared by the DS, they are dialed within the timeout of the request opening them.
+func connectTimeout(ds *coCfg.DeliveryService) time.Duration {
+	if ds == nil {
+		return defaultConnectTimeout
+	}
+	return milliseconds(ds.Upstream.ConnectTimeout, defaultConnectTimeout)
+}
+
+// ttfbTimeout is the TTFBTimeout of the DS, the default without DS. The h2c transport towards the parents
+// has no response header timeout, it is applied to each request.
+func ttfbTimeout(ds *coCfg.DeliveryService) time.Duration {
+	if ds == nil {
+		return defaultTTFBTimeout
+	}
+	return milliseconds(ds.Upstream.TTFBTimeout, defaultTTFBTimeout)
+}
+
+func newUpstreamTransport(settings upstreamSettings) (*http.Transport, error) {
+	tlsConfig, err := originTLSConfig(&settings.originTLS)
+	if err != nil {
+		return nil, err
+	}
+	t := transport.Clone()
+	dialer := &net.Dialer{Timeout: milliseconds(settings.upstream.ConnectTimeout, defaultConnectTimeout), KeepAlive: 30 * time.Second}
+	t.DialContext = dialer.DialContext
+	t.TLSClientConfig = tlsConfig
+	t.TLSHandshakeTimeout = milliseconds(settings.upstream.TLSHandshakeTimeout, defaultTLSHandshakeTimeout)
+	t.ResponseHeaderTimeout = milliseconds(settings.upstream.TTFBTimeout, defaultTTFBTimeout)
+	t.MaxConnsPerHost = settings.upstream.MaxConnsPerHost
+	return t, nil
+}
+
+// transport returns the transport of the DS, an error when its origin TLS settings are not valid
+func (u *upstreamPool) transport(cfg *config.RunConfig, ds *coCfg.DeliveryService) (*http.Transport, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if ds == nil {
+		return &transport, nil
+	}
+	settings := upstreamSettings{upstream: ds.Upstream, originTLS: ds.OriginTLS}
+	cur, ok := u.transports[ds.Name]
+	if ok && cur.settings == settings {
+		return cur.t, nil
+	}
+	if !ok && settings == (upstreamSettings{}) {
+		return &transport, nil
+	}
+	u.drop(cfg)
+	if ok {
+		slog.Info("BE Upstream: Upstream settings changed, replacing the transport", "ds", ds.Name)
+		cur.t.CloseIdleConnections()
+		delete(u.transports, ds.Name)
+	}
+	if settings == (upstreamSettings{}) {
+		return &transport, nil
+	}
+	t, err := newUpstreamTransport(settings)
+	if err != nil {
+		slog.Error("BE Upstream: Invalid origin TLS settings", "ds", ds.Name, "error", err)
+		return nil, err
+	}
+	u.transports[ds.Name] = &upstreamTransport{settings: settings, t: t}
+	return t, nil
+}
+
+// drop closes the transports of the DS no longer configured, u.mu is held
+func (u *upstreamPool) drop(cfg *config.RunConfig) {
+	live := make(map[string]bool)
+	for _, ds := range cfg.DSList() {
+		live[ds.Name] = true
+	}
+	for name, cur := range u.transports {
+		if !live[name] {
+			cur.t.CloseIdleConnections()
+			delete(u.transports, name)
+		}
+	}
+}
+
+// upstreamBody enforces the idle read & total timeouts of the DS while the body is read
+type upstreamBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+	idle   time.Duration
+	timer  *time.Timer // nil without idle read timeout
+	idled  atomic.Bool
+}
+
+func (b *upstreamBody) Read(p []byte) (n int, err error) {
+	n, err = b.ReadCloser.Read(p)
+	if b.timer != nil {
+		if b.idled.Load() {
+			return n, errIdleRead
+		}
+		b.timer.Reset(b.idle)
+	}
+	return
+}
+
+func (b *upstreamBody) Close() error {
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// withTimeouts returns the context of the fetch limited by the TotalTimeout of the DS, and the function
+// applying the IdleReadTimeout to the body of the response
+func withTimeouts(ctx context.Context, ds *coCfg.DeliveryService) (context.Context, func(*http.Response)) {
+	if ds == nil || (ds.Upstream.TotalTimeout <= 0 && ds.Upstream.IdleReadTimeout <= 0) {
+		return ctx, func(*http.Response) {}
+	}
+	var cancel context.CancelFunc
+	if ds.Upstream.TotalTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, milliseconds(ds.Upstream.TotalTimeout, 0))
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	return ctx, func(response *http.Response) {
+		if response == nil {
+			cancel()
+			return
+		}
+		body := &upstreamBody{ReadCloser: response.Body, cancel: cancel}
+		if ds.Upstream.IdleReadTimeout > 0 {
+			body.idle = milliseconds(ds.Upstream.IdleReadTimeout, 0)
+			body.timer = time.AfterFunc(body.idle, func() {
+				body.idled.Store(true)
+				cancel()
+			})
+		}
+		response.Body = body
+	}
+}
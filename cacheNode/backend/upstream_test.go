@@ -0,0 +1,209 @@
+package backend_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hcl/cdn/cacheNode/backend"
+	"github.com/hcl/cdn/cacheNode/backend/backendTestMock"
+	commonConfig "github.com/hcl/cdn/common/config"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+func TestBackend_DoUpstreamTimeouts(t *testing.T) {
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+		switch r.URL.Path {
+		case "/slow":
+			time.Sleep(300 * time.Millisecond)
+		case "/stall":
+			w.Write([]byte("part"))
+			w.(http.Flusher).Flush()
+			time.Sleep(300 * time.Millisecond)
+		case "/drip":
+			for i := 0; i < 10; i++ {
+				w.Write([]byte("."))
+				w.(http.Flusher).Flush()
+				time.Sleep(50 * time.Millisecond)
+			}
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer origin.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+	defer cancel()
+	cfg := midConfig(commonConfig.DeliveryService{
+		Name: "DS1", ClientURL: "http://example.com", OriginURL: origin.URL,
+		Upstream: commonConfig.Upstream{TTFBTimeout: 100, IdleReadTimeout: 100, TotalTimeout: 200},
+	})
+	store := &backendTestMock.RequestHandlerMock{HttpStatuscode: http.StatusOK, Header: map[string][]string{}}
+	backhandler, err := backend.Init(ctx, wg, cfg, store, nil)
+	if err != nil {
+		t.Fatalf("Backend Initialization failed")
+	}
+	read := func(path string) (string, error) {
+		req, _ := http.NewRequest("GET", "http://example.com"+path, nil)
+		resp, err := backhandler.Do(req)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		return string(body), err
+	}
+
+	if body, err := read("/fast"); err != nil || body != "ok" {
+		t.Errorf("expected the fast object, got %q %v", body, err)
+	}
+	start := time.Now()
+	if _, err := read("/slow"); err == nil || time.Since(start) > 250*time.Millisecond {
+		t.Errorf("expected the time to first byte exceeded, got %v after %v", err, time.Since(start))
+	}
+	if _, err := read("/stall"); err == nil || !strings.Contains(err.Error(), "idle") {
+		t.Errorf("expected the idle read timeout, got %v", err)
+	}
+	if _, err := read("/drip"); err == nil {
+		t.Error("expected the total timeout of the slow transfer")
+	}
+}
+
+func TestBackend_DoUpstreamSettingsChange(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	mid := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+		}
+		time.Sleep(150 * time.Millisecond)
+		w.Header().Set("Cache-Control", "no-store")
+		w.Write([]byte("mid " + r.URL.Path))
+	}))
+	defer mid.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+	cfg := edgeConfig([]commonConfig.Parent{parentOf(t, mid)}, false, "http://originurl.com")
+	cfg.ServiceList.ServiceList[0].Upstream.TTFBTimeout = 50
+	store := &backendTestMock.RequestHandlerMock{HttpStatuscode: http.StatusOK, Header: map[string][]string{}}
+	backhandler, err := backend.Init(ctx, wg, cfg, store, nil)
+	if err != nil {
+		t.Fatalf("Backend Initialization failed")
+	}
+	req, _ := http.NewRequest("GET", "http://example.com/a.txt", nil)
+	if _, err := backhandler.Do(req); err == nil {
+		t.Error("expected the time to first byte of the parent exceeded")
+	}
+
+	// the new settings of the DS apply to the next fetches
+	for inFlight.Load() > 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	peak.Store(0)
+	cfg.ServiceList.ServiceList[0].Upstream = commonConfig.Upstream{TTFBTimeout: 1000, MaxConnsPerHost: 1}
+	var fetches sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		fetches.Add(1)
+		go func() {
+			defer fetches.Done()
+			req, _ := http.NewRequest("GET", "http://example.com/a.txt", nil)
+			resp, err := backhandler.Do(req)
+			if err != nil {
+				t.Errorf("Do failed with the new settings: %v", err)
+				return
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}()
+	}
+	fetches.Wait()
+	if peak.Load() != 1 {
+		t.Errorf("expected one connection to the parent at a time, got %d", peak.Load())
+	}
+	cancel()
+	wg.Wait()
+}
+
+func TestBackend_DoParentH2CTimeouts(t *testing.T) {
+	var mu sync.Mutex
+	conns := make(map[string]bool)
+	mid := httptest.NewServer(h2c.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		conns[r.RemoteAddr] = true
+		mu.Unlock()
+		w.Header().Set("Cache-Control", "no-store")
+		switch r.URL.Path {
+		case "/slow":
+			time.Sleep(300 * time.Millisecond)
+		case "/drip":
+			for i := 0; i < 10; i++ {
+				w.Write([]byte("."))
+				w.(http.Flusher).Flush()
+				time.Sleep(50 * time.Millisecond)
+			}
+		}
+		w.Write([]byte("ok"))
+	}), &http2.Server{}))
+	defer mid.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+	defer cancel()
+	cfg := edgeConfig([]commonConfig.Parent{parentOf(t, mid)}, false, "http://originurl.com")
+	cfg.Node.ParentH2C = true
+	cfg.ServiceList.ServiceList[0].Upstream = commonConfig.Upstream{ConnectTimeout: 100, TTFBTimeout: 100, TotalTimeout: 400}
+	store := &backendTestMock.RequestHandlerMock{HttpStatuscode: http.StatusOK, Header: map[string][]string{}}
+	backhandler, err := backend.Init(ctx, wg, cfg, store, nil)
+	if err != nil {
+		t.Fatalf("Backend Initialization failed")
+	}
+	read := func(path string) (string, error) {
+		req, _ := http.NewRequest("GET", "http://example.com"+path, nil)
+		resp, err := backhandler.Do(req)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		return string(body), err
+	}
+
+	// the requests arriving together share the connection dialed for the first one
+	var fetches sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		fetches.Add(1)
+		go func() {
+			defer fetches.Done()
+			if body, err := read("/fast"); err != nil || body != "ok" {
+				t.Errorf("expected the fast object, got %q %v", body, err)
+			}
+		}()
+	}
+	fetches.Wait()
+	start := time.Now()
+	if _, err := read("/slow"); err == nil || time.Since(start) > 250*time.Millisecond {
+		t.Errorf("expected the time to first byte exceeded, got %v after %v", err, time.Since(start))
+	}
+	if _, err := read("/drip"); err == nil {
+		t.Error("expected the total timeout of the slow transfer")
+	}
+	if body, err := read("/fast"); err != nil || body != "ok" {
+		t.Errorf("expected the fast object after the timeouts, got %q %v", body, err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(conns) != 1 {
+		t.Errorf("expected one h2c connection to the parent, got %d", len(conns))
+	}
+}
@@ -0,0 +1,146 @@
+package cachePolicy
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/hcl/cdn/common/config"
+)
+
+// Key identifies one cached object. The Collapser, storage and invalidation all build it with NewKey,
+// so that requests share a collapsed fetch exactly when they share the stored object.
+type Key struct {
+	Host      string // lower cased host of the request
+	Path      string // path, lower cased when the DS ignores case
+	Qualifier string // query params, headers and cookies selected by the DS, "" when none
+}
+
+// NewKey builds the key of the request URL u for the host, reqHdr supplies the header and cookie
+// components. A nil policy keys on host and path only.
+func NewKey(u *url.URL, host string, reqHdr http.Header, policy *config.CacheKey) Key {
+	if host == "" {
+		host = u.Host
+	}
+	if policy == nil {
+		policy = &config.CacheKey{}
+	}
+	key := Key{
+		Host: strings.ToLower(host),
+		Path: u.Path,
+	}
+	if policy.IgnoreCase {
+		key.Path = strings.ToLower(key.Path)
+	}
+
+	var parts []string
+	if query := keyQuery(u.RawQuery, policy); query != "" {
+		parts = append(parts, query)
+	}
+	for _, name := range policy.Headers {
+		if values := reqHdr.Values(name); len(values) > 0 {
+			parts = append(parts, "h:"+strings.ToLower(name)+"="+strings.Join(values, ","))
+		}
+	}
+	if len(policy.Cookies) > 0 {
+		cookieReq := &http.Request{Header: http.Header{"Cookie": reqHdr.Values("Cookie")}}
+		for _, name := range policy.Cookies {
+			if cookie, err := cookieReq.Cookie(name); err == nil {
+				parts = append(parts, "c:"+name+"="+cookie.Value)
+			}
+		}
+	}
+	key.Qualifier = strings.Join(parts, "|")
+	return key
+}
+
+// String returns the key as URL, e.g. "http://example.com/app.js?v=123"
+func (k Key) String() string {
+	s := "http://" + k.Host + k.Path
+	if k.Qualifier != "" {
+		s += "?" + k.Qualifier
+	}
+	return s
+}
+
+// Hash identifies the qualifier in file names, it is empty when the key has no qualifier
+func (k Key) Hash() string {
+	if k.Qualifier == "" {
+		return ""
+	}
+	sum := sha1.Sum([]byte(k.Qualifier))
+	return hex.EncodeToString(sum[:8])
+}
+
+// KeyHeaders returns the request headers the key depends on besides the URL
+func KeyHeaders(policy *config.CacheKey) []string {
+	if policy == nil {
+		return nil
+	}
+	names := make([]string, 0, len(policy.Headers)+1)
+	for _, name := range policy.Headers {
+		names = append(names, http.CanonicalHeaderKey(name))
+	}
+	if len(policy.Cookies) > 0 {
+		names = append(names, "Cookie")
+	}
+	return names
+}
+
+// keyQuery keeps the query params selected by the policy, in request order unless they are sorted
+func keyQuery(rawQuery string, policy *config.CacheKey) string {
+	if rawQuery == "" || policy.QueryMode == config.CacheKeyQueryIgnoreAll {
+		return ""
+	}
+	var params []string
+	for _, param := range strings.Split(rawQuery, "&") {
+		if param == "" {
+			continue
+		}
+		name, _, _ := strings.Cut(param, "=")
+		if unescaped, err := url.QueryUnescape(name); err == nil {
+			name = unescaped
+		}
+		switch policy.QueryMode {
+		case config.CacheKeyQueryAllowList:
+			if !matchParam(name, policy.QueryParams, policy.IgnoreCase) {
+				continue
+			}
+		case config.CacheKeyQueryDenyList:
+			if matchParam(name, policy.QueryParams, policy.IgnoreCase) {
+				continue
+			}
+		}
+		if policy.IgnoreCase {
+			param = strings.ToLower(param)
+		}
+		params = append(params, param)
+	}
+	if policy.SortQuery {
+		sort.Strings(params)
+	}
+	return strings.Join(params, "&")
+}
+
+// matchParam matches the param name against the list, a trailing "*" matches by prefix
+func matchParam(name string, list []string, ignoreCase bool) bool {
+	if ignoreCase {
+		name = strings.ToLower(name)
+	}
+	for _, pattern := range list {
+		if ignoreCase {
+			pattern = strings.ToLower(pattern)
+		}
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		} else if name == pattern {
+			return true
+		}
+	}
+	return false
+}
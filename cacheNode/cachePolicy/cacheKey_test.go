@@ -0,0 +1,46 @@
+package cachePolicy
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/hcl/cdn/common/config"
+)
+
+func TestNewKey(t *testing.T) {
+	cases := []struct {
+		name   string
+		url    string
+		hdr    []string
+		policy *config.CacheKey
+		want   string
+	}{
+		{"default ignores query", "http://Example.com/a.js?v=1", nil, nil, "http://example.com/a.js"},
+		{"include all keeps order", "http://example.com/a.js?b=2&a=1", nil,
+			&config.CacheKey{QueryMode: config.CacheKeyQueryIncludeAll}, "http://example.com/a.js?b=2&a=1"},
+		{"include all sorted", "http://example.com/a.js?b=2&a=1", nil,
+			&config.CacheKey{QueryMode: config.CacheKeyQueryIncludeAll, SortQuery: true}, "http://example.com/a.js?a=1&b=2"},
+		{"allow list", "http://example.com/a.js?v=123&utm_source=x", nil,
+			&config.CacheKey{QueryMode: config.CacheKeyQueryAllowList, QueryParams: []string{"v"}}, "http://example.com/a.js?v=123"},
+		{"deny list with prefix", "http://example.com/a.js?utm_source=x&v=123&UTM_medium=y", nil,
+			&config.CacheKey{QueryMode: config.CacheKeyQueryDenyList, QueryParams: []string{"utm_*"}, IgnoreCase: true}, "http://example.com/a.js?v=123"},
+		{"ignore case", "http://example.com/A.js?V=ABC", nil,
+			&config.CacheKey{QueryMode: config.CacheKeyQueryIncludeAll, IgnoreCase: true}, "http://example.com/a.js?v=abc"},
+		{"header and cookie", "http://example.com/a.js", []string{"X-Device", "mobile", "Cookie", "lang=en; session=1"},
+			&config.CacheKey{Headers: []string{"x-device"}, Cookies: []string{"lang"}}, "http://example.com/a.js?h:x-device=mobile|c:lang=en"},
+	}
+	for _, tc := range cases {
+		u, _ := url.Parse(tc.url)
+		key := NewKey(u, "", header(tc.hdr...), tc.policy)
+		if got := key.String(); got != tc.want {
+			t.Errorf("%s: expected %s, got %s", tc.name, tc.want, got)
+		}
+		if (key.Hash() == "") != (key.Qualifier == "") {
+			t.Errorf("%s: unexpected hash %q for qualifier %q", tc.name, key.Hash(), key.Qualifier)
+		}
+	}
+
+	if names := KeyHeaders(&config.CacheKey{Headers: []string{"x-device"}, Cookies: []string{"lang"}}); len(names) != 2 || names[0] != "X-Device" || names[1] != "Cookie" {
+		t.Errorf("unexpected key headers %v", names)
+	}
+}
@@ -0,0 +1,368 @@
+// Package cachePolicy decides, as a shared cache (RFC 9111), whether a response may be stored
+// and whether a stored response may be served without revalidation.
+// It is used by the frontend (Validator), the backend (saver) and storage (CacheEvictor bookkeeping).
+package cachePolicy
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// heuristic freshness is this fraction of the time since Last-Modified (RFC 9111 section 4.2.2)
+	heuristicFraction = 10
+	// and never more than a day
+	maxHeuristicLifetime = 24 * time.Hour
+)
+
+// status codes which are cacheable by default (RFC 9110 section 15.1)
+var heuristicallyCacheable = map[int]bool{
+	http.StatusOK:                   true,
+	http.StatusNonAuthoritativeInfo: true,
+	http.StatusNoContent:            true,
+	http.StatusPartialContent:       true,
+	http.StatusMultipleChoices:      true,
+	http.StatusMovedPermanently:     true,
+	http.StatusPermanentRedirect:    true,
+	http.StatusNotFound:             true,
+	http.StatusMethodNotAllowed:     true,
+	http.StatusGone:                 true,
+	http.StatusRequestURITooLong:    true,
+	http.StatusNotImplemented:       true,
+}
+
+// Directives holds the Cache-Control directives, keys are lower case, values unquoted
+type Directives map[string]string
+
+// ParseCacheControl parses every Cache-Control header of hdr,
+// e.g. `public, max-age=60, no-cache="Set-Cookie"`
+func ParseCacheControl(hdr http.Header) Directives {
+	d := make(Directives)
+	for _, value := range hdr.Values("Cache-Control") {
+		for _, directive := range splitDirectives(value) {
+			name, arg, _ := strings.Cut(directive, "=")
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name == "" {
+				continue
+			}
+			if _, exists := d[name]; exists {
+				// first occurrence wins on duplicates
+				continue
+			}
+			d[name] = strings.Trim(strings.TrimSpace(arg), `"`)
+		}
+	}
+	return d
+}
+
+// splitDirectives splits on commas which are not inside a quoted string
+func splitDirectives(value string) []string {
+	var directives []string
+	inQuotes := false
+	start := 0
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '"':
+			inQuotes = !inQuotes
+		case ',':
+			if !inQuotes {
+				directives = append(directives, strings.TrimSpace(value[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(directives, strings.TrimSpace(value[start:]))
+}
+
+// Has reports whether the directive is present
+func (d Directives) Has(name string) bool {
+	_, ok := d[name]
+	return ok
+}
+
+// Seconds returns the delta-seconds argument of the directive, ok is false if absent or invalid
+func (d Directives) Seconds(name string) (seconds time.Duration, ok bool) {
+	arg, exists := d[name]
+	if !exists || arg == "" {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	if n < 0 {
+		n = 0
+	}
+	return time.Duration(n) * time.Second, true
+}
+
+// hasExplicitExpiration reports whether the response carries its own freshness lifetime
+func hasExplicitExpiration(hdr http.Header, cc Directives) bool {
+	return cc.Has("s-maxage") || cc.Has("max-age") || hdr.Get("Expires") != ""
+}
+
+// FreshnessLifetime returns how long the response is fresh for a shared cache
+// (RFC 9111 section 4.2.1). heuristic is true when it was derived from Last-Modified.
+func FreshnessLifetime(hdr http.Header, statusCode int) (lifetime time.Duration, heuristic bool) {
+	cc := ParseCacheControl(hdr)
+	if seconds, ok := cc.Seconds("s-maxage"); ok {
+		return seconds, false
+	}
+	if seconds, ok := cc.Seconds("max-age"); ok {
+		return seconds, false
+	}
+	date, err := http.ParseTime(hdr.Get("Date"))
+	if err != nil {
+		date = time.Now()
+	}
+	if expiresStr := hdr.Get("Expires"); expiresStr != "" {
+		expires, err := http.ParseTime(expiresStr)
+		if err != nil || !expires.After(date) {
+			// invalid dates (e.g. "0") mean already expired
+			return 0, false
+		}
+		return expires.Sub(date), false
+	}
+	if !heuristicallyCacheable[statusCode] && !cc.Has("public") {
+		return 0, false
+	}
+	lastModified, err := http.ParseTime(hdr.Get("Last-Modified"))
+	if err != nil || !lastModified.Before(date) {
+		return 0, false
+	}
+	lifetime = date.Sub(lastModified) / heuristicFraction
+	if lifetime > maxHeuristicLifetime {
+		lifetime = maxHeuristicLifetime
+	}
+	return lifetime, true
+}
+
+// Age returns the value of the Age header, storage keeps it current for the stored responses
+func Age(hdr http.Header) time.Duration {
+	age, err := strconv.ParseInt(strings.TrimSpace(hdr.Get("Age")), 10, 64)
+	if err != nil || age < 0 {
+		return 0
+	}
+	return time.Duration(age) * time.Second
+}
+
+// InitialAge returns the age of the response at the time it was received (RFC 9111 section 4.2.3),
+// the larger of the Age header and the apparent age from the Date header.
+func InitialAge(hdr http.Header, responseTime time.Time) time.Duration {
+	age := Age(hdr)
+	if date, err := http.ParseTime(hdr.Get("Date")); err == nil {
+		if apparentAge := responseTime.Sub(date); apparentAge > age {
+			age = apparentAge
+		}
+	}
+	return age.Truncate(time.Second)
+}
+
+// CurrentAge returns the age of a response received at responseTime
+func CurrentAge(hdr http.Header, responseTime time.Time, now time.Time) time.Duration {
+	resident := now.Sub(responseTime)
+	if resident < 0 {
+		resident = 0
+	}
+	return InitialAge(hdr, responseTime) + resident.Truncate(time.Second)
+}
+
+// IsStorable decides whether a shared cache may store the response to the request (RFC 9111 section 3).
+// Storage keeps the body & headers of full objects and serves them as 200, so only the 200 responses to
+// GET are stored; a HEAD response has no body to replace the stored object with.
+func IsStorable(req *http.Request, resp *http.Response) bool {
+	if req.Method != http.MethodGet || resp.StatusCode != http.StatusOK {
+		return false
+	}
+	if ParseCacheControl(req.Header).Has("no-store") {
+		return false
+	}
+	cc := ParseCacheControl(resp.Header)
+	if cc.Has("no-store") || cc.Has("private") {
+		return false
+	}
+	for _, name := range VaryHeaders(resp.Header) {
+		if name == "*" {
+			// the variant cannot be selected from the request headers
+			return false
+		}
+	}
+	if req.Header.Get("Authorization") != "" &&
+		!cc.Has("public") && !cc.Has("must-revalidate") && !cc.Has("s-maxage") {
+		return false
+	}
+	return hasExplicitExpiration(resp.Header, cc) || cc.Has("public") ||
+		heuristicallyCacheable[resp.StatusCode]
+}
+
+// Collapsible reports whether requests like req may share one fetch. Only GET & HEAD requests the cache
+// may store are collapsed, the response to a request with credentials may be private to its client.
+func Collapsible(req *http.Request) bool {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return false
+	}
+	if ParseCacheControl(req.Header).Has("no-store") {
+		return false
+	}
+	return req.Header.Get("Authorization") == ""
+}
+
+// MustRevalidate reports whether the stored response must never be served stale
+func MustRevalidate(hdr http.Header) bool {
+	cc := ParseCacheControl(hdr)
+	return cc.Has("must-revalidate") || cc.Has("proxy-revalidate") || cc.Has("s-maxage")
+}
+
+// IsFresh reports whether the stored response, whose Age header is current, is still fresh
+func IsFresh(hdr http.Header) bool {
+	lifetime, _ := FreshnessLifetime(hdr, http.StatusOK)
+	return lifetime > Age(hdr)
+}
+
+// CanServeStored decides whether the stored response may be served to the request without
+// revalidation, honouring both the response and the request Cache-Control directives.
+func CanServeStored(req *http.Request, hdr http.Header) bool {
+	respCC := ParseCacheControl(hdr)
+	if respCC.Has("no-cache") {
+		return false
+	}
+	reqCC := ParseCacheControl(req.Header)
+	if reqCC.Has("no-cache") || (len(reqCC) == 0 && req.Header.Get("Pragma") == "no-cache") {
+		return false
+	}
+	age := Age(hdr)
+	lifetime, _ := FreshnessLifetime(hdr, http.StatusOK)
+	if maxAge, ok := reqCC.Seconds("max-age"); ok && age > maxAge {
+		return false
+	}
+	if minFresh, ok := reqCC.Seconds("min-fresh"); ok && lifetime-age < minFresh {
+		return false
+	}
+	if lifetime > age {
+		return true
+	}
+	// stale, the client may still accept it unless the origin forbids serving stale
+	if !reqCC.Has("max-stale") || MustRevalidate(hdr) {
+		return false
+	}
+	maxStale, ok := reqCC.Seconds("max-stale")
+	return !ok || age-lifetime <= maxStale
+}
+
+// OnlyIfCached reports whether the client asked not to contact the upstream
+func OnlyIfCached(req *http.Request) bool {
+	return ParseCacheControl(req.Header).Has("only-if-cached")
+}
+
+// Staleness returns for how long the stored response, whose Age header is current, has been stale
+func Staleness(hdr http.Header) time.Duration {
+	lifetime, _ := FreshnessLifetime(hdr, http.StatusOK)
+	return Age(hdr) - lifetime
+}
+
+// mayServeStale reports whether the directives of the stored response allow serving it stale at all
+func mayServeStale(hdr http.Header) bool {
+	return !ParseCacheControl(hdr).Has("no-cache") && !MustRevalidate(hdr)
+}
+
+// CanServeWhileRevalidate decides whether the stale response may be served while it is revalidated
+// in the background (RFC 5861 section 3). defaultWindow applies when the response has no
+// stale-while-revalidate directive.
+func CanServeWhileRevalidate(req *http.Request, hdr http.Header, defaultWindow time.Duration) bool {
+	if !mayServeStale(hdr) {
+		return false
+	}
+	reqCC := ParseCacheControl(req.Header)
+	if reqCC.Has("no-cache") || reqCC.Has("max-age") || reqCC.Has("min-fresh") ||
+		(len(reqCC) == 0 && req.Header.Get("Pragma") == "no-cache") {
+		return false
+	}
+	window, ok := ParseCacheControl(hdr).Seconds("stale-while-revalidate")
+	if !ok {
+		window = defaultWindow
+	}
+	return Staleness(hdr) <= window
+}
+
+// CanServeOnError decides whether the stale response may be served when the upstream fails
+// (RFC 5861 section 4). The directive may come from the response or the request, defaultWindow
+// applies when neither has it.
+func CanServeOnError(req *http.Request, hdr http.Header, defaultWindow time.Duration) bool {
+	if !mayServeStale(hdr) {
+		return false
+	}
+	window, ok := ParseCacheControl(hdr).Seconds("stale-if-error")
+	if !ok {
+		window = defaultWindow
+	}
+	if reqWindow, ok := ParseCacheControl(req.Header).Seconds("stale-if-error"); ok && reqWindow > window {
+		window = reqWindow
+	}
+	return Staleness(hdr) <= window
+}
+
+// VaryRequestHeaderPrefix prefixes the varied request headers when the backend hands a response
+// over to storage, e.g. "X-Vary-Request-Accept-Encoding: gzip", so that storage can key the variant.
+// The request headers and cookies of the cache key (see NewKey) are handed over the same way.
+const VaryRequestHeaderPrefix = "X-Vary-Request-"
+
+// VaryHeaders returns the sorted canonical names of the request headers the response varies on
+func VaryHeaders(hdr http.Header) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, value := range hdr.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			if name != "*" {
+				name = http.CanonicalHeaderKey(name)
+			}
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// normalizeHeaderValues lower cases the comma separated members of the header values,
+// drops white space and sorts them, so that "gzip, br" and "br,gzip" select the same variant
+func normalizeHeaderValues(values []string) string {
+	var members []string
+	for _, value := range values {
+		for _, member := range strings.Split(value, ",") {
+			member = strings.Join(strings.Fields(strings.ToLower(member)), "")
+			if member != "" {
+				members = append(members, member)
+			}
+		}
+	}
+	sort.Strings(members)
+	return strings.Join(members, ",")
+}
+
+// VariantKey identifies the variant selected by the request headers named in vary.
+// It is empty when the response does not vary.
+func VariantKey(vary []string, reqHdr http.Header) string {
+	if len(vary) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	for _, name := range vary {
+		b.WriteString(strings.ToLower(name))
+		b.WriteString("=")
+		b.WriteString(normalizeHeaderValues(reqHdr.Values(name)))
+		b.WriteString("\n")
+	}
+	sum := sha1.Sum([]byte(b.String()))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,206 @@
+package cachePolicy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func header(kv ...string) http.Header {
+	hdr := make(http.Header)
+	for i := 0; i+1 < len(kv); i += 2 {
+		hdr.Add(kv[i], kv[i+1])
+	}
+	return hdr
+}
+
+func TestParseCacheControl(t *testing.T) {
+	cc := ParseCacheControl(header("Cache-Control", `public, Max-Age=60, no-cache="Set-Cookie, Via"`, "Cache-Control", "s-maxage=30"))
+	if !cc.Has("public") || cc["no-cache"] != "Set-Cookie, Via" {
+		t.Errorf("unexpected directives %v", cc)
+	}
+	if seconds, ok := cc.Seconds("max-age"); !ok || seconds != 60*time.Second {
+		t.Errorf("expected max-age 60, got %v %v", seconds, ok)
+	}
+	if seconds, ok := cc.Seconds("s-maxage"); !ok || seconds != 30*time.Second {
+		t.Errorf("expected s-maxage 30, got %v %v", seconds, ok)
+	}
+	if _, ok := cc.Seconds("public"); ok {
+		t.Errorf("public has no delta-seconds")
+	}
+}
+
+func TestFreshnessLifetime(t *testing.T) {
+	date := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		hdr       http.Header
+		status    int
+		want      time.Duration
+		heuristic bool
+	}{
+		{"max-age after other directives", header("Cache-Control", "public, max-age=60"), 200, 60 * time.Second, false},
+		{"s-maxage wins", header("Cache-Control", "max-age=60, s-maxage=10"), 200, 10 * time.Second, false},
+		{"Expires minus Date", header("Date", date.Format(http.TimeFormat), "Expires", date.Add(time.Hour).Format(http.TimeFormat)), 200, time.Hour, false},
+		{"max-age wins over Expires", header("Cache-Control", "max-age=5", "Date", date.Format(http.TimeFormat), "Expires", date.Add(time.Hour).Format(http.TimeFormat)), 200, 5 * time.Second, false},
+		{"invalid Expires", header("Date", date.Format(http.TimeFormat), "Expires", "0"), 200, 0, false},
+		{"heuristic", header("Date", date.Format(http.TimeFormat), "Last-Modified", date.Add(-10*time.Hour).Format(http.TimeFormat)), 200, time.Hour, true},
+		{"heuristic capped", header("Date", date.Format(http.TimeFormat), "Last-Modified", date.Add(-1000*time.Hour).Format(http.TimeFormat)), 200, 24 * time.Hour, true},
+		{"no heuristic for 500", header("Date", date.Format(http.TimeFormat), "Last-Modified", date.Add(-10*time.Hour).Format(http.TimeFormat)), 500, 0, false},
+		{"nothing", header(), 200, 0, false},
+	}
+	for _, tc := range tests {
+		got, heuristic := FreshnessLifetime(tc.hdr, tc.status)
+		if got != tc.want || heuristic != tc.heuristic {
+			t.Errorf("%s: expected %v (heuristic %v), got %v (heuristic %v)", tc.name, tc.want, tc.heuristic, got, heuristic)
+		}
+	}
+}
+
+func TestCurrentAge(t *testing.T) {
+	responseTime := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	now := responseTime.Add(30 * time.Second)
+
+	hdr := header("Age", "10", "Date", responseTime.Format(http.TimeFormat))
+	if got := CurrentAge(hdr, responseTime, now); got != 40*time.Second {
+		t.Errorf("expected 40s, got %v", got)
+	}
+	// apparent age from an old Date is larger than Age
+	hdr = header("Age", "10", "Date", responseTime.Add(-time.Minute).Format(http.TimeFormat))
+	if got := CurrentAge(hdr, responseTime, now); got != 90*time.Second {
+		t.Errorf("expected 90s, got %v", got)
+	}
+}
+
+func TestIsStorable(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		reqHdr  http.Header
+		status  int
+		respHdr http.Header
+		want    bool
+	}{
+		{"plain 200", http.MethodGet, header(), 200, header(), true},
+		{"no-store", http.MethodGet, header(), 200, header("Cache-Control", "no-store"), false},
+		{"private", http.MethodGet, header(), 200, header("Cache-Control", "private, max-age=60"), false},
+		{"request no-store", http.MethodGet, header("Cache-Control", "no-store"), 200, header(), false},
+		{"POST", http.MethodPost, header(), 200, header("Cache-Control", "max-age=60"), false},
+		{"500 without freshness", http.MethodGet, header(), 500, header(), false},
+		{"500 with max-age", http.MethodGet, header(), 500, header("Cache-Control", "max-age=60"), false},
+		{"404 with max-age", http.MethodGet, header(), 404, header("Cache-Control", "max-age=60"), false},
+		{"301", http.MethodGet, header(), 301, header(), false},
+		{"HEAD", http.MethodHead, header(), 200, header("Cache-Control", "max-age=60"), false},
+		{"Authorization", http.MethodGet, header("Authorization", "Basic x"), 200, header("Cache-Control", "max-age=60"), false},
+		{"Authorization with public", http.MethodGet, header("Authorization", "Basic x"), 200, header("Cache-Control", "public, max-age=60"), true},
+	}
+	for _, tc := range tests {
+		req := httptest.NewRequest(tc.method, "http://example.com/a", nil)
+		req.Header = tc.reqHdr
+		resp := &http.Response{StatusCode: tc.status, Header: tc.respHdr}
+		if got := IsStorable(req, resp); got != tc.want {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestCollapsible(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		reqHdr http.Header
+		want   bool
+	}{
+		{"GET", http.MethodGet, header(), true},
+		{"HEAD", http.MethodHead, header(), true},
+		{"POST", http.MethodPost, header(), false},
+		{"request no-store", http.MethodGet, header("Cache-Control", "no-store"), false},
+		{"Authorization", http.MethodGet, header("Authorization", "Basic x"), false},
+	}
+	for _, tc := range tests {
+		req := httptest.NewRequest(tc.method, "http://example.com/a", nil)
+		req.Header = tc.reqHdr
+		if got := Collapsible(req); got != tc.want {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestCanServeStored(t *testing.T) {
+	tests := []struct {
+		name    string
+		reqHdr  http.Header
+		respHdr http.Header
+		want    bool
+	}{
+		{"fresh", header(), header("Cache-Control", "public, max-age=60", "Age", "10"), true},
+		{"stale", header(), header("Cache-Control", "max-age=60", "Age", "70"), false},
+		{"no-cache response", header(), header("Cache-Control", "no-cache, max-age=60", "Age", "1"), false},
+		{"no-cache request", header("Cache-Control", "no-cache"), header("Cache-Control", "max-age=60", "Age", "1"), false},
+		{"Pragma no-cache", header("Pragma", "no-cache"), header("Cache-Control", "max-age=60", "Age", "1"), false},
+		{"request max-age", header("Cache-Control", "max-age=5"), header("Cache-Control", "max-age=60", "Age", "10"), false},
+		{"request min-fresh", header("Cache-Control", "min-fresh=55"), header("Cache-Control", "max-age=60", "Age", "10"), false},
+		{"max-stale accepts", header("Cache-Control", "max-stale=20"), header("Cache-Control", "max-age=60", "Age", "70"), true},
+		{"max-stale too old", header("Cache-Control", "max-stale=5"), header("Cache-Control", "max-age=60", "Age", "70"), false},
+		{"max-stale with must-revalidate", header("Cache-Control", "max-stale"), header("Cache-Control", "max-age=60, must-revalidate", "Age", "70"), false},
+		{"max-stale with proxy-revalidate", header("Cache-Control", "max-stale"), header("Cache-Control", "max-age=60, proxy-revalidate", "Age", "70"), false},
+	}
+	for _, tc := range tests {
+		req := httptest.NewRequest(http.MethodGet, "http://example.com/a", nil)
+		req.Header = tc.reqHdr
+		if got := CanServeStored(req, tc.respHdr); got != tc.want {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestServeStale(t *testing.T) {
+	tests := []struct {
+		name      string
+		reqHdr    http.Header
+		respHdr   http.Header
+		def       time.Duration
+		revalWant bool
+		errWant   bool
+	}{
+		{"directives", header(), header("Cache-Control", "max-age=60, stale-while-revalidate=30, stale-if-error=300", "Age", "80"), 0, true, true},
+		{"beyond windows", header(), header("Cache-Control", "max-age=60, stale-while-revalidate=10, stale-if-error=10", "Age", "80"), 0, false, false},
+		{"defaults", header(), header("Cache-Control", "max-age=60", "Age", "80"), 30 * time.Second, true, true},
+		{"no defaults", header(), header("Cache-Control", "max-age=60", "Age", "80"), 0, false, false},
+		{"must-revalidate", header(), header("Cache-Control", "max-age=60, must-revalidate", "Age", "80"), time.Hour, false, false},
+		{"no-cache request", header("Cache-Control", "no-cache"), header("Cache-Control", "max-age=60", "Age", "80"), time.Hour, false, true},
+		{"request stale-if-error", header("Cache-Control", "stale-if-error=60"), header("Cache-Control", "max-age=60", "Age", "80"), 0, false, true},
+	}
+	for _, tc := range tests {
+		req := httptest.NewRequest(http.MethodGet, "http://example.com/a", nil)
+		req.Header = tc.reqHdr
+		if got := CanServeWhileRevalidate(req, tc.respHdr, tc.def); got != tc.revalWant {
+			t.Errorf("%s: CanServeWhileRevalidate expected %v, got %v", tc.name, tc.revalWant, got)
+		}
+		if got := CanServeOnError(req, tc.respHdr, tc.def); got != tc.errWant {
+			t.Errorf("%s: CanServeOnError expected %v, got %v", tc.name, tc.errWant, got)
+		}
+	}
+}
+
+func TestVariantKey(t *testing.T) {
+	vary := VaryHeaders(header("Vary", "accept-encoding, Accept-Language", "Vary", "Accept-Encoding"))
+	if len(vary) != 2 || vary[0] != "Accept-Encoding" || vary[1] != "Accept-Language" {
+		t.Fatalf("unexpected Vary headers %v", vary)
+	}
+	a := VariantKey(vary, header("Accept-Encoding", "gzip, br", "Accept-Language", "en"))
+	b := VariantKey(vary, header("Accept-Encoding", "BR,gzip", "Accept-Language", "en"))
+	c := VariantKey(vary, header("Accept-Encoding", "gzip", "Accept-Language", "en"))
+	if a != b || a == c {
+		t.Errorf("unexpected variant keys %s %s %s", a, b, c)
+	}
+	if VariantKey(nil, header()) != "" {
+		t.Errorf("expected no variant key without Vary")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/a", nil)
+	if IsStorable(req, &http.Response{StatusCode: 200, Header: header("Vary", "*")}) {
+		t.Errorf("Vary: * must not be stored")
+	}
+}
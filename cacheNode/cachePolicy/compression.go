@@ -0,0 +1,208 @@
+package cachePolicy
+
+import (
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hcl/cdn/common/config"
+)
+
+// Content codings the cache node decodes and encodes
+const (
+	EncodingIdentity = "identity"
+	EncodingGzip     = "gzip"
+	EncodingBrotli   = "br"
+)
+
+// content types compressed when the DS does not list its own
+var defaultCompressibleTypes = []string{
+	"text/*",
+	"application/javascript",
+	"application/x-javascript",
+	"application/json",
+	"application/xml",
+	"application/xhtml+xml",
+	"application/rss+xml",
+	"image/svg+xml",
+}
+
+// ContentCoding returns the content coding of the representation, "identity" when it is not encoded
+func ContentCoding(hdr http.Header) string {
+	coding := strings.ToLower(strings.TrimSpace(hdr.Get("Content-Encoding")))
+	if coding == "" {
+		return EncodingIdentity
+	}
+	return coding
+}
+
+// acceptedCodings returns the q values of the Accept-Encoding members, nil without Accept-Encoding
+func acceptedCodings(reqHdr http.Header) map[string]float64 {
+	values := reqHdr.Values("Accept-Encoding")
+	if len(values) == 0 {
+		return nil
+	}
+	codings := make(map[string]float64)
+	for _, value := range values {
+		for _, member := range strings.Split(value, ",") {
+			coding, params, _ := strings.Cut(member, ";")
+			coding = strings.ToLower(strings.TrimSpace(coding))
+			if coding == "" {
+				continue
+			}
+			q := 1.0
+			if name, v, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(name) == "q" {
+				if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+					q = f
+				}
+			}
+			codings[coding] = q
+		}
+	}
+	return codings
+}
+
+// AcceptsEncoding reports whether the client accepts the content coding (RFC 9110 section 12.5.3).
+// Clients without Accept-Encoding are given identity only.
+func AcceptsEncoding(reqHdr http.Header, coding string) bool {
+	codings := acceptedCodings(reqHdr)
+	if q, ok := codings[coding]; ok {
+		return q > 0
+	}
+	if coding == EncodingIdentity {
+		// identity is acceptable unless excluded explicitly or by "*;q=0"
+		q, ok := codings["*"]
+		return !ok || q > 0
+	}
+	q, ok := codings["*"]
+	return ok && q > 0
+}
+
+// codingRank orders codings of the same q value, brotli compresses best
+func codingRank(coding string) int {
+	switch coding {
+	case EncodingBrotli:
+		return 0
+	case EncodingGzip:
+		return 1
+	case EncodingIdentity:
+		return 3
+	}
+	return 2
+}
+
+// PreferredEncoding returns the coding the cache node compresses with for the client, br or gzip,
+// "" when the client accepts neither
+func PreferredEncoding(reqHdr http.Header) string {
+	codings := acceptedCodings(reqHdr)
+	preferred, preferredQ := "", 0.0
+	for _, coding := range []string{EncodingBrotli, EncodingGzip} {
+		q, ok := codings[coding]
+		if !ok {
+			q = codings["*"]
+		}
+		if q > preferredQ {
+			preferred, preferredQ = coding, q
+		}
+	}
+	return preferred
+}
+
+// EncodingPreference lists the stored representations serving the request in order of preference.
+// gzip & br come last when the client does not accept them, the frontend decodes them for it.
+func EncodingPreference(reqHdr http.Header) []string {
+	codings := acceptedCodings(reqHdr)
+	var accepted []string
+	for coding, q := range codings {
+		if q > 0 && coding != "*" && coding != EncodingIdentity {
+			accepted = append(accepted, coding)
+		}
+	}
+	sort.Slice(accepted, func(i, j int) bool {
+		qi, qj := codings[accepted[i]], codings[accepted[j]]
+		if qi != qj {
+			return qi > qj
+		}
+		if codingRank(accepted[i]) != codingRank(accepted[j]) {
+			return codingRank(accepted[i]) < codingRank(accepted[j])
+		}
+		return accepted[i] < accepted[j]
+	})
+
+	preference := accepted
+	seen := make(map[string]bool)
+	for _, coding := range accepted {
+		seen[coding] = true
+	}
+	for _, coding := range []string{EncodingIdentity, EncodingGzip, EncodingBrotli} {
+		if !seen[coding] {
+			preference = append(preference, coding)
+		}
+	}
+	return preference
+}
+
+// Compressible decides whether the cache node compresses the response under the DS policy
+func Compressible(policy *config.Compression, hdr http.Header) bool {
+	if policy == nil || !policy.Enabled {
+		return false
+	}
+	if ContentCoding(hdr) != EncodingIdentity || ParseCacheControl(hdr).Has("no-transform") {
+		return false
+	}
+	if v := hdr.Get("Content-Length"); v != "" {
+		if length, err := strconv.Atoi(v); err == nil && length < policy.MinSize {
+			return false
+		}
+	}
+	mediaType, _, err := mime.ParseMediaType(hdr.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	types := policy.MimeTypes
+	if len(types) == 0 {
+		types = defaultCompressibleTypes
+	}
+	for _, t := range types {
+		t = strings.ToLower(t)
+		if prefix, ok := strings.CutSuffix(t, "*"); ok {
+			if strings.HasPrefix(mediaType, prefix) {
+				return true
+			}
+		} else if mediaType == t {
+			return true
+		}
+	}
+	return false
+}
+
+// AddVary lists the request header in Vary unless it is already listed
+func AddVary(hdr http.Header, name string) {
+	for _, listed := range VaryHeaders(hdr) {
+		if listed == "*" || listed == http.CanonicalHeaderKey(name) {
+			return
+		}
+	}
+	hdr.Add("Vary", name)
+}
+
+// RemoveVary drops the request header from Vary, Vary is removed when nothing is left
+func RemoveVary(hdr http.Header, name string) {
+	names := VaryHeaders(hdr)
+	kept := names[:0]
+	for _, listed := range names {
+		if listed != http.CanonicalHeaderKey(name) {
+			kept = append(kept, listed)
+		}
+	}
+	if len(kept) == len(names) {
+		return
+	}
+	if len(kept) == 0 {
+		hdr.Del("Vary")
+		return
+	}
+	hdr.Set("Vary", strings.Join(kept, ", "))
+}
@@ -0,0 +1,92 @@
+package cachePolicy
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/hcl/cdn/common/config"
+)
+
+func TestEncodingNegotiation(t *testing.T) {
+	cases := []struct {
+		name       string
+		accept     []string
+		preferred  string
+		preference []string
+		identity   bool
+	}{
+		{"no accept-encoding", nil, "", []string{"identity", "gzip", "br"}, true},
+		{"gzip only", []string{"gzip"}, "gzip", []string{"gzip", "identity", "br"}, true},
+		{"br preferred on tie", []string{"gzip, deflate, br"}, "br", []string{"br", "gzip", "deflate", "identity"}, true},
+		{"q values", []string{"br;q=0.5, gzip;q=0.8"}, "gzip", []string{"gzip", "br", "identity"}, true},
+		{"br refused", []string{"gzip, br;q=0"}, "gzip", []string{"gzip", "identity", "br"}, true},
+		{"wildcard", []string{"*"}, "br", []string{"identity", "gzip", "br"}, true},
+		{"identity refused", []string{"gzip, identity;q=0"}, "gzip", []string{"gzip", "identity", "br"}, false},
+	}
+	for _, c := range cases {
+		hdr := http.Header{}
+		for _, v := range c.accept {
+			hdr.Add("Accept-Encoding", v)
+		}
+		if got := PreferredEncoding(hdr); got != c.preferred {
+			t.Errorf("%s: PreferredEncoding = %q, want %q", c.name, got, c.preferred)
+		}
+		if got := EncodingPreference(hdr); !reflect.DeepEqual(got, c.preference) {
+			t.Errorf("%s: EncodingPreference = %v, want %v", c.name, got, c.preference)
+		}
+		if got := AcceptsEncoding(hdr, EncodingIdentity); got != c.identity {
+			t.Errorf("%s: AcceptsEncoding(identity) = %v, want %v", c.name, got, c.identity)
+		}
+	}
+}
+
+func TestCompressible(t *testing.T) {
+	policy := &config.Compression{Enabled: true, MinSize: 100}
+	cases := []struct {
+		name   string
+		policy *config.Compression
+		hdr    []string
+		want   bool
+	}{
+		{"disabled", &config.Compression{}, []string{"Content-Type", "text/html"}, false},
+		{"default types", policy, []string{"Content-Type", "text/html; charset=utf-8"}, true},
+		{"not in default types", policy, []string{"Content-Type", "image/png"}, false},
+		{"too small", policy, []string{"Content-Type", "text/css", "Content-Length", "99"}, false},
+		{"large enough", policy, []string{"Content-Type", "text/css", "Content-Length", "100"}, true},
+		{"already encoded", policy, []string{"Content-Type", "text/css", "Content-Encoding", "gzip"}, false},
+		{"no-transform", policy, []string{"Content-Type", "text/css", "Cache-Control", "max-age=60, no-transform"}, false},
+		{"allow list", &config.Compression{Enabled: true, MimeTypes: []string{"application/*"}},
+			[]string{"Content-Type", "application/wasm"}, true},
+		{"allow list excludes", &config.Compression{Enabled: true, MimeTypes: []string{"application/*"}},
+			[]string{"Content-Type", "text/html"}, false},
+	}
+	for _, c := range cases {
+		hdr := http.Header{}
+		for i := 0; i < len(c.hdr); i += 2 {
+			hdr.Set(c.hdr[i], c.hdr[i+1])
+		}
+		if got := Compressible(c.policy, hdr); got != c.want {
+			t.Errorf("%s: Compressible = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestAddVary(t *testing.T) {
+	hdr := http.Header{}
+	hdr.Set("Vary", "Origin")
+	AddVary(hdr, "accept-encoding")
+	AddVary(hdr, "Accept-Encoding")
+	if got := VaryHeaders(hdr); !reflect.DeepEqual(got, []string{"Accept-Encoding", "Origin"}) {
+		t.Errorf("AddVary: Vary = %v", got)
+	}
+
+	RemoveVary(hdr, "origin")
+	if got := hdr.Values("Vary"); !reflect.DeepEqual(got, []string{"Accept-Encoding"}) {
+		t.Errorf("RemoveVary: Vary = %v", got)
+	}
+	RemoveVary(hdr, "Accept-Encoding")
+	if _, ok := hdr["Vary"]; ok {
+		t.Errorf("RemoveVary: expected Vary to be removed, got %v", hdr["Vary"])
+	}
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"context"
 	"net/http"
+	"slices"
 	"sync"
 	"log/slog"
 	"github.com/hcl/cdn/cacheNode/common"
@@ -90,7 +91,8 @@ func (cr *ConfigReconciler) UpdateCacheNode(cacheNode *config.CacheNode) {
 
 	oldCacheNode := cr.activeConfig.Node
 	if oldCacheNode != nil && oldCacheNode.IP == cacheNode.IP {
-		if oldCacheNode.Port != cacheNode.Port || oldCacheNode.ParentIP != cacheNode.ParentIP || oldCacheNode.ParentPort != cacheNode.ParentPort {
+		if oldCacheNode.Port != cacheNode.Port || oldCacheNode.ParentIP != cacheNode.ParentIP || oldCacheNode.ParentPort != cacheNode.ParentPort ||
+			!slices.Equal(oldCacheNode.Parents, cacheNode.Parents) {
 			// Handle cache node update logic, restarting services
 			// Issue DELETE or UPDATE requests to storage if required
 			req, _ := http.NewRequest(http.MethodDelete, "http://"+oldCacheNode.IP, nil)
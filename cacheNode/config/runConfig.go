@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
 	"strings"
 	"sync"
@@ -16,6 +17,9 @@ import (
 	"github.com/hcl/cdn/common/helper"
 )
 
+// DefaultCollapseTimeout is the wait of a collapsed request when the node does not set one
+const DefaultCollapseTimeout = 30 * time.Second
+
 // configuration
 type RunConfig struct {
 	Valid       bool                     `json:"-"`           // is config Valid?
@@ -171,6 +175,57 @@ func (c *RunConfig) BindingPort() int {
 	return 0
 }
 
+func (c *RunConfig) BindingTLSPort() int {
+	if c.Node != nil {
+		return c.Node.TLSPort
+	}
+	return 0
+}
+
+func (c *RunConfig) H2C() bool {
+	if c.Node != nil {
+		return c.Node.H2C
+	}
+	return false
+}
+
+func (c *RunConfig) ParentH2C() bool {
+	if c.Node != nil {
+		return c.Node.ParentH2C
+	}
+	return false
+}
+
+func (c *RunConfig) MaxConcurrentStreams() int {
+	if c.Node != nil {
+		return c.Node.MaxConcurrentStreams
+	}
+	return 0
+}
+
+// CollapseTimeout is how long a collapsed request waits for the fetch of another request
+// before fetching on its own
+func (c *RunConfig) CollapseTimeout() time.Duration {
+	if c.Node != nil && c.Node.CollapseTimeout > 0 {
+		return time.Duration(c.Node.CollapseTimeout) * time.Second
+	}
+	return DefaultCollapseTimeout
+}
+
+func (c *RunConfig) NodeType() string {
+	if c.Node != nil {
+		return c.Node.Type
+	}
+	return ""
+}
+
+func (c *RunConfig) NodeName() string {
+	if c.Node != nil {
+		return c.Node.Name
+	}
+	return ""
+}
+
 func (c *RunConfig) ParentIp() string {
 	if c.Node != nil {
 		return c.Node.ParentIP
@@ -185,6 +240,29 @@ func (c *RunConfig) ParentPort() int {
 	return 0
 }
 
+// Parents returns the upstream caches of the node, the ParentIP/ParentPort of the node when it does not list
+// them, none when the node fetches from the origins
+func (c *RunConfig) Parents() []config.Parent {
+	if c.Node == nil {
+		return nil
+	}
+	if len(c.Node.Parents) > 0 {
+		return c.Node.Parents
+	}
+	if c.Node.ParentIP != "" {
+		return []config.Parent{{IP: c.Node.ParentIP, Port: c.Node.ParentPort}}
+	}
+	return nil
+}
+
+// OriginFallback reports if the node fetches from the origin when all its parents are down
+func (c *RunConfig) OriginFallback() bool {
+	if c.Node != nil {
+		return c.Node.OriginFallback
+	}
+	return false
+}
+
 func (c *RunConfig) DSLookup(req *http.Request) (*config.DeliveryService, error) {
 	urlStr := helper.GetString(req)
 	slog.Info(fmt.Sprintf("Searching for client URL: %s", urlStr))
@@ -207,6 +285,36 @@ func (c *RunConfig) DSLookup(req *http.Request) (*config.DeliveryService, error)
 	return nil, errors.New("not Found")
 }
 
+// DSLookupHost looks for the Delivery Service of the hostname, e.g. the TLS server name of the client
+func (c *RunConfig) DSLookupHost(host string) (*config.DeliveryService, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.ServiceList == nil {
+		return nil, errors.New("not Found")
+	}
+	for _, ds := range c.ServiceList.ServiceList {
+		clientURL, err := url.Parse(ds.ClientURL)
+		if err != nil {
+			continue
+		}
+		if strings.EqualFold(clientURL.Hostname(), host) {
+			return &ds, nil
+		}
+	}
+	return nil, errors.New("not Found")
+}
+
+// DSList returns a copy of the Delivery Services
+func (c *RunConfig) DSList() []config.DeliveryService {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.ServiceList == nil {
+		return nil
+	}
+	return append([]config.DeliveryService(nil), c.ServiceList.ServiceList...)
+}
 
 // func (c *RunConfig) DSLookup(url url.URL) (*config.DeliveryService, error) {
 // 	urlStr := fmt.Sprintf("%s://%s", url.Scheme, url.Host)
@@ -0,0 +1,120 @@
+package frontend
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	coCfg "github.com/hcl/cdn/common/config"
+	"github.com/hcl/cdn/common/helper"
+)
+
+// Reasons of the denied requests in the frontend metrics
+const (
+	DenyReasonIP      = "acl_ip"
+	DenyReasonCountry = "acl_country"
+	DenyReasonToken   = "token"
+	DenyReasonCORS    = "cors"
+)
+
+func matchesCIDR(addr netip.Addr, cidrs []string) bool {
+	for _, cidr := range cidrs {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			slog.Error("FE accessControl.go : Invalid CIDR", "cidr", cidr)
+			continue
+		}
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesCountry(country string, countries []string) bool {
+	if country == "" {
+		return false
+	}
+	for _, c := range countries {
+		if strings.EqualFold(c, country) {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluateACL returns the reason the client is denied by the lists, "" if it is allowed.
+// Deny lists win. With allow lists the client must be in an allowed network or country,
+// clients of unknown country only pass by their network.
+func evaluateACL(acl *coCfg.AccessControl, addr netip.Addr, country string) string {
+	if matchesCIDR(addr, acl.DenyCIDRs) {
+		return DenyReasonIP
+	}
+	if matchesCountry(country, acl.DenyCountries) {
+		return DenyReasonCountry
+	}
+	if len(acl.AllowCIDRs) == 0 && len(acl.AllowCountries) == 0 {
+		return ""
+	}
+	if matchesCIDR(addr, acl.AllowCIDRs) || matchesCountry(country, acl.AllowCountries) {
+		return ""
+	}
+	if len(acl.AllowCountries) > 0 {
+		return DenyReasonCountry
+	}
+	return DenyReasonIP
+}
+
+// forbidden returns the 403 response for a denied client
+func forbidden() *http.Response {
+	body := "403 : Forbidden"
+	resp := &http.Response{
+		StatusCode:    http.StatusForbidden,
+		Status:        fmt.Sprintf("%d %s", http.StatusForbidden, http.StatusText(http.StatusForbidden)),
+		Header:        make(http.Header),
+		ContentLength: int64(len(body)),
+		Body:          io.NopCloser(strings.NewReader(body)),
+	}
+	resp.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	resp.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	resp.Header.Set("Cache-Control", "no-store")
+	return resp
+}
+
+// checkAccess applies the access control lists of the DS before the cache lookup,
+// it returns the 403 response and the reason for a denied client, nil when the request is served.
+// Mid nodes are reached by the edges, the edge checked the client.
+func (l *Listener) checkAccess(req *http.Request) (*http.Response, string) {
+	if l.cfg.NodeType() == coCfg.CacheNodeMid {
+		return nil, ""
+	}
+	configDS, err := l.cfg.DSLookup(req)
+	if err != nil {
+		return nil, ""
+	}
+	acl := &configDS.ACL
+	if len(acl.AllowCIDRs) == 0 && len(acl.DenyCIDRs) == 0 && len(acl.AllowCountries) == 0 && len(acl.DenyCountries) == 0 {
+		return nil, ""
+	}
+	addr, err := netip.ParseAddr(clientIP(req))
+	if err != nil {
+		slog.Error("FE accessControl.go : Invalid client IP", "client", req.RemoteAddr)
+		return forbidden(), DenyReasonIP
+	}
+	addr = addr.Unmap().WithZone("")
+
+	country := ""
+	if len(acl.AllowCountries) > 0 || len(acl.DenyCountries) > 0 {
+		country = l.geo.Country(addr)
+	}
+	if reason := evaluateACL(acl, addr, country); reason != "" {
+		slog.Info("FE accessControl.go : Request denied", "url", helper.GetString(req), "client", addr.String(), "country", country, "reason", reason)
+		return forbidden(), reason
+	}
+	return nil, ""
+}
@@ -0,0 +1,133 @@
+package frontend
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hcl/cdn/cacheNode/config"
+	coCfg "github.com/hcl/cdn/common/config"
+)
+
+func TestGeoIPDB(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	file := filepath.Join(t.TempDir(), "geoip.csv")
+	data := "start_ip,end_ip,country\n# comment\n1.0.0.0,1.0.0.255,au\n2.0.0.0,2.0.255.255,FR\n2001:db8::,2001:db8::ffff,DE\nbad,line\n"
+	if err := os.WriteFile(file, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	db := NewGeoIPDB(ctx, &wg, file)
+
+	cases := map[string]string{
+		"1.0.0.1":         "AU",
+		"1.0.1.0":         "",
+		"2.0.128.1":       "FR",
+		"::ffff:2.0.0.1":  "FR",
+		"2001:db8::1":     "DE",
+		"2001:db8::1:0":   "",
+		"0.0.0.1":         "",
+		"255.255.255.255": "",
+	}
+	for ip, want := range cases {
+		if got := db.Country(netip.MustParseAddr(ip)); got != want {
+			t.Errorf("Country(%s) = %q, want %q", ip, got, want)
+		}
+	}
+
+	// Hot reload of the changed file
+	if err := os.WriteFile(file, []byte("1.0.0.0,1.0.0.255,NZ\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(file, later, later)
+	db.reload()
+	if got := db.Country(netip.MustParseAddr("1.0.0.1")); got != "NZ" {
+		t.Errorf("expected reloaded country NZ, got %q", got)
+	}
+}
+
+func TestCheckAccess(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "geoip.csv")
+	os.WriteFile(file, []byte("1.0.0.0,1.0.0.255,IN\n2.0.0.0,2.0.0.255,CN\n3.0.0.0,3.0.0.255,US\n"), 0644)
+	var wg sync.WaitGroup
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	newListener := func(acl coCfg.AccessControl) *Listener {
+		return &Listener{
+			cfg: &config.RunConfig{ServiceList: &coCfg.DeliveryServices{ServiceList: []coCfg.DeliveryService{
+				{Name: "abc", ClientURL: "http://abc.com", OriginURL: "http://origin.abc.com", ACL: acl},
+			}}},
+			geo: NewGeoIPDB(ctx, &wg, file),
+		}
+	}
+	check := func(l *Listener, ip string) string {
+		req := httptest.NewRequest(http.MethodGet, "http://abc.com/a.mp4", nil)
+		req.RemoteAddr = ip + ":40000"
+		resp, reason := l.checkAccess(req)
+		if (resp == nil) != (reason == "") {
+			t.Fatalf("response and reason disagree for %s", ip)
+		}
+		if resp != nil && resp.StatusCode != http.StatusForbidden {
+			t.Errorf("expected 403, got %d", resp.StatusCode)
+		}
+		return reason
+	}
+
+	// licensed in India & US, one network of India denied
+	l := newListener(coCfg.AccessControl{AllowCountries: []string{"in", "US"}, DenyCIDRs: []string{"1.0.0.128/25"}})
+	for ip, want := range map[string]string{
+		"1.0.0.1":   "",
+		"1.0.0.200": DenyReasonIP,
+		"3.0.0.1":   "",
+		"2.0.0.1":   DenyReasonCountry,
+		"9.9.9.9":   DenyReasonCountry,
+	} {
+		if got := check(l, ip); got != want {
+			t.Errorf("%s: got %q, want %q", ip, got, want)
+		}
+	}
+
+	// partner networks only
+	l = newListener(coCfg.AccessControl{AllowCIDRs: []string{"10.0.0.0/8", "2001:db8::/32"}})
+	for ip, want := range map[string]string{
+		"10.1.2.3":      "",
+		"[2001:db8::1]": "",
+		"1.0.0.1":       DenyReasonIP,
+	} {
+		if got := check(l, ip); got != want {
+			t.Errorf("%s: got %q, want %q", ip, got, want)
+		}
+	}
+
+	// country deny list
+	l = newListener(coCfg.AccessControl{DenyCountries: []string{"CN"}})
+	if got := check(l, "2.0.0.1"); got != DenyReasonCountry {
+		t.Errorf("expected CN to be denied, got %q", got)
+	}
+	if got := check(l, "9.9.9.9"); got != "" {
+		t.Errorf("expected unknown country to pass a deny list, got %q", got)
+	}
+
+	// Mid nodes are reached by the edges
+	l = newListener(coCfg.AccessControl{AllowCIDRs: []string{"10.0.0.0/8"}})
+	l.cfg.Node = &coCfg.CacheNode{Type: coCfg.CacheNodeMid}
+	if got := check(l, "192.168.1.1"); got != "" {
+		t.Errorf("expected mid node not to check the clients, got %q", got)
+	}
+}
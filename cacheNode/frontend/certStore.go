@@ -0,0 +1,74 @@
+package frontend
+
+import (
+	"crypto/tls"
+	"errors"
+	"log/slog"
+	"sync"
+
+	"github.com/hcl/cdn/cacheNode/config"
+)
+
+// cachedCert is the parsed certificate of a DS along with the PEM it was parsed from
+type cachedCert struct {
+	certPEM string
+	keyPEM  string
+	cert    *tls.Certificate
+}
+
+// CertStore selects the certificate of the DS by the SNI server name of the client.
+// Certificates come with the DS list (configServer, UpdateDsList) and are parsed again
+// whenever their PEM changes, so a rotated certificate is served from the next handshake on.
+type CertStore struct {
+	cfg   *config.RunConfig
+	mu    sync.Mutex
+	certs map[string]*cachedCert // DS name to certificate
+}
+
+// NewCertStore initializes a new CertStore
+func NewCertStore(cfg *config.RunConfig) *CertStore {
+	return &CertStore{
+		cfg:   cfg,
+		certs: make(map[string]*cachedCert),
+	}
+}
+
+// GetCertificate is the tls.Config.GetCertificate callback of the HTTPS listener
+func (s *CertStore) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	if hello.ServerName == "" {
+		slog.Error("FE certStore.go : Client did not send a server name (SNI)")
+		return nil, errors.New("no server name")
+	}
+	ds, err := s.cfg.DSLookupHost(hello.ServerName)
+	if err != nil {
+		slog.Error("FE certStore.go : No DS Config found for server name", "serverName", hello.ServerName)
+		return nil, err
+	}
+	if ds.TLS.Certificate == "" || ds.TLS.PrivateKey == "" {
+		slog.Error("FE certStore.go : DS has no certificate", "ds", ds.Name)
+		return nil, errors.New("no certificate for " + hello.ServerName)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cached, ok := s.certs[ds.Name]
+	if ok && cached.certPEM == ds.TLS.Certificate && cached.keyPEM == ds.TLS.PrivateKey {
+		return cached.cert, nil
+	}
+	cert, err := tls.X509KeyPair([]byte(ds.TLS.Certificate), []byte(ds.TLS.PrivateKey))
+	if err != nil {
+		slog.Error("FE certStore.go : Invalid certificate", "ds", ds.Name, "error", err.Error())
+		if ok {
+			// a bad rotation does not take the DS down, the previous certificate is served until it is fixed
+			return cached.cert, nil
+		}
+		return nil, err
+	}
+	slog.Info("FE certStore.go : Certificate loaded", "ds", ds.Name)
+	s.certs[ds.Name] = &cachedCert{
+		certPEM: ds.TLS.Certificate,
+		keyPEM:  ds.TLS.PrivateKey,
+		cert:    &cert,
+	}
+	return &cert, nil
+}
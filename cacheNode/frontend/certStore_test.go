@@ -0,0 +1,118 @@
+package frontend
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hcl/cdn/cacheNode/config"
+	coCfg "github.com/hcl/cdn/common/config"
+)
+
+// selfSignedPEM returns a PEM certificate & key for the host
+func selfSignedPEM(t *testing.T, host string, serial int64) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: host},
+		DNSNames:     []string{host},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+	return string(certPEM), string(keyPEM)
+}
+
+func newTLSConfig(tlsCfg coCfg.TLSConfig) *config.RunConfig {
+	return &config.RunConfig{
+		Node: &coCfg.CacheNode{Port: 8080, TLSPort: 8443},
+		ServiceList: &coCfg.DeliveryServices{
+			ServiceList: []coCfg.DeliveryService{
+				{Name: "abc", ClientURL: "http://abc.com", OriginURL: "http://origin.abc.com", TLS: tlsCfg},
+			},
+		},
+	}
+}
+
+func TestCertStore(t *testing.T) {
+	certPEM, keyPEM := selfSignedPEM(t, "abc.com", 1)
+	cfg := newTLSConfig(coCfg.TLSConfig{Certificate: certPEM, PrivateKey: keyPEM})
+	store := NewCertStore(cfg)
+
+	cert, err := store.GetCertificate(&tls.ClientHelloInfo{ServerName: "ABC.com"})
+	if err != nil || cert == nil {
+		t.Fatalf("expected certificate for abc.com, got %v", err)
+	}
+	if _, err = store.GetCertificate(&tls.ClientHelloInfo{ServerName: "xyz.com"}); err == nil {
+		t.Errorf("expected no certificate for an unknown server name")
+	}
+
+	// Rotation: the new certificate is served without restart
+	certPEM, keyPEM = selfSignedPEM(t, "abc.com", 2)
+	cfg.UpdateConfig(nil, newTLSConfig(coCfg.TLSConfig{Certificate: certPEM, PrivateKey: keyPEM}).ServiceList)
+	rotated, err := store.GetCertificate(&tls.ClientHelloInfo{ServerName: "abc.com"})
+	if err != nil {
+		t.Fatalf("expected rotated certificate, got %v", err)
+	}
+	leaf, _ := x509.ParseCertificate(rotated.Certificate[0])
+	if leaf.SerialNumber.Int64() != 2 {
+		t.Errorf("expected rotated certificate, got serial %v", leaf.SerialNumber)
+	}
+
+	// A certificate which does not parse keeps the previous one served
+	cfg.UpdateConfig(nil, newTLSConfig(coCfg.TLSConfig{Certificate: "bad", PrivateKey: keyPEM}).ServiceList)
+	kept, err := store.GetCertificate(&tls.ClientHelloInfo{ServerName: "abc.com"})
+	if err != nil || kept != rotated {
+		t.Errorf("expected the previous certificate kept, got %v", err)
+	}
+}
+
+func TestHttpsRedirect(t *testing.T) {
+	l := &Listener{cfg: newTLSConfig(coCfg.TLSConfig{RedirectToHTTPS: true})}
+
+	req := httptest.NewRequest(http.MethodGet, "http://abc.com:8080/a/b.js?v=1", nil)
+	resp := l.httpsRedirect(req)
+	if resp == nil || resp.StatusCode != http.StatusMovedPermanently {
+		t.Fatalf("expected a redirect, got %v", resp)
+	}
+	if location := resp.Header.Get("Location"); location != "https://abc.com:8443/a/b.js?v=1" {
+		t.Errorf("unexpected Location %s", location)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "http://abc.com/a", nil)
+	if resp = l.httpsRedirect(req); resp == nil || resp.StatusCode != http.StatusPermanentRedirect {
+		t.Errorf("expected 308 for POST, got %v", resp)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "https://abc.com/a", nil)
+	if resp = l.httpsRedirect(req); resp != nil {
+		t.Errorf("expected no redirect over HTTPS, got %d", resp.StatusCode)
+	}
+
+	l = &Listener{cfg: newTLSConfig(coCfg.TLSConfig{})}
+	req = httptest.NewRequest(http.MethodGet, "http://abc.com/a", nil)
+	if resp = l.httpsRedirect(req); resp != nil {
+		t.Errorf("expected no redirect without RedirectToHTTPS, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,38 @@
+package frontend
+
+import (
+	"net/http"
+
+	"github.com/hcl/cdn/cacheNode/headerRewrite"
+	coCfg "github.com/hcl/cdn/common/config"
+)
+
+// ensureRequestID gives the client request an id for ${request_id}, requests from the edge keep theirs
+func ensureRequestID(req *http.Request) {
+	if req.Header.Get(headerRewrite.RequestIDHeader) == "" {
+		req.Header.Set(headerRewrite.RequestIDHeader, headerRewrite.NewRequestID())
+	}
+}
+
+// rewriteClientHeaders applies the client phase rules of the DS to the response for the client.
+// The header of the shared response is copied, the Collapser hands it to all the waiting clients.
+func (l *Listener) rewriteClientHeaders(req *http.Request, resp *http.Response) *http.Response {
+	configDS, err := l.cfg.DSLookup(req)
+	if err != nil {
+		return resp
+	}
+	hasRules := false
+	for _, rule := range configDS.RewriteRules {
+		if rule.Phase == coCfg.HdrReWritePhaseClient {
+			hasRules = true
+			break
+		}
+	}
+	if !hasRules {
+		return resp
+	}
+	ret := *resp
+	ret.Header = resp.Header.Clone()
+	headerRewrite.Apply(configDS.RewriteRules, coCfg.HdrReWritePhaseClient, ret.Header, req, headerRewrite.NewVars(req, configDS.Name, l.cfg.NodeName()))
+	return &ret
+}
@@ -0,0 +1,64 @@
+package frontend
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hcl/cdn/cacheNode/config"
+	"github.com/hcl/cdn/cacheNode/headerRewrite"
+	coCfg "github.com/hcl/cdn/common/config"
+)
+
+func TestRewriteClientHeaders(t *testing.T) {
+	rules := []coCfg.RewriteRule{
+		{HeaderName: "X-Request-Id", Operation: coCfg.HdrReWriteOpAdd, Value: "${request_id}", Phase: coCfg.HdrReWritePhaseClient},
+		{HeaderName: "X-Client", Operation: coCfg.HdrReWriteOpAdd, Value: "${client_ip} ${ds_name} ${node_name}", Phase: coCfg.HdrReWritePhaseClient},
+		{HeaderName: "Cache-Control", Operation: coCfg.HdrReWriteOpOverwrite, Value: "private, max-age=60", Phase: coCfg.HdrReWritePhaseClient, IfHeader: "Authorization"},
+		{HeaderName: "X-Stored", Operation: coCfg.HdrReWriteOpAdd, Value: "1"},
+	}
+	l := &Listener{
+		cfg: &config.RunConfig{
+			Node: &coCfg.CacheNode{Name: "edge1"},
+			ServiceList: &coCfg.DeliveryServices{ServiceList: []coCfg.DeliveryService{
+				{Name: "abc", ClientURL: "http://abc.com", OriginURL: "http://origin.abc.com", RewriteRules: rules},
+			}},
+		},
+	}
+	shared := &http.Response{StatusCode: http.StatusOK, Header: make(http.Header)}
+	shared.Header.Set("Cache-Control", "public, max-age=3600")
+
+	req := httptest.NewRequest(http.MethodGet, "http://abc.com/a.js", nil)
+	req.RemoteAddr = "10.0.0.1:40000"
+	ensureRequestID(req)
+	id := req.Header.Get(headerRewrite.RequestIDHeader)
+	if id == "" {
+		t.Fatal("expected a request id")
+	}
+	ensureRequestID(req)
+	if req.Header.Get(headerRewrite.RequestIDHeader) != id {
+		t.Error("expected the request id to be kept")
+	}
+
+	resp := l.rewriteClientHeaders(req, shared)
+	if got := resp.Header.Get("X-Request-Id"); got != id {
+		t.Errorf("expected X-Request-Id %q, got %q", id, got)
+	}
+	if got := resp.Header.Get("X-Client"); got != "10.0.0.1 abc edge1" {
+		t.Errorf("unexpected X-Client %q", got)
+	}
+	if got := resp.Header.Get("Cache-Control"); got != "public, max-age=3600" {
+		t.Errorf("expected the condition to skip the rule, got %q", got)
+	}
+	if resp.Header.Get("X-Stored") != "" {
+		t.Error("response phase rules must not be applied by the frontend")
+	}
+	if len(shared.Header) != 1 {
+		t.Errorf("the shared response must not be changed, got %v", shared.Header)
+	}
+
+	req.Header.Set("Authorization", "Bearer x")
+	if got := l.rewriteClientHeaders(req, shared).Header.Get("Cache-Control"); got != "private, max-age=60" {
+		t.Errorf("expected the conditional rule to apply, got %q", got)
+	}
+}
@@ -2,41 +2,61 @@ package frontend
 
 import (
 	"io"
-	"time"
+	"log/slog"
 	"net/http"
-	"sync"
-	"log/slog" 
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/hcl/cdn/cacheNode/cachePolicy"
+	"github.com/hcl/cdn/cacheNode/config"
+	coCfg "github.com/hcl/cdn/common/config"
+	"github.com/hcl/cdn/common/helper"
 )
 
-// HttpResponse wraps an HTTP response and any associated error.
-type HttpResponse struct {
-	resp *http.Response
-	err  error
-}
+// Roles of a request in collapsing, reported in the frontend metrics
+const (
+	CollapseLeader   = "leader"   // fetched, the waiting requests share its response
+	CollapseWaiter   = "waiter"   // served by the fetch of another request
+	CollapsePromoted = "promoted" // waited, then fetched in place of a failed or cancelled leader
+	CollapseTimeout  = "timeout"  // waited longer than the collapse timeout, then fetched on its own
+	CollapseCanceled = "canceled" // the client left while waiting
+	CollapseVariant  = "variant"  // the response of the leader is another variant (Vary), fetched on its own
+)
+
+// fetches a promoted waiter retries after a failed fetch, a leader whose fetch its client aborted is always replaced
+const collapseRetries = 1
 
 // CollapseEntry represents a request entry used to collapse duplicate requests.
-// It uses a channel to signal when a response is ready and tracks pending requests.
+// The leader fetches, the waiting requests get its response once ready and each reads
+// the body from a shared body at its own pace. While the body is being filled from the origin
+// the entry stays in the map, requests arriving late join it instead of fetching again.
+// When the fetch of the leader fails, one waiter takes the lead and fetches again.
 type CollapseEntry struct {
-	responseReady chan HttpResponse // Channel to signal response readiness.
-	pendingCount  int               // Number of pending requests waiting for this response.
+	ready        chan struct{}      // closed once the response is ready
+	lead         chan struct{}      // holds a token while the lead is vacant, the waiter taking it fetches
+	resp         *http.Response     // response of the leader
+	header       http.Header        // header of the response as received, copied for each waiter
+	reqHeader    http.Header        // header of the request which fetched the response, selects its variant
+	body         *helper.SharedBody // body of the response, shared with the waiters
+	err          error              // error of the fetch
+	pendingCount int                // Number of pending requests waiting for this response.
+	retries      int                // failed fetches retried by a promoted waiter
+	removed      bool               // the entry left the map, no request joins anymore
 }
 
 // NewCollapseEntry initializes and returns a new CollapseEntry instance.
 func NewCollapseEntry() *CollapseEntry {
 	entry := &CollapseEntry{
-		responseReady: make(chan HttpResponse),
-		pendingCount:  0,
+		ready: make(chan struct{}),
+		lead:  make(chan struct{}, 1),
 	}
 	slog.Info("FE collapser.go : New CollapseEntry Instance Added")
 	return entry
 }
 
-// handleResponse processes the response for a collapsed request.
-// It ensures all waiting requests receive the same response.
-func (e *CollapseEntry) HandleResponse(resp *http.Response, err error) (*http.Response, error) {
-	slog.Info("FE collapser.go : handleResponse() - Start")
-	
+// collapseError is the response of the waiters of a failed fetch, each gets its own body
+func collapseError() *http.Response {
 	respErr := &http.Response{
 		Status:     "500 Internal Server Error",
 		StatusCode: http.StatusInternalServerError,
@@ -52,49 +72,7 @@ func (e *CollapseEntry) HandleResponse(resp *http.Response, err error) (*http.Re
 	respErr.Header.Set("User-Agent", "Go Server Agent/1.23.4")
 	respErr.Body = io.NopCloser(strings.NewReader(errStr))
 	respErr.ContentLength = int64(len(errStr))
-
-	if err != nil {
-		for i := e.pendingCount; i > 0; i-- {
-			e.responseReady <- HttpResponse{resp: respErr, err: nil}
-		}
-		slog.Info("FE collapser.go : handleResponse() - End with error")
-		return respErr, nil
-	}
-
-	respRdr := resp.Body
-	for i := e.pendingCount; i > 0; i-- {
-		rdr, wtr := io.Pipe()
-		go func() {
-			defer wtr.Close()
-			// Not handling the error for io.Copy() below
-			io.Copy(wtr, respRdr)
-		}()
-		e.responseReady <- HttpResponse{
-			resp: &http.Response{
-				Status:     resp.Status,
-				StatusCode: resp.StatusCode,
-				Header:     resp.Header,
-				Body:       rdr,
-			},
-			err: nil,
-		}
-		respRdr = io.NopCloser(rdr)
-	}
-	resp.Body = respRdr
-
-	slog.Info("FE collapser.go : handleResponse() - End")
-	return resp, nil
-}
-
-// waitForResponse waits for the response to be ready and returns it.
-// This method increments the pending count for this entry.
-func (e *CollapseEntry) WaitForResponse() (*http.Response, error) {
-	slog.Info("FE collapser.go : waitForResponse() - Start")
-	e.pendingCount++
-	// This will get blocked here until the response is receied via the responseReady channel
-	respObj := <-e.responseReady
-	slog.Info("FE collapser.go : Got the response & waitForResponse() - End")
-	return respObj.resp, respObj.err
+	return respErr
 }
 
 // Collapser prevents duplicate requests to the same URL.
@@ -102,7 +80,8 @@ func (e *CollapseEntry) WaitForResponse() (*http.Response, error) {
 type Collapser struct {
 	Next            *Finder    					// Downstream handler for executing requests.
 	pendingMapMutex sync.RWMutex    			// Mutex to synchronize access to the pendingReq map.           
-	pendingReq      map[string]*CollapseEntry 	// Map of URLs to their CollapseEntry.
+	pendingReq      map[string]*CollapseEntry 	// Map of cache keys to their CollapseEntry.
+	Config          *config.RunConfig			// per DS cache key policy & collapse timeout, optional
 }
 
 // NewCollapser initializes and returns a new Collapser instance.
@@ -116,39 +95,250 @@ func NewCollapser(next *Finder) *Collapser {
 	return c
 }
 
+// cacheKey returns the cache key of the request, requests for the same stored object are collapsed
+func (c *Collapser) cacheKey(r *http.Request) string {
+	var policy *coCfg.CacheKey
+	if c.Config != nil {
+		if ds, err := c.Config.DSLookup(r); err == nil {
+			policy = &ds.CacheKey
+		}
+	}
+	return cachePolicy.NewKey(r.URL, r.Host, r.Header, policy).String()
+}
+
+// timeout is how long a request waits for the fetch of another one
+func (c *Collapser) timeout() time.Duration {
+	if c.Config != nil {
+		return c.Config.CollapseTimeout()
+	}
+	return config.DefaultCollapseTimeout
+}
+
 // Do processes an HTTP request, collapsing duplicate requests to the same URL.
 func (c *Collapser) Do(r *http.Request) (*http.Response, error) {
+	resp, _, err := c.Fetch(r)
+	return resp, err
+}
+
+// Fetch processes an HTTP request like Do and reports the role of the request in collapsing,
+// "" when the request is not collapsed
+func (c *Collapser) Fetch(r *http.Request) (*http.Response, string, error) {
 	slog.Info("FE collapser.go : Do() - Start")
-	var entry *CollapseEntry
-	var reqUrlStr string
-	reqUrlStr = "http://" + r.Host + r.URL.Path
-	c.pendingMapMutex.RLock()
+	if !cachePolicy.Collapsible(r) {
+		slog.Info("FE collapser.go : Do() - Request not collapsible, invoking finder.Do()")
+		resp, err := c.Next.Do(r)
+		return resp, "", err
+	}
+	// HEAD responses have no body for the GET requests
+	reqUrlStr := r.Method + " " + c.cacheKey(r)
+	c.pendingMapMutex.Lock()
 	entry, ok := c.pendingReq[reqUrlStr]
-	c.pendingMapMutex.RUnlock()
+	if ok {
+		entry.pendingCount++
+	} else {
+		slog.Info("FE collapser.go : Do() - No existing entry found, creating new one")
+		entry = NewCollapseEntry()
+		c.pendingReq[reqUrlStr] = entry
+	}
+	c.pendingMapMutex.Unlock()
 
 	if !ok {
-		slog.Info("FE collapser.go : Do() - No existing entry found, creating new one")
-		c.pendingMapMutex.Lock()
-		entry, ok = c.pendingReq[reqUrlStr]
-		if !ok {
-			entry = NewCollapseEntry()
-			c.pendingReq[reqUrlStr] = entry
+		resp, err := c.lead(r, reqUrlStr, entry)
+		return resp, CollapseLeader, err
+	}
+	slog.Info("FE collapser.go : Do() - Waiting for response")
+	return c.waitForResponse(r, reqUrlStr, entry)
+}
+
+// fetchFailed reports if the fetch of the leader failed, a waiter may fetch again. A response in hand is
+// shared with the waiters even when the client of the leader left meanwhile.
+func fetchFailed(resp *http.Response, err error) bool {
+	return err != nil || resp.StatusCode >= http.StatusInternalServerError
+}
+
+// lead fetches for the entry and hands the response over to the waiting requests
+func (c *Collapser) lead(r *http.Request, key string, entry *CollapseEntry) (*http.Response, error) {
+	slog.Info("FE collapser.go : Do() - Invoking finder.Do() ")
+	resp, err := c.Next.Do(r)
+	// a fetch aborted by the client of the leader does not count as a retry
+	if fetchFailed(resp, err) && c.handOver(entry, err != nil && r.Context().Err() != nil) {
+		// the request answers its own client with its own result
+		slog.Info("FE collapser.go : Do() - Fetch failed, lead handed over to a waiting request")
+		return resp, err
+	}
+	slog.Info("FE collapser.go : Do() - End with response")
+	return c.handleResponse(key, entry, r.Header.Clone(), resp, err)
+}
+
+// handOver makes the lead vacant for a waiting request after a failed fetch, a failed fetch is retried
+// collapseRetries times. It reports false when nobody takes the lead.
+func (c *Collapser) handOver(entry *CollapseEntry, cancelled bool) bool {
+	c.pendingMapMutex.Lock()
+	defer c.pendingMapMutex.Unlock()
+	if entry.pendingCount == 0 {
+		return false
+	}
+	if !cancelled {
+		if entry.retries >= collapseRetries {
+			return false
 		}
+		entry.retries++
+	}
+	entry.lead <- struct{}{}
+	return true
+}
+
+// handleResponse hands the response of the fetch over to the waiting requests. A body being filled from
+// the origin is shared with the late requests until filled, other bodies only with the requests already waiting.
+func (c *Collapser) handleResponse(key string, entry *CollapseEntry, reqHeader http.Header, resp *http.Response, err error) (*http.Response, error) {
+	slog.Info("FE collapser.go : handleResponse() - Start")
+	if err != nil {
+		c.pendingMapMutex.Lock()
+		entry.err = err
+		close(entry.ready)
+		c.remove(key, entry)
 		c.pendingMapMutex.Unlock()
+		slog.Info("FE collapser.go : handleResponse() - End with error")
+		return collapseError(), nil
+	}
 
-		slog.Info("FE collapser.go : Do() - Invoking finder.Do() ")
-		//slog.Info("FE collapser.go : Sleeping for 5 seconds...")
-		time.Sleep(0*time.Second)
-		resp, err := c.Next.Do(r)
+	inProgress := helper.InProgress(resp.Body)
+	c.pendingMapMutex.Lock()
+	if !inProgress {
+		c.remove(key, entry)
+	}
+	share := resp.Body != nil && (inProgress || entry.pendingCount > 0)
+	c.pendingMapMutex.Unlock()
 
+	var body *helper.SharedBody
+	if share {
+		body = helper.Share(resp.Body)
+		resp.Body = body.NewReader()
+	}
+	c.pendingMapMutex.Lock()
+	entry.resp = resp
+	entry.header = resp.Header.Clone()
+	entry.reqHeader = reqHeader
+	entry.body = body
+	close(entry.ready)
+	// the waiters may have left meanwhile
+	c.release(entry)
+	c.pendingMapMutex.Unlock()
+
+	if inProgress {
+		go func() {
+			<-body.Done()
+			c.pendingMapMutex.Lock()
+			c.remove(key, entry)
+			c.pendingMapMutex.Unlock()
+		}()
+	}
+	slog.Info("FE collapser.go : handleResponse() - End")
+	return resp, nil
+}
+
+// waitForResponse waits for the response of the entry, the request gets a copy of it with its own reader of the body.
+// The wait ends with the client request or after the collapse timeout, the request then fetches on its own.
+// A request taking the vacant lead fetches for the entry.
+func (c *Collapser) waitForResponse(r *http.Request, key string, entry *CollapseEntry) (*http.Response, string, error) {
+	slog.Info("FE collapser.go : waitForResponse() - Start")
+	timer := time.NewTimer(c.timeout())
+	defer timer.Stop()
+
+	select {
+	case <-entry.ready:
+		if !sameVariant(entry, r) {
+			c.leave(key, entry)
+			slog.Info("FE collapser.go : waitForResponse() - Response of another variant, fetching alone")
+			resp, err := c.Next.Do(r)
+			return resp, CollapseVariant, err
+		}
+		slog.Info("FE collapser.go : Got the response & waitForResponse() - End")
+		return c.response(entry), CollapseWaiter, nil
+
+	case <-entry.lead:
 		c.pendingMapMutex.Lock()
-		delete(c.pendingReq, reqUrlStr)
+		entry.pendingCount--
 		c.pendingMapMutex.Unlock()
+		slog.Info("FE collapser.go : waitForResponse() - Taking the lead")
+		resp, err := c.lead(r, key, entry)
+		return resp, CollapsePromoted, err
+
+	case <-r.Context().Done():
+		c.leave(key, entry)
+		slog.Info("FE collapser.go : waitForResponse() - Client request cancelled", "error", r.Context().Err())
+		return nil, CollapseCanceled, r.Context().Err()
 
-		slog.Info("FE collapser.go : Do() - End with response")
-		return entry.HandleResponse(resp, err)
+	case <-timer.C:
+		c.leave(key, entry)
+		slog.Warn("FE collapser.go : waitForResponse() - Collapse timeout, fetching alone", "timeout", c.timeout())
+		resp, err := c.Next.Do(r)
+		return resp, CollapseTimeout, err
 	}
+}
 
-	slog.Info("FE collapser.go : Do() - Waiting for response")
-	return entry.WaitForResponse()
-}
\ No newline at end of file
+// sameVariant reports if the response of the entry is the variant the request selects by the headers named in Vary.
+// Any content coding serves, the frontend adapts it to the client. Responses varying on "*" serve no other request.
+// The entry is ready.
+func sameVariant(entry *CollapseEntry, r *http.Request) bool {
+	if entry.err != nil {
+		return true
+	}
+	var vary []string
+	for _, name := range cachePolicy.VaryHeaders(entry.header) {
+		if name == "*" {
+			return false
+		}
+		if name != "Accept-Encoding" {
+			vary = append(vary, name)
+		}
+	}
+	return cachePolicy.VariantKey(vary, r.Header) == cachePolicy.VariantKey(vary, entry.reqHeader)
+}
+
+// response is the copy of the response of the entry for a waiting request
+func (c *Collapser) response(entry *CollapseEntry) *http.Response {
+	c.pendingMapMutex.Lock()
+	defer c.pendingMapMutex.Unlock()
+	entry.pendingCount--
+	if entry.err != nil {
+		return collapseError()
+	}
+	resp := *entry.resp
+	resp.Header = entry.header.Clone()
+	if entry.body != nil {
+		resp.Body = entry.body.NewReader()
+	}
+	c.release(entry)
+	return &resp
+}
+
+// leave takes a request out of the waiting ones, an entry left without leader nor waiters is removed
+func (c *Collapser) leave(key string, entry *CollapseEntry) {
+	c.pendingMapMutex.Lock()
+	defer c.pendingMapMutex.Unlock()
+	entry.pendingCount--
+	if entry.pendingCount == 0 && len(entry.lead) > 0 {
+		<-entry.lead
+		c.remove(key, entry)
+	}
+	c.release(entry)
+}
+
+// remove takes the entry out of the map, the late requests fetch again. pendingMapMutex is held.
+func (c *Collapser) remove(key string, entry *CollapseEntry) {
+	if c.pendingReq[key] == entry {
+		delete(c.pendingReq, key)
+	}
+	entry.removed = true
+	c.release(entry)
+}
+
+// release drops the reference of the entry on the body once no request can get a reader through the entry.
+// pendingMapMutex is held.
+func (c *Collapser) release(entry *CollapseEntry) {
+	if entry.removed && entry.pendingCount == 0 && entry.body != nil {
+		entry.body.Release()
+		entry.body = nil
+	}
+}
@@ -0,0 +1,394 @@
+package frontend
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hcl/cdn/cacheNode/config"
+	coCfg "github.com/hcl/cdn/common/config"
+	"github.com/hcl/cdn/common/helper"
+)
+
+// streamOrigin answers like the backend, the body is shared with the store and filled from a pipe the test writes
+type streamOrigin struct {
+	mu    sync.Mutex
+	calls int
+	w     *io.PipeWriter
+}
+
+func (o *streamOrigin) Do(req *http.Request) (*http.Response, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.calls++
+	r, w := io.Pipe()
+	o.w = w
+	body := helper.NewSharedBody(r)
+	resp := &http.Response{StatusCode: http.StatusOK, Header: make(http.Header), Body: body.NewReader()}
+	resp.Header.Set("Content-Type", "text/plain")
+	body.Release()
+	return resp, nil
+}
+
+func (o *streamOrigin) ReDo(req *http.Request, oldResp *http.Response) (*http.Response, error) {
+	return o.Do(req)
+}
+
+func (o *streamOrigin) fetches() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.calls
+}
+
+func (o *streamOrigin) writer() *io.PipeWriter {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.w
+}
+
+func pending(c *Collapser) int {
+	c.pendingMapMutex.RLock()
+	defer c.pendingMapMutex.RUnlock()
+	return len(c.pendingReq)
+}
+
+func TestCollapserStreamsToLateRequests(t *testing.T) {
+	origin := &streamOrigin{}
+	c := NewCollapser(&Finder{StoragePath: emptyStore{}, BackendPath: origin})
+	req := func() *http.Request { return httptest.NewRequest(http.MethodGet, "http://abc.com/video.mp4", nil) }
+
+	first, _ := c.Do(req())
+	w := origin.writer()
+	w.Write([]byte("part one, "))
+
+	// the fill has begun, the late request joins it
+	late, _ := c.Do(req())
+	if origin.fetches() != 1 {
+		t.Fatalf("late request fetched again, %d fetches", origin.fetches())
+	}
+	late.Header.Set("X-Late", "1")
+	if first.Header.Get("X-Late") != "" {
+		t.Error("header of the late request shared with the first request")
+	}
+
+	go func() {
+		w.Write([]byte("part two"))
+		w.Close()
+	}()
+	// the first client does not read yet, the late one streams the whole body
+	got, err := io.ReadAll(late.Body)
+	if err != nil || string(got) != "part one, part two" {
+		t.Errorf("late request got %q, error %v", got, err)
+	}
+	got, err = io.ReadAll(first.Body)
+	if err != nil || string(got) != "part one, part two" {
+		t.Errorf("first request got %q, error %v", got, err)
+	}
+	first.Body.Close()
+	late.Body.Close()
+
+	// once filled the entry is gone, the next request goes through the Finder again
+	deadline := time.Now().Add(5 * time.Second)
+	for pending(c) != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if pending(c) != 0 {
+		t.Fatal("entry of the filled body left in the map")
+	}
+	next, _ := c.Do(req())
+	origin.writer().Close()
+	next.Body.Close()
+	if origin.fetches() != 2 {
+		t.Errorf("expected a new fetch after the fill, %d fetches", origin.fetches())
+	}
+}
+
+func TestCollapserUpstreamError(t *testing.T) {
+	origin := &streamOrigin{}
+	c := NewCollapser(&Finder{StoragePath: emptyStore{}, BackendPath: origin})
+	req := func() *http.Request { return httptest.NewRequest(http.MethodGet, "http://abc.com/broken", nil) }
+
+	first, _ := c.Do(req())
+	second, _ := c.Do(req())
+	errUpstream := errors.New("origin reset")
+	w := origin.writer()
+	go func() {
+		w.Write([]byte("partial"))
+		w.CloseWithError(errUpstream)
+	}()
+	for _, resp := range []*http.Response{first, second} {
+		got, err := io.ReadAll(resp.Body)
+		if string(got) != "partial" || !errors.Is(err, errUpstream) {
+			t.Errorf("got %q, error %v, want the data then %v", got, err, errUpstream)
+		}
+		resp.Body.Close()
+	}
+}
+
+// gateOrigin holds its first fetch until the gate opens, then fails it with firstErr or answers "first".
+// The other fetches answer "fresh" right away. The responses vary on the header names of vary.
+type gateOrigin struct {
+	mu       sync.Mutex
+	calls    int
+	gate     chan struct{}
+	firstErr error
+	vary     string
+}
+
+func (o *gateOrigin) Do(req *http.Request) (*http.Response, error) {
+	o.mu.Lock()
+	o.calls++
+	first := o.calls == 1
+	o.mu.Unlock()
+	body := "fresh"
+	if first {
+		<-o.gate
+		if o.firstErr != nil {
+			return nil, o.firstErr
+		}
+		body = "first"
+	}
+	resp := &http.Response{StatusCode: http.StatusOK, Header: make(http.Header), Body: io.NopCloser(strings.NewReader(body))}
+	if o.vary != "" {
+		resp.Header.Set("Vary", o.vary)
+	}
+	return resp, nil
+}
+
+func (o *gateOrigin) ReDo(req *http.Request, oldResp *http.Response) (*http.Response, error) {
+	return o.Do(req)
+}
+
+func (o *gateOrigin) fetches() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.calls
+}
+
+type fetchResult struct {
+	resp *http.Response
+	role string
+	err  error
+}
+
+// fetchAsync runs the request through the Collapser, the result is sent once fetched
+func fetchAsync(c *Collapser, req *http.Request) chan fetchResult {
+	done := make(chan fetchResult, 1)
+	go func() {
+		resp, role, err := c.Fetch(req)
+		done <- fetchResult{resp, role, err}
+	}()
+	return done
+}
+
+// waitFor polls cond until it holds or the test times out
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func waiting(c *Collapser, method string, url string) int {
+	c.pendingMapMutex.RLock()
+	defer c.pendingMapMutex.RUnlock()
+	entry, ok := c.pendingReq[method+" "+c.cacheKey(httptest.NewRequest(method, url, nil))]
+	if !ok {
+		return -1
+	}
+	return entry.pendingCount
+}
+
+func TestCollapserLeaderFailover(t *testing.T) {
+	origin := &gateOrigin{gate: make(chan struct{}), firstErr: errors.New("origin unreachable")}
+	c := NewCollapser(&Finder{StoragePath: emptyStore{}, BackendPath: origin})
+	const url = "http://abc.com/failover"
+
+	leader := fetchAsync(c, httptest.NewRequest(http.MethodGet, url, nil))
+	waitFor(t, "the leader", func() bool { return waiting(c, http.MethodGet, url) == 0 })
+	waiter := fetchAsync(c, httptest.NewRequest(http.MethodGet, url, nil))
+	waitFor(t, "the waiter", func() bool { return waiting(c, http.MethodGet, url) == 1 })
+	close(origin.gate)
+
+	got := <-leader
+	if got.role != CollapseLeader || got.resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("leader: role %q status %d, want the failure of its own fetch", got.role, got.resp.StatusCode)
+	}
+	got = <-waiter
+	if got.err != nil || got.role != CollapsePromoted || got.resp.StatusCode != http.StatusOK {
+		t.Fatalf("waiter: role %q error %v, want a promoted fetch", got.role, got.err)
+	}
+	body, _ := io.ReadAll(got.resp.Body)
+	got.resp.Body.Close()
+	if string(body) != "fresh" || origin.fetches() != 2 {
+		t.Errorf("waiter got %q after %d fetches", body, origin.fetches())
+	}
+}
+
+func TestCollapserLeaderCancelledAfterFetch(t *testing.T) {
+	origin := &gateOrigin{gate: make(chan struct{})}
+	c := NewCollapser(&Finder{StoragePath: emptyStore{}, BackendPath: origin})
+	const url = "http://abc.com/leader-left"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	leader := fetchAsync(c, httptest.NewRequest(http.MethodGet, url, nil).WithContext(ctx))
+	waitFor(t, "the leader", func() bool { return waiting(c, http.MethodGet, url) == 0 })
+	waiter := fetchAsync(c, httptest.NewRequest(http.MethodGet, url, nil))
+	waitFor(t, "the waiter", func() bool { return waiting(c, http.MethodGet, url) == 1 })
+	// the client of the leader leaves once the response is fetched
+	cancel()
+	close(origin.gate)
+
+	got := <-leader
+	got.resp.Body.Close()
+	got = <-waiter
+	if got.err != nil || got.role != CollapseWaiter {
+		t.Fatalf("waiter: role %q error %v, want the response of the leader", got.role, got.err)
+	}
+	body, _ := io.ReadAll(got.resp.Body)
+	got.resp.Body.Close()
+	if string(body) != "first" || origin.fetches() != 1 {
+		t.Errorf("waiter got %q after %d fetches, want the fetch of the leader", body, origin.fetches())
+	}
+}
+
+func TestCollapserVariants(t *testing.T) {
+	origin := &gateOrigin{gate: make(chan struct{}), vary: "Accept-Language, Accept-Encoding"}
+	c := NewCollapser(&Finder{StoragePath: emptyStore{}, BackendPath: origin})
+	const url = "http://abc.com/variants"
+	req := func(language string, encoding string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, url, nil)
+		r.Header.Set("Accept-Language", language)
+		r.Header.Set("Accept-Encoding", encoding)
+		return r
+	}
+
+	leader := fetchAsync(c, req("en", "gzip"))
+	waitFor(t, "the leader", func() bool { return waiting(c, http.MethodGet, url) == 0 })
+	// the content coding is adapted to each client, any serves
+	sameVariant := fetchAsync(c, req("en", "br"))
+	otherVariant := fetchAsync(c, req("fr", "gzip"))
+	waitFor(t, "the waiters", func() bool { return waiting(c, http.MethodGet, url) == 2 })
+	close(origin.gate)
+
+	got := <-leader
+	got.resp.Body.Close()
+	for _, want := range []struct {
+		name   string
+		result chan fetchResult
+		role   string
+		body   string
+	}{
+		{"same variant", sameVariant, CollapseWaiter, "first"},
+		{"other variant", otherVariant, CollapseVariant, "fresh"},
+	} {
+		got := <-want.result
+		if got.err != nil || got.role != want.role {
+			t.Errorf("%s: role %q error %v, want role %q", want.name, got.role, got.err, want.role)
+			continue
+		}
+		body, _ := io.ReadAll(got.resp.Body)
+		got.resp.Body.Close()
+		if string(body) != want.body {
+			t.Errorf("%s: got %q, want %q", want.name, body, want.body)
+		}
+	}
+	if origin.fetches() != 2 {
+		t.Errorf("expected the other variant fetched alone, %d fetches", origin.fetches())
+	}
+}
+
+func TestCollapserWaiterCancelled(t *testing.T) {
+	origin := &gateOrigin{gate: make(chan struct{})}
+	c := NewCollapser(&Finder{StoragePath: emptyStore{}, BackendPath: origin})
+	const url = "http://abc.com/cancel"
+
+	leader := fetchAsync(c, httptest.NewRequest(http.MethodGet, url, nil))
+	waitFor(t, "the leader", func() bool { return waiting(c, http.MethodGet, url) == 0 })
+	ctx, cancel := context.WithCancel(context.Background())
+	waiter := fetchAsync(c, httptest.NewRequest(http.MethodGet, url, nil).WithContext(ctx))
+	waitFor(t, "the waiter", func() bool { return waiting(c, http.MethodGet, url) == 1 })
+	cancel()
+
+	got := <-waiter
+	if !errors.Is(got.err, context.Canceled) || got.role != CollapseCanceled {
+		t.Errorf("cancelled waiter: role %q error %v", got.role, got.err)
+	}
+	if n := waiting(c, http.MethodGet, url); n != 0 {
+		t.Errorf("cancelled waiter still counted, %d waiting", n)
+	}
+	close(origin.gate)
+	got = <-leader
+	body, _ := io.ReadAll(got.resp.Body)
+	got.resp.Body.Close()
+	if string(body) != "first" {
+		t.Errorf("leader got %q", body)
+	}
+}
+
+func TestCollapserTimeout(t *testing.T) {
+	origin := &gateOrigin{gate: make(chan struct{})}
+	defer close(origin.gate)
+	c := NewCollapser(&Finder{StoragePath: emptyStore{}, BackendPath: origin})
+	c.Config = &config.RunConfig{
+		Node:        &coCfg.CacheNode{Type: coCfg.CacheNodeEdge, CollapseTimeout: 1},
+		ServiceList: &coCfg.DeliveryServices{},
+	}
+	const url = "http://abc.com/slow"
+
+	fetchAsync(c, httptest.NewRequest(http.MethodGet, url, nil))
+	waitFor(t, "the leader", func() bool { return waiting(c, http.MethodGet, url) == 0 })
+	start := time.Now()
+	resp, role, err := c.Fetch(httptest.NewRequest(http.MethodGet, url, nil))
+	if err != nil || role != CollapseTimeout {
+		t.Fatalf("role %q error %v, want a fetch of its own after the timeout", role, err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "fresh" || time.Since(start) < time.Second {
+		t.Errorf("got %q after %v", body, time.Since(start))
+	}
+}
+
+func TestCollapserNotCollapsible(t *testing.T) {
+	origin := &gateOrigin{gate: make(chan struct{})}
+	c := NewCollapser(&Finder{StoragePath: emptyStore{}, BackendPath: origin})
+	const url = "http://abc.com/object"
+
+	leader := fetchAsync(c, httptest.NewRequest(http.MethodGet, url, nil))
+	waitFor(t, "the leader", func() bool { return waiting(c, http.MethodGet, url) == 0 })
+
+	// HEAD requests are collapsed apart from GET requests, requests the cache may not store are not collapsed
+	head := httptest.NewRequest(http.MethodHead, url, nil)
+	noStore := httptest.NewRequest(http.MethodGet, url, nil)
+	noStore.Header.Set("Cache-Control", "no-store")
+	authorized := httptest.NewRequest(http.MethodGet, url, nil)
+	authorized.Header.Set("Authorization", "Bearer x")
+	for _, tc := range []struct {
+		req  *http.Request
+		role string
+	}{{head, CollapseLeader}, {noStore, ""}, {authorized, ""}} {
+		resp, role, err := c.Fetch(tc.req)
+		if err != nil || role != tc.role || resp.StatusCode != http.StatusOK {
+			t.Errorf("%s %v: role %q error %v, want role %q", tc.req.Method, tc.req.Header, role, err, tc.role)
+			continue
+		}
+		resp.Body.Close()
+	}
+	if origin.fetches() != 4 {
+		t.Errorf("expected each request to fetch, %d fetches", origin.fetches())
+	}
+	close(origin.gate)
+	got := <-leader
+	got.resp.Body.Close()
+}
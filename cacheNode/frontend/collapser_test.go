@@ -6,147 +6,130 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/hcl/cdn/cacheNode/frontend"
 )
 
-type mockRequestHandler struct {
-	resp *http.Response
-	err  error
-}
+// mockRequestHandler is declared in finder_test.go
 
-func (m *mockRequestHandler) Do(*http.Request) (*http.Response, error) {
-	return m.resp, m.err
+// slowBackend answers every fetch with a new body after the delay and counts the fetches
+type slowBackend struct {
+	delay   time.Duration
+	fetches atomic.Int32
 }
 
-// Skipping the tests involving MockFinder
-func TestCollapser_Do(t *testing.T) {
-	t.Run("No pending request, new request is processed", func(t *testing.T) {
-		//expectedResp := &http.Response{StatusCode: http.StatusOK}
-		//mockHandler := &mockRequestHandler{resp: expectedResp}
-		collapser := frontend.NewCollapser(nil) // passing nil for Finder
-
-		req := httptest.NewRequest("GET", "http://example.com/test", nil)
-		resp, _ := collapser.Do(req)
-
-		if resp.StatusCode != http.StatusOK {
-			t.Errorf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
-		}
-	})
-
-	t.Run("Duplicate request, should wait for response", func(t *testing.T) {
-		//expectedResp := &http.Response{StatusCode: http.StatusOK}
-		//mockHandler := &mockRequestHandler{resp: expectedResp}
-		collapser := frontend.NewCollapser(nil) // passing nil for Finder
-
-		req1 := httptest.NewRequest("GET", "http://example.com/test", nil)
-		req2 := httptest.NewRequest("GET", "http://example.com/test", nil)
-
-		// Start first request
-		go func() {
-			_, _ = collapser.Do(req1)
-		}()
+func (b *slowBackend) Do(req *http.Request) (*http.Response, error) {
+	b.fetches.Add(1)
+	time.Sleep(b.delay)
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader("fetched " + req.URL.Path)),
+	}, nil
+}
 
-		// Start second request which should wait for the first response
-		resp, _ := collapser.Do(req2)
+func (b *slowBackend) ReDo(req *http.Request, resp *http.Response) (*http.Response, error) {
+	return b.Do(req)
+}
 
-		if resp.StatusCode != http.StatusOK {
-			t.Errorf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
-		}
+// newCollapser returns a collapser fetching the objects missing in storage from the backend
+func newCollapser(backend *slowBackend) *frontend.Collapser {
+	return frontend.NewCollapser(&frontend.Finder{
+		StoragePath: &mockRequestHandler{resp: &http.Response{StatusCode: http.StatusNotFound, Header: make(http.Header), Body: http.NoBody}},
+		BackendPath: backend,
 	})
+}
 
-	t.Run("Error response from finder", func(t *testing.T) {
-		//mockHandler := &mockRequestHandler{err: errors.New("finder error")}
-		collapser := frontend.NewCollapser(nil) // passing nil for Finder
+func readBody(t *testing.T, resp *http.Response) string {
+	t.Helper()
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Errorf("reading the body failed: %v", err)
+	}
+	return string(body)
+}
 
-		req := httptest.NewRequest("GET", "http://example.com/test", nil)
-		resp, _ := collapser.Do(req)
+func TestCollapser_Do(t *testing.T) {
+	t.Run("No pending request, new request is processed", func(t *testing.T) {
+		backend := &slowBackend{}
+		collapser := newCollapser(backend)
 
-		if resp.StatusCode != http.StatusInternalServerError {
-			t.Errorf("expected status %d, got %d", http.StatusInternalServerError, resp.StatusCode)
+		resp, err := collapser.Do(httptest.NewRequest("GET", "http://example.com/test", nil))
+		if err != nil || resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %v %v", http.StatusOK, resp, err)
 		}
-
-		body, _ := io.ReadAll(resp.Body)
-		if !strings.Contains(string(body), "Internal Server Error from FE") {
-			t.Errorf("unexpected error message: %s", body)
+		if body := readBody(t, resp); body != "fetched /test" {
+			t.Errorf("unexpected body %q", body)
 		}
 	})
 
-	t.Run("Simultaneous requests to the same URL", func(t *testing.T) {
-		//expectedResp := &http.Response{StatusCode: http.StatusOK}
-		//mockHandler := &mockRequestHandler{resp: expectedResp}
-		collapser := frontend.NewCollapser(nil) // passing nil for Finder
-
-		req1 := httptest.NewRequest("GET", "http://example.com/test", nil)
-		req2 := httptest.NewRequest("GET", "http://example.com/test", nil)
-
-		// Start first request
-		go func() {
-			_, _ = collapser.Do(req1)
-		}()
-
-		// Start second request which should wait for the first response
-		resp, _ := collapser.Do(req2)
-
-		if resp.StatusCode != http.StatusOK {
-			t.Errorf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	t.Run("Simultaneous requests to the same URL share one fetch", func(t *testing.T) {
+		backend := &slowBackend{delay: 200 * time.Millisecond}
+		collapser := newCollapser(backend)
+
+		var wg sync.WaitGroup
+		bodies := make([]string, 3)
+		for i := range bodies {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				resp, err := collapser.Do(httptest.NewRequest("GET", "http://example.com/test", nil))
+				if err != nil || resp.StatusCode != http.StatusOK {
+					t.Errorf("expected status %d, got %v %v", http.StatusOK, resp, err)
+					return
+				}
+				bodies[i] = readBody(t, resp)
+			}(i)
+			time.Sleep(20 * time.Millisecond)
 		}
-	})
-
-	t.Run("No entry found, create new entry and return response", func(t *testing.T) {
-		//expectedResp := &http.Response{StatusCode: http.StatusOK}
-		//mockHandler := &mockRequestHandler{resp: expectedResp}
-		collapser := frontend.NewCollapser(nil) // passing nil for Finder
-
-		req := httptest.NewRequest("GET", "http://example.com/test", nil)
-		resp, _ := collapser.Do(req)
-
-		if resp.StatusCode != http.StatusOK {
-			t.Errorf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+		wg.Wait()
+		if backend.fetches.Load() != 1 {
+			t.Errorf("expected one fetch, got %d", backend.fetches.Load())
 		}
-	})
-}
-
-// Test CollapseEntry methods
-func TestCollapseEntry_HandleResponse(t *testing.T) {
-	t.Run("Handle response with error", func(t *testing.T) {
-		entry := frontend.NewCollapseEntry()
-		resp, _ := entry.HandleResponse(nil, errors.New("error"))
-		if resp.StatusCode != http.StatusInternalServerError {
-			t.Errorf("expected status %d, got %d", http.StatusInternalServerError, resp.StatusCode)
+		for _, body := range bodies {
+			if body != "fetched /test" {
+				t.Errorf("unexpected body %q", body)
+			}
 		}
 	})
 
-	t.Run("Handle successful response", func(t *testing.T) {
-		expectedResp := &http.Response{StatusCode: http.StatusOK}
-		entry := frontend.NewCollapseEntry()
-		resp, _ := entry.HandleResponse(expectedResp, nil)
-
-		if resp.StatusCode != http.StatusOK {
-			t.Errorf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	t.Run("Requests to different URLs are not collapsed", func(t *testing.T) {
+		backend := &slowBackend{delay: 100 * time.Millisecond}
+		collapser := newCollapser(backend)
+
+		var wg sync.WaitGroup
+		for _, path := range []string{"/a", "/b"} {
+			wg.Add(1)
+			go func(path string) {
+				defer wg.Done()
+				resp, _ := collapser.Do(httptest.NewRequest("GET", "http://example.com"+path, nil))
+				if body := readBody(t, resp); body != "fetched "+path {
+					t.Errorf("unexpected body %q for %s", body, path)
+				}
+			}(path)
+		}
+		wg.Wait()
+		if backend.fetches.Load() != 2 {
+			t.Errorf("expected two fetches, got %d", backend.fetches.Load())
 		}
 	})
-}
-
-func TestCollapseEntry_WaitForResponse(t *testing.T) {
-	t.Run("Wait for response", func(t *testing.T) {
-		entry := frontend.NewCollapseEntry()
 
-		// Simulate a response being set
-		go func() {
-			time.Sleep(1 * time.Second)
-			entry.HandleResponse(&http.Response{StatusCode: http.StatusOK}, nil)
-		}()
+	t.Run("Error response from finder", func(t *testing.T) {
+		collapser := frontend.NewCollapser(&frontend.Finder{
+			StoragePath: &mockRequestHandler{err: errors.New("storage error")},
+		})
 
-		resp, err := entry.WaitForResponse()
-		if err != nil {
-			t.Errorf("unexpected error: %v", err)
+		resp, _ := collapser.Do(httptest.NewRequest("GET", "http://example.com/test", nil))
+		if resp.StatusCode != http.StatusInternalServerError {
+			t.Errorf("expected status %d, got %d", http.StatusInternalServerError, resp.StatusCode)
 		}
-
-		if resp.StatusCode != http.StatusOK {
-			t.Errorf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+		if body := readBody(t, resp); !strings.Contains(body, "storage error") {
+			t.Errorf("unexpected error message: %s", body)
 		}
 	})
 }
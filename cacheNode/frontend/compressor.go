@@ -0,0 +1,291 @@
+package frontend
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/hcl/cdn/cacheNode/cachePolicy"
+	"github.com/hcl/cdn/cacheNode/common"
+	"github.com/hcl/cdn/cacheNode/config"
+	"github.com/hcl/cdn/common/helper"
+)
+
+// compressed variants larger than this are served but not stored
+const maxStoredCompressedSize = 16 << 20
+
+// Compressor adapts the content coding of the response to the client (Accept-Encoding).
+// Compressible responses of a DS with compression enabled are compressed with br or gzip and the
+// compressed copy is stored as a variant of the object, so later clients are served it from storage.
+// gzip & br copies are decoded for clients which do not accept them.
+type Compressor struct {
+	ctx    context.Context
+	wg     *sync.WaitGroup
+	Config *config.RunConfig
+	Store  common.RequestHandler
+}
+
+// NewCompressor initializes a new Compressor
+func NewCompressor(ctx context.Context, wg *sync.WaitGroup, cfg *config.RunConfig, store common.RequestHandler) *Compressor {
+	return &Compressor{
+		ctx:    ctx,
+		wg:     wg,
+		Config: cfg,
+		Store:  store,
+	}
+}
+
+// plan chooses the representation of the response served to the client: the content coding to decode
+// and the one to encode, "" for none. vary reports an identity copy marked as varying on Accept-Encoding.
+func (c *Compressor) plan(req *http.Request, resp *http.Response) (decodeFrom string, encodeTo string, vary bool) {
+	if c == nil || resp == nil || resp.StatusCode != http.StatusOK || c.Config == nil {
+		return
+	}
+	ds, err := c.Config.DSLookup(req)
+	if err != nil {
+		return
+	}
+
+	header := resp.Header
+	coding := cachePolicy.ContentCoding(header)
+	if coding != cachePolicy.EncodingIdentity && !cachePolicy.AcceptsEncoding(req.Header, coding) {
+		// codings not supported by the cache node go to the client as they are
+		if coding != cachePolicy.EncodingGzip && coding != cachePolicy.EncodingBrotli {
+			slog.Info("FE compressor.go : Unsupported content coding", "url", helper.GetString(req), "coding", coding)
+			return
+		}
+		decodeFrom = coding
+		header = header.Clone()
+		header.Del("Content-Encoding")
+		header.Del("Content-Length")
+	}
+
+	if !cachePolicy.Compressible(&ds.Compression, header) {
+		return
+	}
+	coding = cachePolicy.PreferredEncoding(req.Header)
+	// Ranges are served out of the stored identity copy, its length is known
+	if coding == "" || req.Header.Get("Range") != "" {
+		return decodeFrom, "", true
+	}
+	return decodeFrom, coding, false
+}
+
+// Representation returns the response with the header of the representation Serve makes of it, the body is
+// left as is. Client preconditions are evaluated against it before any content is decoded or compressed.
+func (c *Compressor) Representation(req *http.Request, resp *http.Response) *http.Response {
+	decodeFrom, encodeTo, vary := c.plan(req, resp)
+	if decodeFrom == "" && encodeTo == "" && !vary {
+		return resp
+	}
+	ret := withHeader(resp)
+	if decodeFrom != "" {
+		decoded(ret)
+	}
+	if encodeTo != "" {
+		encoded(ret, encodeTo)
+	}
+	if vary {
+		cachePolicy.AddVary(ret.Header, "Accept-Encoding")
+	}
+	return ret
+}
+
+// Serve returns the response in a content coding accepted by the client.
+// The response header may be shared with collapsed requests, it is cloned before any change.
+func (c *Compressor) Serve(req *http.Request, resp *http.Response) (*http.Response, error) {
+	decodeFrom, encodeTo, vary := c.plan(req, resp)
+	var err error
+	if decodeFrom != "" {
+		resp, err = c.decode(req, resp, decodeFrom)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if encodeTo != "" {
+		return c.encode(req, resp, encodeTo), nil
+	}
+	if vary {
+		resp = withHeader(resp)
+		cachePolicy.AddVary(resp.Header, "Accept-Encoding")
+	}
+	return resp, nil
+}
+
+// withHeader returns a shallow copy of the response with its own header
+func withHeader(resp *http.Response) *http.Response {
+	ret := *resp
+	ret.Header = resp.Header.Clone()
+	return &ret
+}
+
+// representationChanged updates the header of a response whose content coding changed
+func representationChanged(resp *http.Response) {
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	if etag := resp.Header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		resp.Header.Set("ETag", "W/"+etag)
+	}
+	cachePolicy.AddVary(resp.Header, "Accept-Encoding")
+}
+
+// decoded updates the header of a response whose content coding is removed
+func decoded(resp *http.Response) {
+	resp.Header.Del("Content-Encoding")
+	representationChanged(resp)
+}
+
+// encoded updates the header of a response compressed with coding
+func encoded(resp *http.Response, coding string) {
+	resp.Header.Set("Content-Encoding", coding)
+	representationChanged(resp)
+}
+
+// decodedBody closes the decoder along with the response body
+type decodedBody struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (b *decodedBody) Close() (err error) {
+	for _, closer := range b.closers {
+		if cerr := closer.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return
+}
+
+// decode removes the gzip or br content coding the client does not accept
+func (c *Compressor) decode(req *http.Request, resp *http.Response, coding string) (*http.Response, error) {
+	slog.Info("FE compressor.go : Decoding response for client", "url", helper.GetString(req), "coding", coding)
+	ret := withHeader(resp)
+	decoded(ret)
+	if req.Method == http.MethodHead || resp.Body == nil {
+		return ret, nil
+	}
+	switch coding {
+	case cachePolicy.EncodingGzip:
+		zr, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			resp.Body.Close()
+			slog.Error("FE compressor.go : Failed to decode gzip response", "url", helper.GetString(req), "error", err.Error())
+			return nil, err
+		}
+		ret.Body = &decodedBody{Reader: zr, closers: []io.Closer{zr, resp.Body}}
+	case cachePolicy.EncodingBrotli:
+		ret.Body = &decodedBody{Reader: brotli.NewReader(resp.Body), closers: []io.Closer{resp.Body}}
+	}
+	return ret, nil
+}
+
+// boundedBuffer keeps a copy of the written bytes unless they exceed max
+type boundedBuffer struct {
+	buf      bytes.Buffer
+	max      int
+	overflow bool
+}
+
+func (b *boundedBuffer) Write(p []byte) (int, error) {
+	if b.overflow || b.buf.Len()+len(p) > b.max {
+		b.overflow = true
+		b.buf.Reset()
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+func newEncoder(coding string, w io.Writer) io.WriteCloser {
+	if coding == cachePolicy.EncodingBrotli {
+		return brotli.NewWriterLevel(w, brotli.DefaultCompression)
+	}
+	return gzip.NewWriter(w)
+}
+
+// encode compresses the response on the fly, the compressed copy is stored once it is complete
+func (c *Compressor) encode(req *http.Request, resp *http.Response, coding string) *http.Response {
+	slog.Info("FE compressor.go : Compressing response for client", "url", helper.GetString(req), "coding", coding)
+	ret := withHeader(resp)
+	encoded(ret, coding)
+	if req.Method == http.MethodHead || resp.Body == nil {
+		return ret
+	}
+
+	storedHeader := ret.Header.Clone()
+	storedHeader.Del("X-Is-Cached")
+	storedHeader.Del("Age")
+	storeReq := req.Clone(c.ctx)
+
+	body := resp.Body
+	rdr, wtr := io.Pipe()
+	ret.Body = rdr
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		defer body.Close()
+		capture := &boundedBuffer{max: maxStoredCompressedSize}
+		enc := newEncoder(coding, io.MultiWriter(wtr, capture))
+		_, err := io.Copy(enc, body)
+		if cerr := enc.Close(); err == nil {
+			err = cerr
+		}
+		wtr.CloseWithError(err)
+		if err != nil {
+			slog.Error("FE compressor.go : Compression aborted", "url", helper.GetString(storeReq), "error", err.Error())
+			return
+		}
+		if capture.overflow {
+			slog.Info("FE compressor.go : Compressed copy too large to store", "url", helper.GetString(storeReq))
+			return
+		}
+		c.save(storeReq, storedHeader, capture.buf.Bytes())
+	}()
+	return ret
+}
+
+// save stores the compressed copy as the variant of its content coding
+func (c *Compressor) save(req *http.Request, header http.Header, content []byte) {
+	if c.Store == nil {
+		return
+	}
+	stored := &http.Response{StatusCode: http.StatusOK, Header: header}
+	if !cachePolicy.IsStorable(req, stored) {
+		return
+	}
+	postreq, err := http.NewRequest(http.MethodPost, helper.GetString(req), bytes.NewReader(content))
+	if err != nil {
+		slog.Error("FE compressor.go : Error creating post request to store", "error", err.Error())
+		return
+	}
+	postreq.Header = header.Clone()
+	postreq.Header.Set("Content-Length", strconv.Itoa(len(content)))
+	// the store selects the object & variant by the request headers, the coding stands for Accept-Encoding
+	for _, name := range cachePolicy.VaryHeaders(header) {
+		if name == "Accept-Encoding" {
+			postreq.Header.Set(cachePolicy.VaryRequestHeaderPrefix+name, cachePolicy.ContentCoding(header))
+		} else if values := req.Header.Values(name); len(values) > 0 {
+			postreq.Header[cachePolicy.VaryRequestHeaderPrefix+name] = values
+		}
+	}
+	if ds, err := c.Config.DSLookup(req); err == nil {
+		for _, name := range cachePolicy.KeyHeaders(&ds.CacheKey) {
+			if values := req.Header.Values(name); len(values) > 0 {
+				postreq.Header[cachePolicy.VaryRequestHeaderPrefix+name] = values
+			}
+		}
+	}
+	postreq = postreq.WithContext(c.ctx)
+	storeResp, err := c.Store.Do(postreq)
+	if err != nil {
+		slog.Error("FE compressor.go : Error saving compressed copy to store", "error", err.Error())
+		return
+	}
+	slog.Info("FE compressor.go : Compressed copy saved", "url", helper.GetString(req), "status", storeResp.StatusCode)
+}
@@ -0,0 +1,221 @@
+package frontend
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andybalholm/brotli"
+	"github.com/hcl/cdn/cacheNode/cachePolicy"
+	"github.com/hcl/cdn/cacheNode/config"
+	coCfg "github.com/hcl/cdn/common/config"
+)
+
+// recordingStore keeps the requests it is given along with their body
+type recordingStore struct {
+	mu     sync.Mutex
+	reqs   []*http.Request
+	bodies [][]byte
+}
+
+func (s *recordingStore) Do(req *http.Request) (*http.Response, error) {
+	body, _ := io.ReadAll(req.Body)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reqs = append(s.reqs, req)
+	s.bodies = append(s.bodies, body)
+	return &http.Response{StatusCode: http.StatusCreated, Header: make(http.Header), Body: http.NoBody}, nil
+}
+
+func newCompressionConfig(compression coCfg.Compression) *config.RunConfig {
+	return &config.RunConfig{
+		ServiceList: &coCfg.DeliveryServices{
+			ServiceList: []coCfg.DeliveryService{
+				{Name: "abc", ClientURL: "http://abc.com", OriginURL: "http://origin.abc.com", Compression: compression},
+			},
+		},
+	}
+}
+
+func newCompressibleResponse(content string) *http.Response {
+	resp := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader(content)),
+	}
+	resp.Header.Set("Content-Type", "text/html")
+	resp.Header.Set("Cache-Control", "max-age=600")
+	resp.Header.Set("ETag", `"v1"`)
+	resp.Header.Set("X-Is-Cached", "true")
+	return resp
+}
+
+func TestCompressorEncode(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	defer cancel()
+	store := &recordingStore{}
+	c := NewCompressor(ctx, &wg, newCompressionConfig(coCfg.Compression{Enabled: true}), store)
+	content := strings.Repeat("compress me ", 100)
+
+	req := httptest.NewRequest(http.MethodGet, "http://abc.com/index.html", nil)
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+	shared := newCompressibleResponse(content)
+	resp, err := c.Serve(req, shared)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if resp.Header.Get("Content-Encoding") != "gzip" || resp.Header.Get("ETag") != `W/"v1"` || resp.Header.Get("Vary") != "Accept-Encoding" {
+		t.Errorf("unexpected headers %v", resp.Header)
+	}
+	if shared.Header.Get("Content-Encoding") != "" {
+		t.Errorf("header shared with collapsed requests must not change")
+	}
+	zr, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		t.Fatalf("expected gzip content, got %v", err)
+	}
+	if body, _ := io.ReadAll(zr); string(body) != content {
+		t.Errorf("unexpected decompressed content %q", body)
+	}
+	resp.Body.Close()
+
+	// The compressed copy is stored as the gzip variant
+	for i := 0; i < 100; i++ {
+		store.mu.Lock()
+		n := len(store.reqs)
+		store.mu.Unlock()
+		if n > 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	wg.Wait()
+	if len(store.reqs) != 1 {
+		t.Fatalf("expected compressed copy to be stored, got %d requests", len(store.reqs))
+	}
+	stored := store.reqs[0]
+	if stored.Method != http.MethodPost || stored.Header.Get(cachePolicy.VaryRequestHeaderPrefix+"Accept-Encoding") != "gzip" {
+		t.Errorf("unexpected store request %s %v", stored.Method, stored.Header)
+	}
+	if stored.Header.Get("X-Is-Cached") != "" {
+		t.Errorf("X-Is-Cached must not be stored")
+	}
+	zr, _ = gzip.NewReader(bytes.NewReader(store.bodies[0]))
+	if body, _ := io.ReadAll(zr); string(body) != content {
+		t.Errorf("unexpected stored content")
+	}
+
+	// br is preferred when the client accepts both
+	req.Header.Set("Accept-Encoding", "gzip, br")
+	resp, _ = c.Serve(req, newCompressibleResponse(content))
+	if resp.Header.Get("Content-Encoding") != "br" {
+		t.Errorf("expected br, got %q", resp.Header.Get("Content-Encoding"))
+	}
+	if body, _ := io.ReadAll(brotli.NewReader(resp.Body)); string(body) != content {
+		t.Errorf("unexpected br content")
+	}
+	resp.Body.Close()
+	wg.Wait()
+}
+
+func TestCompressorPassThrough(t *testing.T) {
+	var wg sync.WaitGroup
+	c := NewCompressor(context.Background(), &wg, newCompressionConfig(coCfg.Compression{Enabled: true, MinSize: 1000}), nil)
+
+	// too small
+	req := httptest.NewRequest(http.MethodGet, "http://abc.com/small.html", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	small := newCompressibleResponse("small")
+	small.Header.Set("Content-Length", "5")
+	if resp, _ := c.Serve(req, small); resp.Header.Get("Content-Encoding") != "" {
+		t.Errorf("expected small content not to be compressed")
+	}
+
+	// identity client is served the identity copy, marked as varying
+	req = httptest.NewRequest(http.MethodGet, "http://abc.com/index.html", nil)
+	resp, _ := c.Serve(req, newCompressibleResponse("content"))
+	if resp.Header.Get("Content-Encoding") != "" || resp.Header.Get("Vary") != "Accept-Encoding" {
+		t.Errorf("unexpected headers for identity client %v", resp.Header)
+	}
+
+	// compression disabled
+	c.Config = newCompressionConfig(coCfg.Compression{})
+	req.Header.Set("Accept-Encoding", "gzip")
+	if resp, _ = c.Serve(req, newCompressibleResponse("content")); resp.Header.Get("Content-Encoding") != "" {
+		t.Errorf("expected no compression when disabled")
+	}
+}
+
+func TestCompressorDecode(t *testing.T) {
+	var wg sync.WaitGroup
+	c := NewCompressor(context.Background(), &wg, newCompressionConfig(coCfg.Compression{}), nil)
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte("plain text"))
+	zw.Close()
+	stored := newCompressibleResponse("")
+	stored.Body = io.NopCloser(bytes.NewReader(buf.Bytes()))
+	stored.Header.Set("Content-Encoding", "gzip")
+	stored.Header.Set("Content-Length", "30")
+
+	req := httptest.NewRequest(http.MethodGet, "http://abc.com/index.html", nil)
+	resp, err := c.Serve(req, stored)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if resp.Header.Get("Content-Encoding") != "" || resp.Header.Get("Content-Length") != "" {
+		t.Errorf("unexpected headers for decoded response %v", resp.Header)
+	}
+	if body, _ := io.ReadAll(resp.Body); string(body) != "plain text" {
+		t.Errorf("unexpected decoded content %q", body)
+	}
+
+	// a client accepting gzip gets the stored copy as is
+	stored.Body = io.NopCloser(bytes.NewReader(buf.Bytes()))
+	req.Header.Set("Accept-Encoding", "gzip")
+	if resp, _ = c.Serve(req, stored); resp != stored {
+		t.Errorf("expected stored gzip copy to pass through")
+	}
+}
+
+func TestCompressorRepresentation(t *testing.T) {
+	var wg sync.WaitGroup
+	store := &recordingStore{}
+	c := NewCompressor(context.Background(), &wg, newCompressionConfig(coCfg.Compression{Enabled: true}), store)
+
+	req := httptest.NewRequest(http.MethodGet, "http://abc.com/index.html", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	shared := newCompressibleResponse("content")
+	resp := c.Representation(req, shared)
+	if resp.Header.Get("Content-Encoding") != "gzip" || resp.Header.Get("ETag") != `W/"v1"` || resp.Header.Get("Vary") != "Accept-Encoding" {
+		t.Errorf("unexpected headers %v", resp.Header)
+	}
+	if resp.Body != shared.Body || shared.Header.Get("Content-Encoding") != "" {
+		t.Errorf("the response must be left as is")
+	}
+
+	// a client holding the compressed copy is answered 304 without any compression
+	req.Header.Set("If-None-Match", `W/"v1"`)
+	if answered := ServeConditional(req, c.Representation(req, shared)); answered.StatusCode != http.StatusNotModified || answered.Header.Get("ETag") != `W/"v1"` {
+		t.Errorf("expected 304 for the compressed copy, got %d %v", answered.StatusCode, answered.Header)
+	}
+	wg.Wait()
+	if len(store.reqs) != 0 {
+		t.Errorf("expected nothing compressed nor stored, got %d store requests", len(store.reqs))
+	}
+
+	// the identity copy of a client without Accept-Encoding keeps its strong ETag
+	req = httptest.NewRequest(http.MethodGet, "http://abc.com/index.html", nil)
+	if resp = c.Representation(req, newCompressibleResponse("content")); resp.Header.Get("ETag") != `"v1"` || resp.Header.Get("Vary") != "Accept-Encoding" {
+		t.Errorf("unexpected headers for identity client %v", resp.Header)
+	}
+}
@@ -0,0 +1,153 @@
+package frontend
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// headers sent along with 304 Not Modified (RFC 9110 section 15.4.5)
+var notModifiedHeaders = []string{
+	"Cache-Control",
+	"Content-Location",
+	"Date",
+	"ETag",
+	"Expires",
+	"Last-Modified",
+	"Vary",
+}
+
+// IsConditional reports whether the request carries any precondition header
+func IsConditional(req *http.Request) bool {
+	return req.Header.Get("If-Match") != "" ||
+		req.Header.Get("If-None-Match") != "" ||
+		req.Header.Get("If-Modified-Since") != "" ||
+		req.Header.Get("If-Unmodified-Since") != ""
+}
+
+// splitETags splits a comma separated list of entity tags, e.g. `"a", W/"b"`
+func splitETags(list string) []string {
+	var tags []string
+	for _, tag := range strings.Split(list, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// etagMatches compares etag against every tag of the list. Weak comparison ignores
+// the W/ prefix, strong comparison never matches a weak tag.
+func etagMatches(list string, etag string, weak bool) bool {
+	if etag == "" {
+		return false
+	}
+	for _, tag := range splitETags(list) {
+		if tag == "*" {
+			return true
+		}
+		if weak {
+			if strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		} else if !strings.HasPrefix(tag, "W/") && !strings.HasPrefix(etag, "W/") && tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// modifiedSince reports whether lastModified is later than the date of the header.
+// ok is false when either date is missing or invalid, the precondition is then ignored.
+func modifiedSince(hdrDate string, lastModified string) (modified bool, ok bool) {
+	since, err := http.ParseTime(hdrDate)
+	if err != nil {
+		return false, false
+	}
+	lm, err := http.ParseTime(lastModified)
+	if err != nil {
+		return false, false
+	}
+	return lm.After(since), true
+}
+
+// EvaluatePreconditions evaluates the precondition headers of the request against the
+// validators of the selected representation in the order of RFC 9110 section 13.2.2.
+// It returns 0 if the request must be served normally, otherwise 304 or 412.
+func EvaluatePreconditions(req *http.Request, hdr http.Header) int {
+	etag := hdr.Get("ETag")
+	lastModified := hdr.Get("Last-Modified")
+	isGetHead := req.Method == http.MethodGet || req.Method == http.MethodHead
+
+	if im := req.Header.Get("If-Match"); im != "" {
+		if strings.TrimSpace(im) != "*" && !etagMatches(im, etag, false) {
+			return http.StatusPreconditionFailed
+		}
+	} else if ius := req.Header.Get("If-Unmodified-Since"); ius != "" {
+		if modified, ok := modifiedSince(ius, lastModified); ok && modified {
+			return http.StatusPreconditionFailed
+		}
+	}
+
+	if inm := req.Header.Get("If-None-Match"); inm != "" {
+		if strings.TrimSpace(inm) == "*" || etagMatches(inm, etag, true) {
+			if isGetHead {
+				return http.StatusNotModified
+			}
+			return http.StatusPreconditionFailed
+		}
+	} else if ims := req.Header.Get("If-Modified-Since"); ims != "" && isGetHead {
+		if modified, ok := modifiedSince(ims, lastModified); ok && !modified {
+			return http.StatusNotModified
+		}
+	}
+	return 0
+}
+
+// ServeConditional answers the client preconditions from the headers of the cached (or freshly
+// fetched) response. The body is closed without being read when 304 or 412 is returned.
+// The response is returned unchanged if the request must be served normally.
+func ServeConditional(req *http.Request, resp *http.Response) *http.Response {
+	if resp == nil || resp.StatusCode != http.StatusOK || resp.Header == nil || !IsConditional(req) {
+		return resp
+	}
+	status := EvaluatePreconditions(req, resp.Header)
+	if status == 0 {
+		return resp
+	}
+	slog.Info("FE conditional.go : Precondition evaluated", "url", req.URL.String(), "status", status)
+
+	if resp.Body != nil {
+		resp.Body.Close()
+	}
+	ret := &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         resp.Proto,
+		ProtoMajor:    resp.ProtoMajor,
+		ProtoMinor:    resp.ProtoMinor,
+		Header:        make(http.Header),
+		Body:          http.NoBody,
+		ContentLength: 0,
+		Request:       resp.Request,
+	}
+	if status == http.StatusNotModified {
+		for _, key := range notModifiedHeaders {
+			if values := resp.Header.Values(key); len(values) > 0 {
+				ret.Header[http.CanonicalHeaderKey(key)] = values
+			}
+		}
+		if resp.Header.Get("X-Is-Cached") != "" {
+			ret.Header.Set("X-Is-Cached", resp.Header.Get("X-Is-Cached"))
+		}
+	} else {
+		ret.Header.Set("Content-Length", "0")
+	}
+	if ret.Header.Get("Date") == "" {
+		ret.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	}
+	return ret
+}
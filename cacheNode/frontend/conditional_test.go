@@ -0,0 +1,125 @@
+package frontend
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// trackingBody records whether the content was read or closed
+type trackingBody struct {
+	io.Reader
+	read, closed bool
+}
+
+func (b *trackingBody) Read(p []byte) (int, error) {
+	b.read = true
+	return b.Reader.Read(p)
+}
+
+func (b *trackingBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+func newCachedResponse() (*http.Response, *trackingBody) {
+	body := &trackingBody{Reader: strings.NewReader("cached content")}
+	resp := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     make(http.Header),
+		Body:       body,
+	}
+	resp.Header.Set("ETag", `"abc"`)
+	resp.Header.Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+	resp.Header.Set("Cache-Control", "max-age=60")
+	resp.Header.Set("Content-Type", "text/plain")
+	return resp, body
+}
+
+func TestEvaluatePreconditions(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		hdrs   map[string]string
+		want   int
+	}{
+		{"No preconditions", http.MethodGet, nil, 0},
+		{"If-None-Match hit", http.MethodGet, map[string]string{"If-None-Match": `"xyz", "abc"`}, http.StatusNotModified},
+		{"If-None-Match weak hit", http.MethodGet, map[string]string{"If-None-Match": `W/"abc"`}, http.StatusNotModified},
+		{"If-None-Match miss", http.MethodGet, map[string]string{"If-None-Match": `"xyz"`}, 0},
+		{"If-None-Match star", http.MethodHead, map[string]string{"If-None-Match": "*"}, http.StatusNotModified},
+		{"If-None-Match on POST", http.MethodPost, map[string]string{"If-None-Match": `"abc"`}, http.StatusPreconditionFailed},
+		{"If-Modified-Since not modified", http.MethodGet, map[string]string{"If-Modified-Since": "Mon, 02 Jan 2006 15:04:05 GMT"}, http.StatusNotModified},
+		{"If-Modified-Since modified", http.MethodGet, map[string]string{"If-Modified-Since": "Sun, 01 Jan 2006 15:04:05 GMT"}, 0},
+		{"If-Modified-Since ignored with If-None-Match", http.MethodGet, map[string]string{"If-None-Match": `"xyz"`, "If-Modified-Since": "Mon, 02 Jan 2006 15:04:05 GMT"}, 0},
+		{"If-Modified-Since invalid date", http.MethodGet, map[string]string{"If-Modified-Since": "yesterday"}, 0},
+		{"If-Match hit", http.MethodGet, map[string]string{"If-Match": `"abc"`}, 0},
+		{"If-Match weak never matches", http.MethodGet, map[string]string{"If-Match": `W/"abc"`}, http.StatusPreconditionFailed},
+		{"If-Match miss", http.MethodGet, map[string]string{"If-Match": `"xyz"`}, http.StatusPreconditionFailed},
+		{"If-Unmodified-Since modified", http.MethodGet, map[string]string{"If-Unmodified-Since": "Sun, 01 Jan 2006 15:04:05 GMT"}, http.StatusPreconditionFailed},
+		{"If-Unmodified-Since not modified", http.MethodGet, map[string]string{"If-Unmodified-Since": "Tue, 03 Jan 2006 15:04:05 GMT"}, 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, "http://example.com/a.html", nil)
+			for k, v := range tc.hdrs {
+				req.Header.Set(k, v)
+			}
+			resp, _ := newCachedResponse()
+			if got := EvaluatePreconditions(req, resp.Header); got != tc.want {
+				t.Errorf("expected %d, got %d", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestServeConditional(t *testing.T) {
+	t.Run("304 does not read the body", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "http://example.com/a.html", nil)
+		req.Header.Set("If-None-Match", `"abc"`)
+		resp, body := newCachedResponse()
+
+		got := ServeConditional(req, resp)
+		if got.StatusCode != http.StatusNotModified {
+			t.Fatalf("expected 304, got %d", got.StatusCode)
+		}
+		if body.read || !body.closed {
+			t.Errorf("expected body closed without being read, read=%v closed=%v", body.read, body.closed)
+		}
+		if got.Header.Get("ETag") != `"abc"` || got.Header.Get("Cache-Control") != "max-age=60" {
+			t.Errorf("expected validators and Cache-Control on 304, got %v", got.Header)
+		}
+		if got.Header.Get("Content-Type") != "" {
+			t.Errorf("unexpected Content-Type on 304")
+		}
+	})
+
+	t.Run("412 on If-Match failure", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "http://example.com/a.html", nil)
+		req.Header.Set("If-Match", `"xyz"`)
+		resp, _ := newCachedResponse()
+		if got := ServeConditional(req, resp); got.StatusCode != http.StatusPreconditionFailed {
+			t.Fatalf("expected 412, got %d", got.StatusCode)
+		}
+	})
+
+	t.Run("Unconditional request passes through", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "http://example.com/a.html", nil)
+		resp, _ := newCachedResponse()
+		if got := ServeConditional(req, resp); got != resp {
+			t.Fatalf("expected the response to be returned unchanged")
+		}
+	})
+
+	t.Run("Error responses are not evaluated", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "http://example.com/a.html", nil)
+		req.Header.Set("If-Match", `"xyz"`)
+		resp, _ := newCachedResponse()
+		resp.StatusCode = http.StatusNotFound
+		if got := ServeConditional(req, resp); got != resp {
+			t.Fatalf("expected the response to be returned unchanged")
+		}
+	})
+}
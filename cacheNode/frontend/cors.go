@@ -0,0 +1,163 @@
+package frontend
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hcl/cdn/cacheNode/cachePolicy"
+	coCfg "github.com/hcl/cdn/common/config"
+)
+
+// methods allowed when the policy does not list them
+var defaultCORSMethods = []string{http.MethodGet, http.MethodHead}
+
+// matchOrigin reports if the origin is allowed by one of the patterns, * matches any part of the origin
+func matchOrigin(patterns []string, origin string) bool {
+	origin = strings.ToLower(origin)
+	for _, pattern := range patterns {
+		if wildcardMatch(strings.ToLower(pattern), origin) {
+			return true
+		}
+	}
+	return false
+}
+
+func wildcardMatch(pattern string, s string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == s
+	}
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(s, part)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(part):]
+	}
+	return strings.HasSuffix(s, parts[len(parts)-1])
+}
+
+// corsPolicy returns the enabled CORS policy of the DS of the request, nil on Mid nodes,
+// the edge answers the browsers
+func (l *Listener) corsPolicy(req *http.Request) *coCfg.CORS {
+	if l.cfg.NodeType() == coCfg.CacheNodeMid {
+		return nil
+	}
+	configDS, err := l.cfg.DSLookup(req)
+	if err != nil || !configDS.CORS.Enabled {
+		return nil
+	}
+	return &configDS.CORS
+}
+
+// allowOrigin is the Access-Control-Allow-Origin of the allowed origin, * when any origin is allowed
+// without credentials
+func allowOrigin(policy *coCfg.CORS, origin string) string {
+	if !policy.AllowCredentials {
+		for _, pattern := range policy.AllowOrigins {
+			if pattern == "*" {
+				return "*"
+			}
+		}
+	}
+	return origin
+}
+
+// preflight answers the CORS preflight of the browser, it returns nil for the other requests
+func (l *Listener) preflight(req *http.Request) *http.Response {
+	if req.Method != http.MethodOptions || req.Header.Get("Origin") == "" || req.Header.Get("Access-Control-Request-Method") == "" {
+		return nil
+	}
+	policy := l.corsPolicy(req)
+	if policy == nil {
+		return nil
+	}
+	origin := req.Header.Get("Origin")
+	method := req.Header.Get("Access-Control-Request-Method")
+	methods := policy.AllowMethods
+	if len(methods) == 0 {
+		methods = defaultCORSMethods
+	}
+	methodAllowed := false
+	for _, m := range methods {
+		if strings.EqualFold(m, method) {
+			methodAllowed = true
+			break
+		}
+	}
+	if !matchOrigin(policy.AllowOrigins, origin) || !methodAllowed {
+		slog.Info("FE cors.go : Preflight denied", "origin", origin, "method", method)
+		return forbidden()
+	}
+
+	status := http.StatusNoContent
+	resp := &http.Response{
+		StatusCode: status,
+		Status:     fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		Header:     make(http.Header),
+		Body:       http.NoBody,
+	}
+	resp.Header.Set("Access-Control-Allow-Origin", allowOrigin(policy, origin))
+	resp.Header.Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+	if requested := req.Header.Get("Access-Control-Request-Headers"); requested != "" && len(policy.AllowHeaders) > 0 {
+		if len(policy.AllowHeaders) == 1 && policy.AllowHeaders[0] == "*" {
+			resp.Header.Set("Access-Control-Allow-Headers", requested)
+		} else {
+			resp.Header.Set("Access-Control-Allow-Headers", strings.Join(policy.AllowHeaders, ", "))
+		}
+	}
+	if policy.AllowCredentials {
+		resp.Header.Set("Access-Control-Allow-Credentials", "true")
+	}
+	if policy.MaxAge > 0 {
+		resp.Header.Set("Access-Control-Max-Age", strconv.Itoa(policy.MaxAge))
+	}
+	resp.Header.Set("Vary", "Origin, Access-Control-Request-Method, Access-Control-Request-Headers")
+	resp.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	resp.Header.Set("Content-Length", "0")
+	return resp
+}
+
+// addCORSHeaders replaces the Access-Control headers of the response by the ones of the policy for the
+// Origin of the client. The stored copy is shared by all the Origins, the header is copied.
+func (l *Listener) addCORSHeaders(req *http.Request, resp *http.Response) *http.Response {
+	policy := l.corsPolicy(req)
+	if policy == nil {
+		return resp
+	}
+	ret := *resp
+	ret.Header = resp.Header.Clone()
+	for key := range ret.Header {
+		if strings.HasPrefix(key, "Access-Control-") {
+			ret.Header.Del(key)
+		}
+	}
+	acao := allowOrigin(policy, "")
+	if acao != "*" {
+		// the response differs per Origin for the caches downstream
+		cachePolicy.AddVary(ret.Header, "Origin")
+	}
+	origin := req.Header.Get("Origin")
+	if origin == "" || !matchOrigin(policy.AllowOrigins, origin) {
+		return &ret
+	}
+	if acao == "" {
+		acao = origin
+	}
+	ret.Header.Set("Access-Control-Allow-Origin", acao)
+	if policy.AllowCredentials {
+		ret.Header.Set("Access-Control-Allow-Credentials", "true")
+	}
+	if len(policy.ExposeHeaders) > 0 {
+		ret.Header.Set("Access-Control-Expose-Headers", strings.Join(policy.ExposeHeaders, ", "))
+	}
+	return &ret
+}
@@ -0,0 +1,126 @@
+package frontend
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hcl/cdn/cacheNode/config"
+	coCfg "github.com/hcl/cdn/common/config"
+)
+
+func newCORSListener(cors coCfg.CORS) *Listener {
+	return &Listener{
+		cfg: &config.RunConfig{
+			Node: &coCfg.CacheNode{Type: coCfg.CacheNodeEdge},
+			ServiceList: &coCfg.DeliveryServices{ServiceList: []coCfg.DeliveryService{
+				{Name: "abc", ClientURL: "http://abc.com", OriginURL: "http://origin.abc.com", CORS: cors},
+			}},
+		},
+	}
+}
+
+func TestMatchOrigin(t *testing.T) {
+	patterns := []string{"https://*.example.com", "http://localhost:*", "https://app.test"}
+	for origin, want := range map[string]bool{
+		"https://www.example.com":   true,
+		"https://a.b.EXAMPLE.com":   true,
+		"https://example.com":       false,
+		"http://www.example.com":    false,
+		"https://evil.com":          false,
+		"http://localhost:3000":     true,
+		"https://app.test":          true,
+		"https://app.test.evil.com": false,
+	} {
+		if got := matchOrigin(patterns, origin); got != want {
+			t.Errorf("%s: got %v, want %v", origin, got, want)
+		}
+	}
+}
+
+func TestPreflight(t *testing.T) {
+	l := newCORSListener(coCfg.CORS{
+		Enabled:          true,
+		AllowOrigins:     []string{"https://*.example.com"},
+		AllowMethods:     []string{"GET", "POST"},
+		AllowHeaders:     []string{"Content-Type", "Authorization"},
+		AllowCredentials: true,
+		MaxAge:           600,
+	})
+	preflight := func(origin, method string) *http.Response {
+		req := httptest.NewRequest(http.MethodOptions, "http://abc.com/api/items", nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", method)
+		req.Header.Set("Access-Control-Request-Headers", "content-type")
+		return l.preflight(req)
+	}
+
+	resp := preflight("https://www.example.com", "POST")
+	if resp == nil || resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected 204, got %v", resp)
+	}
+	for name, want := range map[string]string{
+		"Access-Control-Allow-Origin":      "https://www.example.com",
+		"Access-Control-Allow-Methods":     "GET, POST",
+		"Access-Control-Allow-Headers":     "Content-Type, Authorization",
+		"Access-Control-Allow-Credentials": "true",
+		"Access-Control-Max-Age":           "600",
+	} {
+		if got := resp.Header.Get(name); got != want {
+			t.Errorf("%s: got %q, want %q", name, got, want)
+		}
+	}
+	if resp := preflight("https://evil.com", "GET"); resp == nil || resp.StatusCode != http.StatusForbidden {
+		t.Errorf("expected the origin to be denied, got %v", resp)
+	}
+	if resp := preflight("https://www.example.com", "DELETE"); resp == nil || resp.StatusCode != http.StatusForbidden {
+		t.Errorf("expected the method to be denied, got %v", resp)
+	}
+
+	// not a preflight
+	req := httptest.NewRequest(http.MethodOptions, "http://abc.com/api/items", nil)
+	if l.preflight(req) != nil {
+		t.Error("expected plain OPTIONS to go to the origin")
+	}
+}
+
+func TestAddCORSHeaders(t *testing.T) {
+	shared := &http.Response{StatusCode: http.StatusOK, Header: make(http.Header)}
+	shared.Header.Set("Access-Control-Allow-Origin", "https://origin-set.com")
+	shared.Header.Set("Content-Type", "font/woff2")
+
+	l := newCORSListener(coCfg.CORS{Enabled: true, AllowOrigins: []string{"https://*.example.com"}, ExposeHeaders: []string{"X-Request-Id"}})
+	for origin, want := range map[string]string{
+		"https://www.example.com":  "https://www.example.com",
+		"https://shop.example.com": "https://shop.example.com",
+		"https://evil.com":         "",
+		"":                         "",
+	} {
+		req := httptest.NewRequest(http.MethodGet, "http://abc.com/font.woff2", nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		resp := l.addCORSHeaders(req, shared)
+		if got := resp.Header.Get("Access-Control-Allow-Origin"); got != want {
+			t.Errorf("%q: got %q, want %q", origin, got, want)
+		}
+		if got := resp.Header.Get("Vary"); got != "Origin" {
+			t.Errorf("%q: expected Vary Origin, got %q", origin, got)
+		}
+		if want != "" && resp.Header.Get("Access-Control-Expose-Headers") != "X-Request-Id" {
+			t.Errorf("%q: expected exposed headers", origin)
+		}
+	}
+	if shared.Header.Get("Access-Control-Allow-Origin") != "https://origin-set.com" || shared.Header.Get("Vary") != "" {
+		t.Errorf("the shared response must not be changed, got %v", shared.Header)
+	}
+
+	// any origin without credentials
+	l = newCORSListener(coCfg.CORS{Enabled: true, AllowOrigins: []string{"*"}})
+	req := httptest.NewRequest(http.MethodGet, "http://abc.com/font.woff2", nil)
+	req.Header.Set("Origin", "https://any.com")
+	resp := l.addCORSHeaders(req, shared)
+	if resp.Header.Get("Access-Control-Allow-Origin") != "*" || resp.Header.Get("Vary") != "" {
+		t.Errorf("expected * without Vary, got %v", resp.Header)
+	}
+}
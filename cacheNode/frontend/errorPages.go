@@ -0,0 +1,135 @@
+package frontend
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	coCfg "github.com/hcl/cdn/common/config"
+	"github.com/hcl/cdn/common/helper"
+)
+
+// content type of the inline pages which do not give one
+const defaultErrorPageType = "text/html; charset=utf-8"
+
+// headers describing the replaced body
+var errorBodyHeaders = []string{"Content-Encoding", "Content-Length", "Content-Type", "Content-Range", "Accept-Ranges", "ETag", "Last-Modified"}
+
+// findErrorPage returns the page of the status, a page of the status code wins over a page of its class
+func findErrorPage(pages []coCfg.ErrorPage, status int) *coCfg.ErrorPage {
+	code := strconv.Itoa(status)
+	class := code[:1] + "xx"
+	var classPage *coCfg.ErrorPage
+	for i := range pages {
+		switch strings.ToLower(pages[i].Status) {
+		case code:
+			return &pages[i]
+		case class:
+			if classPage == nil {
+				classPage = &pages[i]
+			}
+		}
+	}
+	return classPage
+}
+
+// fetchErrorPage gets the page of the DS through the Collapser, it is cached like the content
+func (l *Listener) fetchErrorPage(req *http.Request, ds *coCfg.DeliveryService, page *coCfg.ErrorPage) *http.Response {
+	clientURL, err := url.Parse(ds.ClientURL)
+	if err != nil || l.NextStep == nil {
+		return nil
+	}
+	pageURL := *clientURL
+	pageURL.Path = ""
+	pageURL.RawPath = ""
+	pageURL.RawQuery = ""
+	ref, err := url.Parse(page.URL)
+	if err != nil {
+		return nil
+	}
+	pageReq, err := http.NewRequestWithContext(req.Context(), http.MethodGet, pageURL.ResolveReference(ref).String(), nil)
+	if err != nil {
+		return nil
+	}
+	pageReq.RemoteAddr = req.RemoteAddr
+	resp, err := l.NextStep.Do(pageReq)
+	if err != nil {
+		slog.Error("FE errorPages.go : Failed to fetch error page", "url", helper.GetString(pageReq), "error", err.Error())
+		return nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		slog.Error("FE errorPages.go : Failed to fetch error page", "url", helper.GetString(pageReq), "status", resp.StatusCode)
+		if resp.Body != nil {
+			resp.Body.Close()
+		}
+		return nil
+	}
+	return resp
+}
+
+// errorPage replaces the body of the error response by the page of the DS, or of the node when no
+// DS matches or the DS has none for the status. Mid nodes leave the pages to the edge.
+func (l *Listener) errorPage(req *http.Request, resp *http.Response) *http.Response {
+	if resp.StatusCode < http.StatusBadRequest || l.cfg == nil || l.cfg.NodeType() == coCfg.CacheNodeMid {
+		return resp
+	}
+	var page *coCfg.ErrorPage
+	var body io.ReadCloser
+	contentType := ""
+	length := int64(-1)
+
+	configDS, err := l.cfg.DSLookup(req)
+	if err == nil {
+		page = findErrorPage(configDS.ErrorPages, resp.StatusCode)
+	}
+	if page != nil && page.URL != "" {
+		if pageResp := l.fetchErrorPage(req, configDS, page); pageResp != nil {
+			body = pageResp.Body
+			contentType = pageResp.Header.Get("Content-Type")
+			length = pageResp.ContentLength
+			if pageResp.Header.Get("Content-Length") == "" {
+				length = -1
+			}
+		}
+	}
+	if page == nil && l.cfg.Node != nil {
+		page = findErrorPage(l.cfg.Node.ErrorPages, resp.StatusCode)
+	}
+	if page == nil {
+		return resp
+	}
+	if body == nil {
+		if page.Content == "" {
+			return resp
+		}
+		body = io.NopCloser(strings.NewReader(page.Content))
+		contentType = page.ContentType
+		if contentType == "" {
+			contentType = defaultErrorPageType
+		}
+		length = int64(len(page.Content))
+	}
+
+	if resp.Body != nil {
+		// collapsed requests read the body through their own readers
+		resp.Body.Close()
+	}
+	ret := *resp
+	ret.Header = resp.Header.Clone()
+	for _, hdr := range errorBodyHeaders {
+		ret.Header.Del(hdr)
+	}
+	if contentType != "" {
+		ret.Header.Set("Content-Type", contentType)
+	}
+	ret.ContentLength = length
+	if length >= 0 {
+		ret.Header.Set("Content-Length", strconv.FormatInt(length, 10))
+	}
+	ret.Body = body
+	slog.Info("FE errorPages.go : Serving error page", "url", helper.GetString(req), "status", resp.StatusCode)
+	return &ret
+}
@@ -0,0 +1,101 @@
+package frontend
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hcl/cdn/cacheNode/config"
+	coCfg "github.com/hcl/cdn/common/config"
+)
+
+// pageOrigin serves the error pages of the DS, nothing is in storage
+type pageOrigin struct {
+	paths []string
+}
+
+func (o *pageOrigin) Do(req *http.Request) (*http.Response, error) {
+	o.paths = append(o.paths, req.URL.Path)
+	if req.URL.Path != "/errors/404.html" {
+		return &http.Response{StatusCode: http.StatusNotFound, Header: make(http.Header), Body: http.NoBody}, nil
+	}
+	resp := &http.Response{StatusCode: http.StatusOK, Header: make(http.Header), Body: io.NopCloser(strings.NewReader("<h1>Lost?</h1>")), ContentLength: 14}
+	resp.Header.Set("Content-Type", "text/html")
+	resp.Header.Set("Content-Length", "14")
+	return resp, nil
+}
+
+func (o *pageOrigin) ReDo(req *http.Request, oldResp *http.Response) (*http.Response, error) {
+	return o.Do(req)
+}
+
+type emptyStore struct{}
+
+func (emptyStore) Do(req *http.Request) (*http.Response, error) {
+	return &http.Response{StatusCode: http.StatusNotFound, Header: make(http.Header), Body: http.NoBody}, nil
+}
+
+func errorResponse(status int, body string) *http.Response {
+	resp := &http.Response{StatusCode: status, Header: make(http.Header), Body: io.NopCloser(strings.NewReader(body)), ContentLength: int64(len(body))}
+	resp.Header.Set("Content-Type", "text/plain")
+	resp.Header.Set("Retry-After", "5")
+	return resp
+}
+
+func TestErrorPage(t *testing.T) {
+	origin := &pageOrigin{}
+	l := &Listener{
+		cfg: &config.RunConfig{
+			Node: &coCfg.CacheNode{Type: coCfg.CacheNodeEdge, ErrorPages: []coCfg.ErrorPage{
+				{Status: "4xx", Content: `{"error":"unavailable"}`, ContentType: "application/json"},
+			}},
+			ServiceList: &coCfg.DeliveryServices{ServiceList: []coCfg.DeliveryService{
+				{Name: "abc", ClientURL: "http://abc.com", OriginURL: "http://origin.abc.com", ErrorPages: []coCfg.ErrorPage{
+					{Status: "5xx", Content: "<h1>Back soon</h1>"},
+					{Status: "503", Content: "<h1>Maintenance</h1>"},
+					{Status: "404", URL: "/errors/404.html"},
+					{Status: "403", URL: "/errors/missing.html", Content: "<h1>Denied</h1>"},
+				}},
+			}},
+		},
+		NextStep: NewCollapser(&Finder{StoragePath: emptyStore{}, BackendPath: origin}),
+	}
+	cases := []struct {
+		url         string
+		status      int
+		body        string
+		contentType string
+	}{
+		{"http://abc.com/a.js", http.StatusInternalServerError, "<h1>Back soon</h1>", defaultErrorPageType},
+		{"http://abc.com/a.js", http.StatusServiceUnavailable, "<h1>Maintenance</h1>", defaultErrorPageType},
+		{"http://abc.com/a.js", http.StatusNotFound, "<h1>Lost?</h1>", "text/html"},
+		{"http://abc.com/a.js", http.StatusForbidden, "<h1>Denied</h1>", defaultErrorPageType},
+		{"http://abc.com/a.js", http.StatusTooManyRequests, `{"error":"unavailable"}`, "application/json"},
+		{"http://unknown.com/a.js", http.StatusNotFound, `{"error":"unavailable"}`, "application/json"},
+		{"http://unknown.com/a.js", http.StatusBadGateway, "bare", "text/plain"},
+		{"http://abc.com/a.js", http.StatusOK, "bare", "text/plain"},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodGet, c.url, nil)
+		resp := l.errorPage(req, errorResponse(c.status, "bare"))
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != c.status || string(body) != c.body || resp.Header.Get("Content-Type") != c.contentType {
+			t.Errorf("%s %d: got %d %q %q", c.url, c.status, resp.StatusCode, body, resp.Header.Get("Content-Type"))
+		}
+		if c.body != "bare" && (resp.ContentLength != int64(len(c.body)) || resp.Header.Get("Retry-After") != "5") {
+			t.Errorf("%s %d: unexpected headers %v", c.url, c.status, resp.Header)
+		}
+	}
+	if strings.Join(origin.paths, ",") != "/errors/404.html,/errors/missing.html" {
+		t.Errorf("unexpected error page requests %v", origin.paths)
+	}
+
+	// the edge serves the pages
+	l.cfg.Node.Type = coCfg.CacheNodeMid
+	req := httptest.NewRequest(http.MethodGet, "http://abc.com/a.js", nil)
+	if resp := l.errorPage(req, errorResponse(http.StatusInternalServerError, "bare")); resp.Header.Get("Content-Type") != "text/plain" {
+		t.Error("expected the mid to keep the error body")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"log/slog"
 	"strings"
 	"errors"
+	"github.com/hcl/cdn/cacheNode/cachePolicy"
 	"github.com/hcl/cdn/cacheNode/common"
 	"io"
 )
@@ -57,6 +58,10 @@ func (f Finder) Do(req *http.Request) (*http.Response, error) {
 			return stRsp, nil
 
 		case http.StatusNotFound:
+			if cachePolicy.OnlyIfCached(req) {
+				slog.Info("FE finder.go : Resource not found in storage and only-if-cached requested")
+				return GatewayTimeout(req), nil
+			}
 			slog.Info("FE finder.go : Resource not found in storage, Calling Backend.Do()")
 			respB, err := f.BackendPath.Do(req)
 			if err != nil {
@@ -0,0 +1,157 @@
+package frontend
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"log/slog"
+	"net/netip"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// interval between the checks of the database file for changes
+const geoIPReloadInterval = 30 * time.Second
+
+// ipRange maps the addresses from start to end (both included) to a country
+type ipRange struct {
+	start   netip.Addr
+	end     netip.Addr
+	country string
+}
+
+/*
+ * GeoIPDB resolves the country of the client IP from a local CSV database, one range per line
+ *		start_ip,end_ip,country			e.g. 1.0.0.0,1.0.0.255,AU
+ * IPv4 & IPv6 ranges may be mixed, lines starting with # and invalid lines are skipped.
+ * The file is loaded again when its modification time changes, a failed load keeps the ranges in use.
+ */
+type GeoIPDB struct {
+	file    string
+	mu      sync.RWMutex
+	ranges  []ipRange // sorted by start
+	modTime time.Time
+	missing bool // the missing file is reported once
+}
+
+// NewGeoIPDB loads the database and watches the file for changes until the context is done.
+// An empty file name disables the lookup, every address resolves to "".
+func NewGeoIPDB(ctx context.Context, wg *sync.WaitGroup, file string) *GeoIPDB {
+	db := &GeoIPDB{file: file}
+	if file == "" {
+		return db
+	}
+	db.reload()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(geoIPReloadInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				db.reload()
+			}
+		}
+	}()
+	return db
+}
+
+// reload loads the database file when it changed since the last load
+func (db *GeoIPDB) reload() {
+	info, err := os.Stat(db.file)
+	if err != nil {
+		if !db.missing {
+			slog.Error("FE geoIP.go : GeoIP database not available", "file", db.file, "error", err.Error())
+			db.missing = true
+		}
+		return
+	}
+	db.missing = false
+	db.mu.RLock()
+	unchanged := info.ModTime().Equal(db.modTime)
+	db.mu.RUnlock()
+	if unchanged {
+		return
+	}
+
+	ranges, err := loadIPRanges(db.file)
+	if err != nil {
+		slog.Error("FE geoIP.go : Failed to load GeoIP database", "file", db.file, "error", err.Error())
+		return
+	}
+	db.mu.Lock()
+	db.ranges = ranges
+	db.modTime = info.ModTime()
+	db.mu.Unlock()
+	slog.Info("FE geoIP.go : GeoIP database loaded", "file", db.file, "ranges", len(ranges))
+}
+
+func loadIPRanges(file string) ([]ipRange, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	rdr := csv.NewReader(f)
+	rdr.Comment = '#'
+	rdr.FieldsPerRecord = -1
+	rdr.TrimLeadingSpace = true
+	var ranges []ipRange
+	skipped := 0
+	for {
+		record, err := rdr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) < 3 {
+			skipped++
+			continue
+		}
+		start, err1 := netip.ParseAddr(strings.TrimSpace(record[0]))
+		end, err2 := netip.ParseAddr(strings.TrimSpace(record[1]))
+		country := strings.ToUpper(strings.TrimSpace(record[2]))
+		if err1 != nil || err2 != nil || start.Is4() != end.Is4() || end.Less(start) || country == "" {
+			// header line or invalid range
+			skipped++
+			continue
+		}
+		ranges = append(ranges, ipRange{start: start.Unmap(), end: end.Unmap(), country: country})
+	}
+	if skipped > 0 {
+		slog.Info("FE geoIP.go : Skipped GeoIP database lines", "file", file, "lines", skipped)
+	}
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].start.Less(ranges[j].start) })
+	return ranges, nil
+}
+
+// Country returns the ISO country code of the address, "" when it is not known
+func (db *GeoIPDB) Country(addr netip.Addr) string {
+	if db == nil {
+		return ""
+	}
+	addr = addr.Unmap()
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	// last range starting at or before addr
+	i := sort.Search(len(db.ranges), func(i int) bool { return addr.Less(db.ranges[i].start) }) - 1
+	if i < 0 {
+		return ""
+	}
+	r := db.ranges[i]
+	if r.start.Is4() != addr.Is4() || r.end.Less(addr) {
+		return ""
+	}
+	return r.country
+}
@@ -11,7 +11,7 @@ import (
 )
 
 // Initialize frontend and pass Stub Backend as a parameter
-func Init(ctx context.Context, wg *sync.WaitGroup, cnfg *config.RunConfig, backend common.CachedRequestHandler, storage common.RequestHandler, observabilityHandler observability.ObservabilityHandler) error {
+func Init(ctx context.Context, wg *sync.WaitGroup, cnfg *config.RunConfig, backend common.CachedRequestHandler, storage common.RequestHandler, observabilityHandler observability.ObservabilityHandler, geoIPFile string) error {
 	
 	slog.SetLogLoggerLevel(slog.LevelInfo)
 	slog.Info("FE init.go : Init() - Start")
@@ -29,6 +29,8 @@ func Init(ctx context.Context, wg *sync.WaitGroup, cnfg *config.RunConfig, backe
 		BackendRequest: backend, 
 		ExpiredCnt:     0,
 		UsableCnt:      0,
+		Config:         cnfg,
+		Refresher:      NewBackgroundRefresher(ctx, wg, backend),
 	}
 
 	slog.Info("FE init.go : Init() - Initializing frontend configuration with finder...")
@@ -47,6 +49,7 @@ func Init(ctx context.Context, wg *sync.WaitGroup, cnfg *config.RunConfig, backe
 	slog.Info("FE init.go : Init() - Initializing frontend configuration with collapser...")
 	// Initialize the Collapser
 	collapser := NewCollapser(&finder)
+	collapser.Config = cnfg
 
 	slog.Info("FE init.go : Init() - Initializing frontend listener...")
 
@@ -55,6 +58,9 @@ func Init(ctx context.Context, wg *sync.WaitGroup, cnfg *config.RunConfig, backe
 		NextStep:   collapser,
 		cfg:      cnfg,
 		feObs: observabilityHandler,
+		compressor: NewCompressor(ctx, wg, cnfg, storage),
+		geo:      NewGeoIPDB(ctx, wg, geoIPFile),
+		limiter:  NewRateLimiter(ctx, wg),
 	}
 
 	wg.Add(1)
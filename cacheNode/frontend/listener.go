@@ -2,11 +2,15 @@ package frontend
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"net"
 	"net/http"
+	"net/url"
+	"strconv"
 	"sync"
 	"strings"
 	"time"
@@ -14,12 +18,18 @@ import (
 	"github.com/hcl/cdn/cacheNode/config"
 	"github.com/hcl/cdn/common/helper"
 	"github.com/hcl/cdn/cacheNode/observability"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 )
 
 type Listener struct {
 	NextStep	 *Collapser
 	cfg      *config.RunConfig
 	feObs observability.ObservabilityHandler
+	certs    *CertStore
+	compressor *Compressor
+	geo      *GeoIPDB
+	limiter  *RateLimiter
 }
 
 // SetConfigFile sets the configuration for the listener
@@ -31,6 +41,8 @@ func (l *Listener) SetConfigFile(cfgIp *config.RunConfig) {
 // SendResponseToClient sends the response to the client
 func (l *Listener) SendResponseToClient(respW http.ResponseWriter, rsp *http.Response, req *http.Request) error {
 	slog.Info(fmt.Sprintf("FE listener.go : SendResponseToClient() for URL: %s", req.URL.String()))
+	// Error pages of the DS or the node
+	rsp = l.errorPage(req, rsp)
 
 	// Add headers to the ResponseWriter
 	for key, values := range rsp.Header {
@@ -78,6 +90,45 @@ func (l *Listener) IsMatchingClientUrlAvailable(req *http.Request) (bool, error)
 	return true, nil
 }
 
+// httpsRedirect redirects plain HTTP clients of a DS with RedirectToHTTPS to the HTTPS listener,
+// it returns nil when the request is served as is
+func (l *Listener) httpsRedirect(req *http.Request) *http.Response {
+	tlsPort := l.cfg.BindingTLSPort()
+	if req.TLS != nil || tlsPort == 0 {
+		return nil
+	}
+	configDS, err := l.cfg.DSLookup(req)
+	if err != nil || !configDS.TLS.RedirectToHTTPS {
+		return nil
+	}
+
+	host := req.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if tlsPort != 443 {
+		host = net.JoinHostPort(host, strconv.Itoa(tlsPort))
+	}
+	location := url.URL{Scheme: "https", Host: host, Path: req.URL.Path, RawQuery: req.URL.RawQuery}
+
+	// 308 keeps the method and body of non GET requests
+	status := http.StatusMovedPermanently
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		status = http.StatusPermanentRedirect
+	}
+	resp := &http.Response{
+		StatusCode: status,
+		Status:     fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		Header:     make(http.Header),
+		Body:       http.NoBody,
+	}
+	resp.Header.Set("Location", location.String())
+	resp.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	resp.Header.Set("Content-Length", "0")
+	slog.Info("FE listener.go : Redirecting client to HTTPS", "location", location.String())
+	return resp
+}
+
 func (l *Listener) reformat(req *http.Request) {
 	if req.URL.Host == "" {
 		req.URL.Host = req.Host
@@ -90,7 +141,7 @@ func (l *Listener) reformat(req *http.Request) {
 // Records the diagnostics associated with the completion of the client request
 func RecordFrontendMetrics(clientIp string, urlStr string, 
 	userAgent string, responseTime int, bytes int, statusCode int, 
-	cacheHit bool, observabilityObj observability.ObservabilityHandler) {
+	cacheHit bool, protocol string, dsName string, denyReason string, collapse string, observabilityObj observability.ObservabilityHandler) {
 	
 	frontendEvent:= observability.FrontendEvent{
 		Timestamp : time.Now(),         //time of the event 
@@ -102,6 +153,10 @@ func RecordFrontendMetrics(clientIp string, urlStr string,
 		Bytes : bytes,  					//number of bytes of content
 		StatusCode : statusCode,  			//http response code to client 
 		CacheHit : cacheHit,  				// true or false
+		Protocol : protocol,				// negotiated protocol
+		DS : dsName,						// name of the Delivery Service
+		DenyReason : denyReason,			// why the request was denied, "" if not
+		Collapse : collapse,				// role of the request in collapsing, "" if not collapsed
 
 	}
 	if observabilityObj != nil {
@@ -126,6 +181,9 @@ func (l *Listener) HandleClientRequest(respW http.ResponseWriter, req *http.Requ
 	var sCode int
 	var bodyLen int
 	cHit := false
+	dsName := ""
+	denyReason := ""
+	collapse := ""
 	userAgent := req.Header.Get("User-Agent")
 	
 	defer func(){
@@ -133,7 +191,7 @@ func (l *Listener) HandleClientRequest(respW http.ResponseWriter, req *http.Requ
 		timeTaken := endTime.Sub(startTime)
 		responseTime:= int(timeTaken.Milliseconds())
 		statusCode := sCode
-		RecordFrontendMetrics(clientIP, urlStr, userAgent, responseTime, bodyLen, statusCode, cHit, l.feObs)
+		RecordFrontendMetrics(clientIP, urlStr, userAgent, responseTime, bodyLen, statusCode, cHit, req.Proto, dsName, denyReason, collapse, l.feObs)
 		slog.Info(fmt.Sprintf("FE listener.go : Time taken to complete the requested url: %s in %d milliseconds" , urlStr, int(timeTaken.Milliseconds())))
 	}()
 
@@ -175,11 +233,72 @@ func (l *Listener) HandleClientRequest(respW http.ResponseWriter, req *http.Requ
 	}
 
 	slog.Info(fmt.Sprintf("FE listener.go : Client URL found %v - NextStep.Do() method called", dsValid))
+	if configDS, err := l.cfg.DSLookup(req); err == nil {
+		dsName = configDS.Name
+	}
+	ensureRequestID(req)
+
+	// Admission control, the request holds a slot of the DS until it is served
+	release, rejected, reason := l.admit(req)
+	if rejected != nil {
+		sCode = rejected.StatusCode
+		bodyLen = int(rejected.ContentLength)
+		denyReason = reason
+		_ = l.SendResponseToClient(respW, rejected, req)
+		return
+	}
+	defer release()
+
+	// Access control lists, denied clients never reach the cache
+	if denied, reason := l.checkAccess(req); denied != nil {
+		sCode = denied.StatusCode
+		bodyLen = int(denied.ContentLength)
+		denyReason = reason
+		_ = l.SendResponseToClient(respW, denied, req)
+		return
+	}
+
+	// CORS preflights are answered by the edge
+	if answer := l.preflight(req); answer != nil {
+		sCode = answer.StatusCode
+		bodyLen = int(answer.ContentLength)
+		if answer.StatusCode == http.StatusForbidden {
+			denyReason = DenyReasonCORS
+		}
+		_ = l.SendResponseToClient(respW, answer, req)
+		return
+	}
+
+	if redirect := l.httpsRedirect(req); redirect != nil {
+		sCode = redirect.StatusCode
+		_ = l.SendResponseToClient(respW, redirect, req)
+		return
+	}
+
+	// Redirect rules of the DS are answered by the edge
+	if redirect := l.redirect(req); redirect != nil {
+		sCode = redirect.StatusCode
+		_ = l.SendResponseToClient(respW, redirect, req)
+		return
+	}
+
+	// Signed URLs, invalid tokens never reach the Collapser
+	if denied := l.authorize(req); denied != nil {
+		sCode = denied.StatusCode
+		bodyLen = int(denied.ContentLength)
+		denyReason = DenyReasonToken
+		_ = l.SendResponseToClient(respW, denied, req)
+		return
+	}
+
+	// URL rewrite rules, the cache key is computed from the rewritten URL
+	l.rewriteURL(req)
 
 	slog.Info("FE listener.go : Attempting NextStep.Do() - Calling the Collapser")
 	
 	// Send request to Collapser
-	resp, err := l.NextStep.Do(req)
+	var resp *http.Response
+	resp, collapse, err = l.NextStep.Fetch(req)
 
 	if err != nil {
 		errStr = errors.New("500: Internal Server Error")
@@ -205,6 +324,36 @@ func (l *Listener) HandleClientRequest(respW http.ResponseWriter, req *http.Requ
 		slog.Info("FE listener.go : cacheHit : False")
 	}
 
+	// Answer client preconditions (304/412) against the representation the client is served,
+	// the content is decoded or compressed only for a response with a body
+	represented := l.compressor.Representation(req, resp)
+	if answered := ServeConditional(req, represented); answered != represented {
+		resp = answered
+	} else {
+		// Content coding of the client, compressed or decoded
+		resp, err = l.compressor.Serve(req, resp)
+		if err != nil {
+			errStr = errors.New("500: Internal Server Error")
+			respErr.ContentLength = int64(len(errStr.Error()))
+			respErr.Body = io.NopCloser(strings.NewReader(errStr.Error()))
+
+			slog.Error("FE listener.go : Failed to adapt content coding for URL", "url", urlHostStr, "error", err.Error())
+			respErr.StatusCode = http.StatusInternalServerError
+			respErr.Status = "500 Internal Server Error"
+			sCode = respErr.StatusCode
+			bodyLen = int(respErr.ContentLength)
+			_ = l.SendResponseToClient(respW, respErr, req)
+			return
+		}
+	}
+
+	// Serve the requested byte ranges out of the full response
+	resp = ServeRange(req, resp)
+	// Access-Control headers of the client Origin
+	resp = l.addCORSHeaders(req, resp)
+	// Client phase header rewrite rules
+	resp = l.rewriteClientHeaders(req, resp)
+
 	sCode = resp.StatusCode
 	bodyLen = int(resp.ContentLength)
 	_ = l.SendResponseToClient(respW, resp, req)
@@ -247,9 +396,16 @@ func (l *Listener) ListenAndServe(ctx context.Context, wg *sync.WaitGroup, cfgIp
 	mux.HandleFunc("/", l.HandleClientRequest)
 	listenerAddr := fmt.Sprintf("%s:%d", IP, Port)
 
+	var handler http.Handler = mux
+	if cfgIp.H2C() {
+		// cleartext HTTP/2 from downstream caches, HTTP/1.1 clients are served as before
+		handler = h2c.NewHandler(mux, l.http2Server())
+		slog.Info("FE listener.go : Listener accepts h2c")
+	}
+
 	srvr := http.Server{
 		Addr:    listenerAddr,
-		Handler: mux,
+		Handler: handler,
 	}
 
 	slog.Info(fmt.Sprintf("FE listener.go : Listener will start listening on %v", srvr.Addr))
@@ -258,6 +414,11 @@ func (l *Listener) ListenAndServe(ctx context.Context, wg *sync.WaitGroup, cfgIp
 	// Run in the background and wait for cancellation signals
 	go l.WaitForCancellation(ctx, wg, &srvr)
 
+	if tlsPort := cfgIp.BindingTLSPort(); tlsPort != 0 {
+		wg.Add(1)
+		go l.ListenAndServeTLS(ctx, wg, fmt.Sprintf("%s:%d", IP, tlsPort), mux)
+	}
+
 	slog.Info("FE listener.go : Listener about to serve the client request")
 
 	err := srvr.ListenAndServe()
@@ -266,3 +427,43 @@ func (l *Listener) ListenAndServe(ctx context.Context, wg *sync.WaitGroup, cfgIp
 	}
 	slog.Info("FE listener.go : ListenAndServe() - End")
 }
+
+// http2Server returns the HTTP/2 settings of the client facing servers
+func (l *Listener) http2Server() *http2.Server {
+	return &http2.Server{
+		MaxConcurrentStreams: uint32(l.cfg.MaxConcurrentStreams()),
+	}
+}
+
+// ListenAndServeTLS starts the HTTPS server, the certificate is selected by SNI from the DS list
+func (l *Listener) ListenAndServeTLS(ctx context.Context, wg *sync.WaitGroup, listenerAddr string, handler http.Handler) {
+	defer wg.Done()
+	if l.certs == nil {
+		l.certs = NewCertStore(l.cfg)
+	}
+
+	srvr := http.Server{
+		Addr:    listenerAddr,
+		Handler: handler,
+		TLSConfig: &tls.Config{
+			GetCertificate: l.certs.GetCertificate,
+		},
+	}
+
+	slog.Info(fmt.Sprintf("FE listener.go : HTTPS Listener will start listening on %v", srvr.Addr))
+
+	// HTTP/2 is negotiated by ALPN, HTTP/1.1 is the fallback
+	if err := http2.ConfigureServer(&srvr, l.http2Server()); err != nil {
+		slog.Error("FE listener.go : Failed to configure HTTP/2", "error", err.Error())
+	}
+
+	wg.Add(1)
+	go l.WaitForCancellation(ctx, wg, &srvr)
+
+	// Certificates come from the TLSConfig, no files
+	err := srvr.ListenAndServeTLS("", "")
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("FE listener.go : HTTPS Server ran into an issue", "error", err.Error())
+	}
+	slog.Info("FE listener.go : ListenAndServeTLS() - End")
+}
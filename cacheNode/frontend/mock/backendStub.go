@@ -93,7 +93,7 @@ func (sb *MockBackend) Do(r *http.Request) (*http.Response, error) {
 }
 
 // ReDo method for retrying requests
-func (sbrd *StubBackend) ReDo(req *http.Request, oldResp *http.Response) (*http.Response, error) {
+func (sbrd *MockBackend) ReDo(req *http.Request, oldResp *http.Response) (*http.Response, error) {
 	isGet := true
 	sbrd.reDoCnt++
 	body := "ReRequest DO - Hello From Backend"
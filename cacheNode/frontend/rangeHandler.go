@@ -0,0 +1,344 @@
+package frontend
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	errInvalidRange       = errors.New("invalid range")
+	errNoOverlap          = errors.New("range does not overlap the content")
+	errRangeNotSequential = errors.New("range is behind the current stream position")
+)
+
+// byteRange is one satisfiable range of the content
+type byteRange struct {
+	start, length int64
+}
+
+func (r byteRange) contentRange(size int64) string {
+	return fmt.Sprintf("bytes %d-%d/%d", r.start, r.start+r.length-1, size)
+}
+
+func (r byteRange) mimeHeader(contentType string, size int64) textproto.MIMEHeader {
+	return textproto.MIMEHeader{
+		"Content-Range": {r.contentRange(size)},
+		"Content-Type":  {contentType},
+	}
+}
+
+// parseRange parses a Range header value ("bytes=0-99,200-") against the content size.
+// errInvalidRange means the header must be ignored, errNoOverlap means 416 must be sent.
+func parseRange(s string, size int64) ([]byteRange, error) {
+	const prefix = "bytes="
+	if !strings.HasPrefix(s, prefix) {
+		return nil, errInvalidRange
+	}
+	var ranges []byteRange
+	noOverlap := false
+	for _, spec := range strings.Split(s[len(prefix):], ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		startStr, endStr, ok := strings.Cut(spec, "-")
+		if !ok {
+			return nil, errInvalidRange
+		}
+		startStr, endStr = strings.TrimSpace(startStr), strings.TrimSpace(endStr)
+		var r byteRange
+		if startStr == "" {
+			// suffix range "-N" : last N bytes
+			n, err := strconv.ParseInt(endStr, 10, 64)
+			if err != nil || n < 0 {
+				return nil, errInvalidRange
+			}
+			if n == 0 {
+				noOverlap = true
+				continue
+			}
+			if n > size {
+				n = size
+			}
+			r.start = size - n
+			r.length = n
+		} else {
+			start, err := strconv.ParseInt(startStr, 10, 64)
+			if err != nil || start < 0 {
+				return nil, errInvalidRange
+			}
+			if start >= size {
+				noOverlap = true
+				continue
+			}
+			r.start = start
+			if endStr == "" {
+				r.length = size - start
+			} else {
+				end, err := strconv.ParseInt(endStr, 10, 64)
+				if err != nil || start > end {
+					return nil, errInvalidRange
+				}
+				if end >= size {
+					end = size - 1
+				}
+				r.length = end - start + 1
+			}
+		}
+		ranges = append(ranges, r)
+	}
+	if len(ranges) == 0 {
+		if noOverlap {
+			return nil, errNoOverlap
+		}
+		return nil, errInvalidRange
+	}
+	return ranges, nil
+}
+
+// isSequential reports whether the ranges can be read from a stream in one pass
+func isSequential(ranges []byteRange) bool {
+	for i := 1; i < len(ranges); i++ {
+		if ranges[i].start < ranges[i-1].start+ranges[i-1].length {
+			return false
+		}
+	}
+	return true
+}
+
+// contentSize returns the full size of the response content, -1 if not known
+func contentSize(resp *http.Response) int64 {
+	if seeker, ok := resp.Body.(io.Seeker); ok {
+		size, err := seeker.Seek(0, io.SeekEnd)
+		if err == nil {
+			_, err = seeker.Seek(0, io.SeekStart)
+		}
+		if err == nil {
+			return size
+		}
+		slog.Error("FE rangeHandler.go : Failed to find size of cached content", "error", err.Error())
+	}
+	if resp.ContentLength > 0 {
+		return resp.ContentLength
+	}
+	if size, err := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64); err == nil && size >= 0 {
+		return size
+	}
+	return -1
+}
+
+// ifRangeMatches evaluates the If-Range precondition against the cached validators
+func ifRangeMatches(req *http.Request, resp *http.Response) bool {
+	ir := strings.TrimSpace(req.Header.Get("If-Range"))
+	if ir == "" {
+		return true
+	}
+	if strings.HasPrefix(ir, `"`) || strings.HasPrefix(ir, "W/") {
+		// If-Range needs a strong comparison, weak tags never match
+		etag := resp.Header.Get("ETag")
+		return !strings.HasPrefix(ir, "W/") && !strings.HasPrefix(etag, "W/") && etag == ir
+	}
+	irTime, err := http.ParseTime(ir)
+	if err != nil {
+		return false
+	}
+	lastModified, err := http.ParseTime(resp.Header.Get("Last-Modified"))
+	if err != nil {
+		return false
+	}
+	return irTime.Equal(lastModified)
+}
+
+// rangeSource reads successive byte ranges out of a response body. Cached files are
+// seeked, live streams from the backend are skipped forward.
+type rangeSource struct {
+	body io.ReadCloser
+	pos  int64
+}
+
+func (s *rangeSource) section(r byteRange) (io.Reader, error) {
+	if seeker, ok := s.body.(io.Seeker); ok {
+		if _, err := seeker.Seek(r.start, io.SeekStart); err != nil {
+			return nil, err
+		}
+	} else {
+		if r.start < s.pos {
+			return nil, errRangeNotSequential
+		}
+		if _, err := io.CopyN(io.Discard, s.body, r.start-s.pos); err != nil {
+			return nil, err
+		}
+	}
+	s.pos = r.start + r.length
+	return io.LimitReader(s.body, r.length), nil
+}
+
+// rangeBody is the body of a single range response. The source is positioned on first Read
+// so that skipping a stream does not delay the response headers.
+type rangeBody struct {
+	src *rangeSource
+	rng byteRange
+	rdr io.Reader
+}
+
+func (b *rangeBody) Read(p []byte) (int, error) {
+	if b.rdr == nil {
+		rdr, err := b.src.section(b.rng)
+		if err != nil {
+			return 0, err
+		}
+		b.rdr = rdr
+	}
+	return b.rdr.Read(p)
+}
+
+func (b *rangeBody) Close() error {
+	return b.src.body.Close()
+}
+
+// countingWriter counts the bytes written, used to size multipart bodies upfront
+type countingWriter int64
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	*w += countingWriter(len(p))
+	return len(p), nil
+}
+
+// multipartSize returns the exact length of the multipart/byteranges body
+func multipartSize(ranges []byteRange, contentType string, size int64, boundary string) int64 {
+	var w countingWriter
+	mw := multipart.NewWriter(&w)
+	mw.SetBoundary(boundary)
+	var total int64
+	for _, r := range ranges {
+		mw.CreatePart(r.mimeHeader(contentType, size))
+		total += r.length
+	}
+	mw.Close()
+	return total + int64(w)
+}
+
+func rangeNotSatisfiable(resp *http.Response, size int64) *http.Response {
+	if resp.Body != nil {
+		resp.Body.Close()
+	}
+	ret := &http.Response{
+		Status:        "416 Range Not Satisfiable",
+		StatusCode:    http.StatusRequestedRangeNotSatisfiable,
+		Proto:         resp.Proto,
+		ProtoMajor:    resp.ProtoMajor,
+		ProtoMinor:    resp.ProtoMinor,
+		Header:        make(http.Header),
+		Body:          http.NoBody,
+		ContentLength: 0,
+		Request:       resp.Request,
+	}
+	ret.Header.Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+	ret.Header.Set("Content-Length", "0")
+	ret.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	return ret
+}
+
+// ServeRange converts a full 200 response into a 206 Partial Content (or 416) response
+// as requested by the Range and If-Range headers of the client request.
+// The response is returned unchanged if ranges do not apply.
+func ServeRange(req *http.Request, resp *http.Response) *http.Response {
+	if req.Method != http.MethodGet || resp == nil || resp.StatusCode != http.StatusOK || resp.Body == nil {
+		return resp
+	}
+	if resp.Header == nil {
+		resp.Header = make(http.Header)
+	}
+	size := contentSize(resp)
+	if size < 0 {
+		return resp
+	}
+	resp.Header.Set("Accept-Ranges", "bytes")
+
+	rangeHdr := req.Header.Get("Range")
+	if rangeHdr == "" || !ifRangeMatches(req, resp) {
+		return resp
+	}
+	slog.Info("FE rangeHandler.go : ServeRange() - Start", "range", rangeHdr, "size", size)
+
+	ranges, err := parseRange(rangeHdr, size)
+	if errors.Is(err, errNoOverlap) {
+		slog.Info("FE rangeHandler.go : Range not satisfiable", "range", rangeHdr, "size", size)
+		return rangeNotSatisfiable(resp, size)
+	}
+	if err != nil {
+		slog.Info("FE rangeHandler.go : Ignoring invalid range", "range", rangeHdr)
+		return resp
+	}
+	var sumLen int64
+	for _, r := range ranges {
+		sumLen += r.length
+	}
+	if sumLen > size {
+		// overlapping ranges asking for more than the content, send it all at once
+		return resp
+	}
+	_, seekable := resp.Body.(io.Seeker)
+	if !seekable && !isSequential(ranges) {
+		slog.Info("FE rangeHandler.go : Out of order ranges on a stream, sending full content", "range", rangeHdr)
+		return resp
+	}
+
+	partial := &http.Response{
+		Status:     "206 Partial Content",
+		StatusCode: http.StatusPartialContent,
+		Proto:      resp.Proto,
+		ProtoMajor: resp.ProtoMajor,
+		ProtoMinor: resp.ProtoMinor,
+		Header:     resp.Header.Clone(),
+		Request:    resp.Request,
+	}
+	src := &rangeSource{body: resp.Body}
+
+	if len(ranges) == 1 {
+		r := ranges[0]
+		partial.Header.Set("Content-Range", r.contentRange(size))
+		partial.Header.Set("Content-Length", strconv.FormatInt(r.length, 10))
+		partial.ContentLength = r.length
+		partial.Body = &rangeBody{src: src, rng: r}
+		return partial
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	partial.ContentLength = multipartSize(ranges, contentType, size, mw.Boundary())
+	partial.Header.Set("Content-Type", "multipart/byteranges; boundary="+mw.Boundary())
+	partial.Header.Set("Content-Length", strconv.FormatInt(partial.ContentLength, 10))
+	partial.Body = pr
+	go func() {
+		defer src.body.Close()
+		for _, r := range ranges {
+			part, err := mw.CreatePart(r.mimeHeader(contentType, size))
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+			section, err := src.section(r)
+			if err == nil {
+				_, err = io.Copy(part, section)
+			}
+			if err != nil {
+				slog.Error("FE rangeHandler.go : Error writing multipart range", "error", err.Error())
+				pw.CloseWithError(err)
+				return
+			}
+		}
+		mw.Close()
+		pw.Close()
+	}()
+	return partial
+}
@@ -0,0 +1,188 @@
+package frontend
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const rangeContent = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// cachedBody behaves like the content file handed out by storage
+type cachedBody struct {
+	*bytes.Reader
+}
+
+func (cachedBody) Close() error { return nil }
+
+func newRangeResponse(seekable bool) *http.Response {
+	resp := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     make(http.Header),
+	}
+	resp.Header.Set("Content-Type", "text/plain")
+	resp.Header.Set("ETag", `"v1"`)
+	resp.Header.Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+	if seekable {
+		resp.Body = cachedBody{bytes.NewReader([]byte(rangeContent))}
+	} else {
+		resp.Body = io.NopCloser(strings.NewReader(rangeContent))
+		resp.ContentLength = int64(len(rangeContent))
+	}
+	return resp
+}
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		hdr     string
+		want    []byteRange
+		wantErr error
+	}{
+		{"bytes=0-4", []byteRange{{0, 5}}, nil},
+		{"bytes=30-", []byteRange{{30, 6}}, nil},
+		{"bytes=-6", []byteRange{{30, 6}}, nil},
+		{"bytes=-100", []byteRange{{0, 36}}, nil},
+		{"bytes=30-100", []byteRange{{30, 6}}, nil},
+		{"bytes=0-0, 10-11", []byteRange{{0, 1}, {10, 2}}, nil},
+		{"bytes=40-50", nil, errNoOverlap},
+		{"bytes=40-50,1-2", []byteRange{{1, 2}}, nil},
+		{"bytes=5-1", nil, errInvalidRange},
+		{"items=0-1", nil, errInvalidRange},
+		{"bytes=abc", nil, errInvalidRange},
+	}
+	for _, tc := range tests {
+		got, err := parseRange(tc.hdr, int64(len(rangeContent)))
+		if err != tc.wantErr {
+			t.Errorf("parseRange(%q) error = %v, want %v", tc.hdr, err, tc.wantErr)
+			continue
+		}
+		if len(got) != len(tc.want) {
+			t.Errorf("parseRange(%q) = %v, want %v", tc.hdr, got, tc.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Errorf("parseRange(%q) = %v, want %v", tc.hdr, got, tc.want)
+			}
+		}
+	}
+}
+
+func TestServeRangeSingle(t *testing.T) {
+	for _, seekable := range []bool{true, false} {
+		req := httptest.NewRequest(http.MethodGet, "http://example.com/file", nil)
+		req.Header.Set("Range", "bytes=10-15")
+		resp := ServeRange(req, newRangeResponse(seekable))
+
+		if resp.StatusCode != http.StatusPartialContent {
+			t.Fatalf("seekable=%v: expected 206, got %d", seekable, resp.StatusCode)
+		}
+		if got := resp.Header.Get("Content-Range"); got != "bytes 10-15/36" {
+			t.Errorf("seekable=%v: unexpected Content-Range %q", seekable, got)
+		}
+		if resp.ContentLength != 6 || resp.Header.Get("Content-Length") != "6" {
+			t.Errorf("seekable=%v: unexpected Content-Length %d", seekable, resp.ContentLength)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		if string(body) != "abcdef" {
+			t.Errorf("seekable=%v: unexpected body %q", seekable, body)
+		}
+	}
+}
+
+func TestServeRangeMultipart(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/file", nil)
+	req.Header.Set("Range", "bytes=0-1,-2")
+	resp := ServeRange(req, newRangeResponse(false))
+
+	if resp.StatusCode != http.StatusPartialContent {
+		t.Fatalf("expected 206, got %d", resp.StatusCode)
+	}
+	mediaType, params, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/byteranges" {
+		t.Fatalf("unexpected Content-Type %q", resp.Header.Get("Content-Type"))
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if int64(len(body)) != resp.ContentLength {
+		t.Errorf("Content-Length %d does not match body length %d", resp.ContentLength, len(body))
+	}
+
+	mr := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+	want := []struct{ contentRange, data string }{
+		{"bytes 0-1/36", "01"},
+		{"bytes 34-35/36", "yz"},
+	}
+	for _, w := range want {
+		part, err := mr.NextPart()
+		if err != nil {
+			t.Fatalf("missing part %q: %v", w.contentRange, err)
+		}
+		if got := part.Header.Get("Content-Range"); got != w.contentRange {
+			t.Errorf("unexpected part Content-Range %q, want %q", got, w.contentRange)
+		}
+		data, _ := io.ReadAll(part)
+		if string(data) != w.data {
+			t.Errorf("unexpected part body %q, want %q", data, w.data)
+		}
+	}
+}
+
+func TestServeRangeFallbacks(t *testing.T) {
+	t.Run("Not satisfiable", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "http://example.com/file", nil)
+		req.Header.Set("Range", "bytes=100-")
+		resp := ServeRange(req, newRangeResponse(true))
+		if resp.StatusCode != http.StatusRequestedRangeNotSatisfiable {
+			t.Fatalf("expected 416, got %d", resp.StatusCode)
+		}
+		if got := resp.Header.Get("Content-Range"); got != "bytes */36" {
+			t.Errorf("unexpected Content-Range %q", got)
+		}
+	})
+
+	t.Run("If-Range mismatch sends full content", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "http://example.com/file", nil)
+		req.Header.Set("Range", "bytes=0-1")
+		req.Header.Set("If-Range", `"v2"`)
+		resp := ServeRange(req, newRangeResponse(true))
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected 200, got %d", resp.StatusCode)
+		}
+		if resp.Header.Get("Accept-Ranges") != "bytes" {
+			t.Errorf("expected Accept-Ranges: bytes")
+		}
+	})
+
+	t.Run("If-Range date match", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "http://example.com/file", nil)
+		req.Header.Set("Range", "bytes=0-1")
+		req.Header.Set("If-Range", "Mon, 02 Jan 2006 15:04:05 GMT")
+		resp := ServeRange(req, newRangeResponse(true))
+		if resp.StatusCode != http.StatusPartialContent {
+			t.Fatalf("expected 206, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Out of order ranges on a stream", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "http://example.com/file", nil)
+		req.Header.Set("Range", "bytes=20-21,0-1")
+		resp := ServeRange(req, newRangeResponse(false))
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected 200, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("HEAD is not ranged", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodHead, "http://example.com/file", nil)
+		req.Header.Set("Range", "bytes=0-1")
+		resp := ServeRange(req, newRangeResponse(true))
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected 200, got %d", resp.StatusCode)
+		}
+	})
+}
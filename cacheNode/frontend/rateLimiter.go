@@ -0,0 +1,186 @@
+package frontend
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	coCfg "github.com/hcl/cdn/common/config"
+)
+
+// Reasons of the rejected requests in the frontend metrics
+const (
+	DenyReasonClientRate  = "rate_client"
+	DenyReasonDSRate      = "rate_ds"
+	DenyReasonConcurrency = "concurrency"
+)
+
+// client buckets unused for this long are dropped, they are full again anyway
+const clientBucketIdle = 5 * time.Minute
+
+// tokenBucket refills rate tokens per second up to burst, a request takes one token
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// take refills the bucket with the current limits and takes a token. When the bucket is empty it
+// returns false along with the time until the next token.
+func (b *tokenBucket) take(rate float64, burst int, now time.Time) (bool, time.Duration) {
+	capacity := float64(burst)
+	if burst <= 0 {
+		capacity = math.Max(rate, 1)
+	}
+	if b.last.IsZero() {
+		b.tokens = capacity
+	} else if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens += elapsed * rate
+	}
+	// limits changed by the configServer apply at once
+	b.tokens = math.Min(b.tokens, capacity)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	return false, wait
+}
+
+// RateLimiter is the admission control of the frontend: token buckets per client IP and per DS,
+// and a cap on the requests in progress per DS. Limits come with the DS list.
+type RateLimiter struct {
+	mu       sync.Mutex
+	clients  map[string]*tokenBucket // DS name & client IP to bucket
+	services map[string]*tokenBucket // DS name to bucket
+	inFlight map[string]int          // DS name to requests in progress
+}
+
+// NewRateLimiter initializes a new RateLimiter, idle client buckets are dropped until the context is done
+func NewRateLimiter(ctx context.Context, wg *sync.WaitGroup) *RateLimiter {
+	r := &RateLimiter{
+		clients:  make(map[string]*tokenBucket),
+		services: make(map[string]*tokenBucket),
+		inFlight: make(map[string]int),
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(clientBucketIdle)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				r.dropIdle(now)
+			}
+		}
+	}()
+	return r
+}
+
+func (r *RateLimiter) dropIdle(now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key, bucket := range r.clients {
+		if now.Sub(bucket.last) > clientBucketIdle {
+			delete(r.clients, key)
+		}
+	}
+}
+
+// Admit decides on the request of the client to the DS. An admitted request holds a slot of the DS
+// until release is called. A rejected request gets the reason and the time after which to retry.
+func (r *RateLimiter) Admit(ds *coCfg.DeliveryService, client string, now time.Time) (release func(), reason string, retryAfter time.Duration) {
+	limit := &ds.RateLimit
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if limit.ClientRate > 0 {
+		key := ds.Name + "|" + client
+		bucket, ok := r.clients[key]
+		if !ok {
+			bucket = &tokenBucket{}
+			r.clients[key] = bucket
+		}
+		if ok, wait := bucket.take(limit.ClientRate, limit.ClientBurst, now); !ok {
+			return nil, DenyReasonClientRate, wait
+		}
+	}
+	if limit.DSRate > 0 {
+		bucket, ok := r.services[ds.Name]
+		if !ok {
+			bucket = &tokenBucket{}
+			r.services[ds.Name] = bucket
+		}
+		if ok, wait := bucket.take(limit.DSRate, limit.DSBurst, now); !ok {
+			return nil, DenyReasonDSRate, wait
+		}
+	}
+	if limit.MaxConcurrent > 0 {
+		if r.inFlight[ds.Name] >= limit.MaxConcurrent {
+			return nil, DenyReasonConcurrency, time.Second
+		}
+		r.inFlight[ds.Name]++
+		name := ds.Name
+		var once sync.Once
+		return func() {
+			once.Do(func() {
+				r.mu.Lock()
+				defer r.mu.Unlock()
+				if r.inFlight[name]--; r.inFlight[name] <= 0 {
+					delete(r.inFlight, name)
+				}
+			})
+		}, "", 0
+	}
+	return func() {}, "", 0
+}
+
+// tooManyRequests returns the 429 response telling the client when to retry
+func tooManyRequests(retryAfter time.Duration) *http.Response {
+	body := "429 : Too Many Requests"
+	resp := &http.Response{
+		StatusCode:    http.StatusTooManyRequests,
+		Status:        fmt.Sprintf("%d %s", http.StatusTooManyRequests, http.StatusText(http.StatusTooManyRequests)),
+		Header:        make(http.Header),
+		ContentLength: int64(len(body)),
+		Body:          io.NopCloser(strings.NewReader(body)),
+	}
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	resp.Header.Set("Retry-After", strconv.Itoa(seconds))
+	resp.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	resp.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	resp.Header.Set("Cache-Control", "no-store")
+	return resp
+}
+
+// admit applies the admission control of the DS to the client request. It returns the release
+// of the admitted request, or the 429 response and the reason for a rejected one. Mid nodes are
+// reached by the edges, the clients were admitted there.
+func (l *Listener) admit(req *http.Request) (release func(), rejected *http.Response, reason string) {
+	if l.cfg.NodeType() == coCfg.CacheNodeMid {
+		return func() {}, nil, ""
+	}
+	configDS, err := l.cfg.DSLookup(req)
+	if err != nil || l.limiter == nil {
+		return func() {}, nil, ""
+	}
+	release, reason, retryAfter := l.limiter.Admit(configDS, clientIP(req), time.Now())
+	if reason != "" {
+		slog.Info("FE rateLimiter.go : Request rejected", "ds", configDS.Name, "client", clientIP(req), "reason", reason, "retryAfter", retryAfter)
+		return func() {}, tooManyRequests(retryAfter), reason
+	}
+	return release, nil, ""
+}
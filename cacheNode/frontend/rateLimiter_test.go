@@ -0,0 +1,112 @@
+package frontend
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hcl/cdn/cacheNode/config"
+	coCfg "github.com/hcl/cdn/common/config"
+)
+
+func newRateLimiter(t *testing.T) *RateLimiter {
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	t.Cleanup(func() {
+		cancel()
+		wg.Wait()
+	})
+	return NewRateLimiter(ctx, &wg)
+}
+
+func TestRateLimiterClientRate(t *testing.T) {
+	r := newRateLimiter(t)
+	ds := &coCfg.DeliveryService{Name: "abc", RateLimit: coCfg.RateLimit{ClientRate: 2, ClientBurst: 3}}
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		if _, reason, _ := r.Admit(ds, "10.0.0.1", now); reason != "" {
+			t.Fatalf("expected burst request %d to pass, got %s", i, reason)
+		}
+	}
+	_, reason, retryAfter := r.Admit(ds, "10.0.0.1", now)
+	if reason != DenyReasonClientRate || retryAfter != 500*time.Millisecond {
+		t.Errorf("expected client rate rejection with 500ms retry, got %q %v", reason, retryAfter)
+	}
+	// other clients have their own bucket
+	if _, reason, _ = r.Admit(ds, "10.0.0.2", now); reason != "" {
+		t.Errorf("expected another client to pass, got %s", reason)
+	}
+	// refilled at 2 per second
+	if _, reason, _ = r.Admit(ds, "10.0.0.1", now.Add(500*time.Millisecond)); reason != "" {
+		t.Errorf("expected refilled token to pass, got %s", reason)
+	}
+
+	r.dropIdle(now.Add(clientBucketIdle + time.Second))
+	if len(r.clients) != 0 {
+		t.Errorf("expected idle client buckets to be dropped, %d left", len(r.clients))
+	}
+}
+
+func TestRateLimiterDSLimits(t *testing.T) {
+	r := newRateLimiter(t)
+	ds := &coCfg.DeliveryService{Name: "abc", RateLimit: coCfg.RateLimit{DSRate: 1, DSBurst: 2}}
+	now := time.Now()
+	r.Admit(ds, "10.0.0.1", now)
+	r.Admit(ds, "10.0.0.2", now)
+	if _, reason, _ := r.Admit(ds, "10.0.0.3", now); reason != DenyReasonDSRate {
+		t.Errorf("expected DS rate rejection, got %q", reason)
+	}
+
+	ds = &coCfg.DeliveryService{Name: "xyz", RateLimit: coCfg.RateLimit{MaxConcurrent: 2}}
+	release1, _, _ := r.Admit(ds, "10.0.0.1", now)
+	release2, _, _ := r.Admit(ds, "10.0.0.1", now)
+	if _, reason, _ := r.Admit(ds, "10.0.0.1", now); reason != DenyReasonConcurrency {
+		t.Errorf("expected concurrency rejection, got %q", reason)
+	}
+	release1()
+	release1() // released once only
+	release3, reason, _ := r.Admit(ds, "10.0.0.1", now)
+	if reason != "" {
+		t.Errorf("expected released slot to be reused, got %q", reason)
+	}
+	if _, reason, _ = r.Admit(ds, "10.0.0.1", now); reason != DenyReasonConcurrency {
+		t.Errorf("expected concurrency rejection after double release, got %q", reason)
+	}
+	release2()
+	release3()
+	if len(r.inFlight) != 0 {
+		t.Errorf("expected no requests in progress, got %v", r.inFlight)
+	}
+}
+
+func TestAdmit(t *testing.T) {
+	l := &Listener{
+		cfg: &config.RunConfig{ServiceList: &coCfg.DeliveryServices{ServiceList: []coCfg.DeliveryService{
+			{Name: "abc", ClientURL: "http://abc.com", OriginURL: "http://origin.abc.com", RateLimit: coCfg.RateLimit{ClientRate: 0.5, ClientBurst: 1}},
+		}}},
+		limiter: newRateLimiter(t),
+	}
+	req := httptest.NewRequest(http.MethodGet, "http://abc.com/a.js", nil)
+	release, rejected, _ := l.admit(req)
+	if rejected != nil {
+		t.Fatalf("expected first request to pass, got %d", rejected.StatusCode)
+	}
+	release()
+	_, rejected, reason := l.admit(req)
+	if rejected == nil || rejected.StatusCode != http.StatusTooManyRequests || reason != DenyReasonClientRate {
+		t.Fatalf("expected 429, got %v %q", rejected, reason)
+	}
+	if retry := rejected.Header.Get("Retry-After"); retry != "2" {
+		t.Errorf("expected Retry-After 2, got %q", retry)
+	}
+
+	// Mid nodes are reached by the edges
+	l.cfg.Node = &coCfg.CacheNode{Type: coCfg.CacheNodeMid}
+	if _, rejected, _ := l.admit(req); rejected != nil {
+		t.Errorf("expected mid node not to limit the edges, got %d", rejected.StatusCode)
+	}
+}
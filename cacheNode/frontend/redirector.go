@@ -0,0 +1,84 @@
+package frontend
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	coCfg "github.com/hcl/cdn/common/config"
+	"github.com/hcl/cdn/common/helper"
+)
+
+// compiled sources of the redirect rules, the rules change seldom
+var redirectSources sync.Map
+
+func redirectSource(expr string) (*regexp.Regexp, error) {
+	if re, ok := redirectSources.Load(expr); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	redirectSources.Store(expr, re)
+	return re, nil
+}
+
+// redirectLocation returns the Location & status of the first rule matching the path of the request,
+// "" when no rule matches
+func redirectLocation(rules []coCfg.RedirectRule, req *http.Request) (string, int) {
+	path := req.URL.EscapedPath()
+	for _, rule := range rules {
+		re, err := redirectSource(rule.Source)
+		if err != nil {
+			slog.Error("FE redirector.go : Invalid redirect source", "regex", rule.Source)
+			continue
+		}
+		match := re.FindStringSubmatchIndex(path)
+		if match == nil {
+			continue
+		}
+		location := string(re.ExpandString(nil, rule.Target, path, match))
+		if rule.PreserveQuery && req.URL.RawQuery != "" {
+			if strings.Contains(location, "?") {
+				location += "&" + req.URL.RawQuery
+			} else {
+				location += "?" + req.URL.RawQuery
+			}
+		}
+		status := rule.StatusCode
+		if status == 0 {
+			status = http.StatusFound
+		}
+		return location, status
+	}
+	return "", 0
+}
+
+// redirect answers the client request with the redirect rules of the DS before the cache lookup,
+// it returns nil when no rule matches
+func (l *Listener) redirect(req *http.Request) *http.Response {
+	configDS, err := l.cfg.DSLookup(req)
+	if err != nil || len(configDS.Redirects) == 0 {
+		return nil
+	}
+	location, status := redirectLocation(configDS.Redirects, req)
+	if location == "" {
+		return nil
+	}
+	resp := &http.Response{
+		StatusCode: status,
+		Status:     fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		Header:     make(http.Header),
+		Body:       http.NoBody,
+	}
+	resp.Header.Set("Location", location)
+	resp.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	resp.Header.Set("Content-Length", "0")
+	slog.Info("FE redirector.go : Redirecting client", "url", helper.GetString(req), "location", location, "status", status)
+	return resp
+}
@@ -0,0 +1,45 @@
+package frontend
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hcl/cdn/cacheNode/config"
+	coCfg "github.com/hcl/cdn/common/config"
+)
+
+func TestRedirect(t *testing.T) {
+	l := &Listener{
+		cfg: &config.RunConfig{ServiceList: &coCfg.DeliveryServices{ServiceList: []coCfg.DeliveryService{
+			{Name: "abc", ClientURL: "http://abc.com", OriginURL: "http://origin.abc.com", Redirects: []coCfg.RedirectRule{
+				{Source: `^/blog/(.*)$`, Target: "https://blog.abc.com/$1", StatusCode: http.StatusMovedPermanently, PreserveQuery: true},
+				{Source: `^/promo$`, Target: "/offers?id=1", PreserveQuery: true},
+				{Source: `^/api/v1/(.*)$`, Target: "/api/v2/$1", StatusCode: http.StatusPermanentRedirect},
+			}},
+		}}},
+	}
+	cases := []struct {
+		url      string
+		status   int
+		location string
+	}{
+		{"http://abc.com/blog/2024/post.html?utm=x", http.StatusMovedPermanently, "https://blog.abc.com/2024/post.html?utm=x"},
+		{"http://abc.com/promo?src=mail", http.StatusFound, "/offers?id=1&src=mail"},
+		{"http://abc.com/api/v1/users?page=2", http.StatusPermanentRedirect, "/api/v2/users"},
+		{"http://abc.com/index.html", 0, ""},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodGet, c.url, nil)
+		resp := l.redirect(req)
+		if c.status == 0 {
+			if resp != nil {
+				t.Errorf("%s: expected no redirect, got %d", c.url, resp.StatusCode)
+			}
+			continue
+		}
+		if resp == nil || resp.StatusCode != c.status || resp.Header.Get("Location") != c.location {
+			t.Errorf("%s: expected %d %s, got %v", c.url, c.status, c.location, resp)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package frontend
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+
+	"github.com/hcl/cdn/cacheNode/common"
+	"github.com/hcl/cdn/common/helper"
+)
+
+// BackgroundRefresher revalidates stale objects with Backend.ReDo() in background go routines,
+// the client is served the stale copy meanwhile (stale-while-revalidate)
+type BackgroundRefresher struct {
+	ctx      context.Context
+	wg       *sync.WaitGroup
+	backend  common.CachedRequestHandler
+	mu       sync.Mutex
+	inFlight map[string]bool
+}
+
+// NewBackgroundRefresher initializes a new BackgroundRefresher
+func NewBackgroundRefresher(ctx context.Context, wg *sync.WaitGroup, backend common.CachedRequestHandler) *BackgroundRefresher {
+	return &BackgroundRefresher{
+		ctx:      ctx,
+		wg:       wg,
+		backend:  backend,
+		inFlight: make(map[string]bool),
+	}
+}
+
+// DoBg starts the refresh of the stale object unless one is already running for the same URL.
+// Only the headers of the stale response are used, its body stays with the caller.
+func (r *BackgroundRefresher) DoBg(req *http.Request, staleResp *http.Response) bool {
+	key := helper.GetString(req)
+	r.mu.Lock()
+	if r.inFlight[key] {
+		r.mu.Unlock()
+		slog.Info("FE refresher.go : Refresh already in progress", "url", key)
+		return false
+	}
+	r.inFlight[key] = true
+	r.mu.Unlock()
+
+	bgReq := req.Clone(r.ctx)
+	oldResp := &http.Response{
+		StatusCode: staleResp.StatusCode,
+		Header:     staleResp.Header.Clone(),
+		Body:       http.NoBody,
+	}
+
+	r.wg.Add(1)
+	go func(ctx context.Context, wg *sync.WaitGroup) {
+		defer wg.Done()
+		defer func() {
+			r.mu.Lock()
+			delete(r.inFlight, key)
+			r.mu.Unlock()
+		}()
+		slog.Info("FE refresher.go : Background refresh - Start", "url", key)
+		resp, err := r.backend.ReDo(bgReq, oldResp)
+		if err != nil {
+			slog.Error("FE refresher.go : Background refresh failed", "url", key, "error", err.Error())
+			return
+		}
+		if resp == nil {
+			return
+		}
+		if resp.Body != nil {
+			// The backend saves the refreshed object from its own reader of the body
+			resp.Body.Close()
+		}
+		slog.Info("FE refresher.go : Background refresh - End", "url", key, "status", resp.StatusCode)
+	}(r.ctx, r.wg)
+	return true
+}
@@ -0,0 +1,95 @@
+package frontend
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// staleBackend answers ReDo with a fixed response or error and counts the calls
+type staleBackend struct {
+	mu     sync.Mutex
+	redos  int
+	status int
+	err    error
+}
+
+func (b *staleBackend) Do(req *http.Request) (*http.Response, error) {
+	return nil, errors.New("not expected")
+}
+
+func (b *staleBackend) ReDo(req *http.Request, oldResp *http.Response) (*http.Response, error) {
+	b.mu.Lock()
+	b.redos++
+	b.mu.Unlock()
+	if b.err != nil {
+		return nil, b.err
+	}
+	return &http.Response{
+		StatusCode: b.status,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader("from backend")),
+	}, nil
+}
+
+func newStaleResponse(cacheControl string) *http.Response {
+	resp := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader("stale content")),
+	}
+	resp.Header.Set("Cache-Control", cacheControl)
+	resp.Header.Set("Age", "80")
+	return resp
+}
+
+func TestValidatorStaleWhileRevalidate(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	backend := &staleBackend{status: http.StatusOK}
+	v := Validator{BackendRequest: backend, Refresher: NewBackgroundRefresher(context.Background(), wg, backend)}
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/a", nil)
+	resp, err := v.Do(req, newStaleResponse("max-age=60, stale-while-revalidate=30"))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "stale content" || resp.Header.Get("X-Is-Cached") == "" {
+		t.Errorf("expected the stale copy to be served, got %q", body)
+	}
+	wg.Wait()
+	if backend.redos != 1 {
+		t.Errorf("expected one background refresh, got %d", backend.redos)
+	}
+}
+
+func TestValidatorStaleIfError(t *testing.T) {
+	tests := []struct {
+		name    string
+		backend *staleBackend
+		cc      string
+		want    string
+	}{
+		{"5xx within window", &staleBackend{status: http.StatusBadGateway}, "max-age=60, stale-if-error=60", "stale content"},
+		{"error within window", &staleBackend{err: errors.New("timeout")}, "max-age=60, stale-if-error=60", "stale content"},
+		{"5xx beyond window", &staleBackend{status: http.StatusBadGateway}, "max-age=60, stale-if-error=10", "Internal Server Error"},
+		{"must-revalidate", &staleBackend{status: http.StatusBadGateway}, "max-age=60, stale-if-error=60, must-revalidate", "Internal Server Error"},
+	}
+	for _, tc := range tests {
+		v := Validator{BackendRequest: tc.backend}
+		req := httptest.NewRequest(http.MethodGet, "http://example.com/a", nil)
+		resp, err := v.Do(req, newStaleResponse(tc.cc))
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", tc.name, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		if string(body) != tc.want {
+			t.Errorf("%s: expected %q, got %q", tc.name, tc.want, body)
+		}
+	}
+}
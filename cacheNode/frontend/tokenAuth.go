@@ -0,0 +1,181 @@
+package frontend
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	coCfg "github.com/hcl/cdn/common/config"
+	"github.com/hcl/cdn/common/helper"
+)
+
+/*
+ * Token of the signed URLs, carried in a query param or a cookie
+ *		exp=<unix time>[~ip=<client ip>][~path=<path prefix>][~kid=<key id>]~hmac=<hex HMAC-SHA256>
+ * The HMAC covers the fields before "~hmac=". A token without path is valid for one URL path only,
+ * the HMAC then covers "~url=<path>" as well. The path prefix covers whole path segments.
+ */
+
+const defaultTokenName = "token"
+
+var (
+	errTokenMissing   = errors.New("token missing")
+	errTokenMalformed = errors.New("token malformed")
+	errTokenExpired   = errors.New("token expired")
+	errTokenIP        = errors.New("token bound to another client IP")
+	errTokenPath      = errors.New("token not valid for the path")
+	errTokenSignature = errors.New("token signature invalid")
+)
+
+// SignToken returns the token signed with the key. ip & pathPrefix are optional,
+// without pathPrefix the token is valid for urlPath only.
+func SignToken(key coCfg.TokenKey, expiry time.Time, ip string, pathPrefix string, urlPath string) string {
+	fields := []string{"exp=" + strconv.FormatInt(expiry.Unix(), 10)}
+	if ip != "" {
+		fields = append(fields, "ip="+ip)
+	}
+	if pathPrefix != "" {
+		fields = append(fields, "path="+pathPrefix)
+	}
+	if key.ID != "" {
+		fields = append(fields, "kid="+key.ID)
+	}
+	payload := strings.Join(fields, "~")
+	return payload + "~hmac=" + tokenHMAC(key.Secret, payload, pathPrefix, urlPath)
+}
+
+func tokenHMAC(secret string, payload string, pathPrefix string, urlPath string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	io.WriteString(mac, payload)
+	if pathPrefix == "" {
+		io.WriteString(mac, "~url="+urlPath)
+	}
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// verifyToken checks the token of the request against the policy of the DS
+func verifyToken(policy *coCfg.TokenAuth, token string, clientIP string, urlPath string, now time.Time) error {
+	if token == "" {
+		return errTokenMissing
+	}
+	payload, signature, ok := strings.Cut(token, "~hmac=")
+	if !ok || signature == "" {
+		return errTokenMalformed
+	}
+	fields := make(map[string]string)
+	for _, field := range strings.Split(payload, "~") {
+		name, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return errTokenMalformed
+		}
+		fields[name] = value
+	}
+
+	exp, err := strconv.ParseInt(fields["exp"], 10, 64)
+	if err != nil {
+		return errTokenMalformed
+	}
+	if now.Unix() > exp {
+		return errTokenExpired
+	}
+	if ip, ok := fields["ip"]; ok {
+		if ip != clientIP {
+			return errTokenIP
+		}
+	} else if policy.RequireIP {
+		return errTokenIP
+	}
+	// the scope covers whole path segments, /videos does not cover /videos-private/
+	if prefix, ok := fields["path"]; ok && urlPath != prefix && !strings.HasPrefix(urlPath, strings.TrimSuffix(prefix, "/")+"/") {
+		return errTokenPath
+	}
+
+	got, err := hex.DecodeString(signature)
+	if err != nil {
+		return errTokenMalformed
+	}
+	// every active key is tried unless the token names its key
+	kid, named := fields["kid"]
+	for _, key := range policy.Keys {
+		if named && key.ID != kid {
+			continue
+		}
+		want, _ := hex.DecodeString(tokenHMAC(key.Secret, payload, fields["path"], urlPath))
+		if hmac.Equal(got, want) {
+			return nil
+		}
+	}
+	return errTokenSignature
+}
+
+func tokenName(name string) string {
+	if name == "" {
+		return defaultTokenName
+	}
+	return name
+}
+
+// tokenFromRequest returns the token of the query param, else of the cookie
+func tokenFromRequest(policy *coCfg.TokenAuth, req *http.Request) string {
+	queryParam := tokenName(policy.QueryParam)
+	if token := req.URL.Query().Get(queryParam); token != "" {
+		return token
+	}
+	if cookie, err := req.Cookie(tokenName(policy.CookieName)); err == nil {
+		return cookie.Value
+	}
+	return ""
+}
+
+// stripToken removes the token param from the request URL, all signed URLs of an object share
+// the cache key and the token does not go upstream
+func stripToken(policy *coCfg.TokenAuth, req *http.Request) {
+	queryParam := tokenName(policy.QueryParam)
+	var kept []string
+	for _, part := range strings.Split(req.URL.RawQuery, "&") {
+		name, _, _ := strings.Cut(part, "=")
+		if unescaped, err := url.QueryUnescape(name); part == "" || (err == nil && unescaped == queryParam) {
+			continue
+		}
+		kept = append(kept, part)
+	}
+	// the order of the other params is kept, it may be part of the cache key
+	req.URL.RawQuery = strings.Join(kept, "&")
+	req.RequestURI = req.URL.RequestURI()
+}
+
+// clientIP returns the IP address of the client without the port
+func clientIP(req *http.Request) string {
+	if host, _, err := net.SplitHostPort(req.RemoteAddr); err == nil {
+		return host
+	}
+	return req.RemoteAddr
+}
+
+// authorize enforces the signed URLs of the DS at the edge, it returns the 403 response for the client
+// or nil when the request is served. Mid nodes trust their children, the edge removed the token.
+func (l *Listener) authorize(req *http.Request) *http.Response {
+	if l.cfg.NodeType() == coCfg.CacheNodeMid {
+		return nil
+	}
+	configDS, err := l.cfg.DSLookup(req)
+	if err != nil || !configDS.TokenAuth.Enabled {
+		return nil
+	}
+	err = verifyToken(&configDS.TokenAuth, tokenFromRequest(&configDS.TokenAuth, req), clientIP(req), req.URL.Path, time.Now())
+	if err != nil {
+		slog.Info("FE tokenAuth.go : Request denied", "url", helper.GetString(req), "client", clientIP(req), "reason", err.Error())
+		return forbidden()
+	}
+	stripToken(&configDS.TokenAuth, req)
+	return nil
+}
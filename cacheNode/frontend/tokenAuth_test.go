@@ -0,0 +1,94 @@
+package frontend
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/hcl/cdn/cacheNode/config"
+	coCfg "github.com/hcl/cdn/common/config"
+)
+
+var (
+	oldKey = coCfg.TokenKey{ID: "k1", Secret: "old secret"}
+	newKey = coCfg.TokenKey{ID: "k2", Secret: "new secret"}
+)
+
+func TestVerifyToken(t *testing.T) {
+	now := time.Now()
+	policy := &coCfg.TokenAuth{Enabled: true, Keys: []coCfg.TokenKey{oldKey, newKey}}
+	cases := []struct {
+		name   string
+		token  string
+		ip     string
+		path   string
+		policy *coCfg.TokenAuth
+		want   error
+	}{
+		{"valid", SignToken(newKey, now.Add(time.Minute), "", "", "/a.mp4"), "10.0.0.1", "/a.mp4", policy, nil},
+		{"rotated key still active", SignToken(oldKey, now.Add(time.Minute), "", "", "/a.mp4"), "10.0.0.1", "/a.mp4", policy, nil},
+		{"unknown key", SignToken(coCfg.TokenKey{ID: "k3", Secret: "x"}, now.Add(time.Minute), "", "", "/a.mp4"), "10.0.0.1", "/a.mp4", policy, errTokenSignature},
+		{"key without id", SignToken(coCfg.TokenKey{Secret: "new secret"}, now.Add(time.Minute), "", "", "/a.mp4"), "10.0.0.1", "/a.mp4", policy, nil},
+		{"expired", SignToken(newKey, now.Add(-time.Minute), "", "", "/a.mp4"), "10.0.0.1", "/a.mp4", policy, errTokenExpired},
+		{"other path", SignToken(newKey, now.Add(time.Minute), "", "", "/a.mp4"), "10.0.0.1", "/b.mp4", policy, errTokenSignature},
+		{"path scope", SignToken(newKey, now.Add(time.Minute), "", "/videos/", ""), "10.0.0.1", "/videos/b.mp4", policy, nil},
+		{"out of path scope", SignToken(newKey, now.Add(time.Minute), "", "/videos/", ""), "10.0.0.1", "/images/b.png", policy, errTokenPath},
+		{"path scope without slash", SignToken(newKey, now.Add(time.Minute), "", "/videos", ""), "10.0.0.1", "/videos/b.mp4", policy, nil},
+		{"path scope of a sibling", SignToken(newKey, now.Add(time.Minute), "", "/videos", ""), "10.0.0.1", "/videos-private/b.mp4", policy, errTokenPath},
+		{"ip bound", SignToken(newKey, now.Add(time.Minute), "10.0.0.1", "", "/a.mp4"), "10.0.0.1", "/a.mp4", policy, nil},
+		{"other ip", SignToken(newKey, now.Add(time.Minute), "10.0.0.1", "", "/a.mp4"), "10.0.0.2", "/a.mp4", policy, errTokenIP},
+		{"ip required", SignToken(newKey, now.Add(time.Minute), "", "", "/a.mp4"), "10.0.0.1", "/a.mp4",
+			&coCfg.TokenAuth{Enabled: true, RequireIP: true, Keys: []coCfg.TokenKey{newKey}}, errTokenIP},
+		{"tampered expiry", "exp=99999999999~kid=k2~hmac=00", "10.0.0.1", "/a.mp4", policy, errTokenSignature},
+		{"missing", "", "10.0.0.1", "/a.mp4", policy, errTokenMissing},
+		{"malformed", "garbage", "10.0.0.1", "/a.mp4", policy, errTokenMalformed},
+	}
+	for _, c := range cases {
+		if got := verifyToken(c.policy, c.token, c.ip, c.path, now); got != c.want {
+			t.Errorf("%s: verifyToken = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	l := &Listener{cfg: &config.RunConfig{
+		Node: &coCfg.CacheNode{Type: coCfg.CacheNodeEdge},
+		ServiceList: &coCfg.DeliveryServices{
+			ServiceList: []coCfg.DeliveryService{{
+				Name:      "abc",
+				ClientURL: "http://abc.com",
+				OriginURL: "http://origin.abc.com",
+				TokenAuth: coCfg.TokenAuth{Enabled: true, CookieName: "auth", Keys: []coCfg.TokenKey{newKey}},
+			}},
+		},
+	}}
+	token := SignToken(newKey, time.Now().Add(time.Minute), "", "/premium/", "")
+
+	req := httptest.NewRequest(http.MethodGet, "http://abc.com/premium/a.mp4?b=2&token="+url.QueryEscape(token)+"&a=1", nil)
+	if resp := l.authorize(req); resp != nil {
+		t.Fatalf("expected valid token to pass, got %d", resp.StatusCode)
+	}
+	if req.URL.RawQuery != "b=2&a=1" || req.RequestURI != "/premium/a.mp4?b=2&a=1" {
+		t.Errorf("expected token to be stripped, got %q %q", req.URL.RawQuery, req.RequestURI)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "http://abc.com/premium/a.mp4", nil)
+	req.AddCookie(&http.Cookie{Name: "auth", Value: token})
+	if resp := l.authorize(req); resp != nil {
+		t.Errorf("expected token of the cookie to pass, got %d", resp.StatusCode)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "http://abc.com/premium/a.mp4?token=exp%3D1~hmac%3D00", nil)
+	if resp := l.authorize(req); resp == nil || resp.StatusCode != http.StatusForbidden {
+		t.Errorf("expected 403 for an invalid token, got %v", resp)
+	}
+
+	// Mid nodes are reached without token
+	l.cfg.Node.Type = coCfg.CacheNodeMid
+	req = httptest.NewRequest(http.MethodGet, "http://abc.com/premium/a.mp4", nil)
+	if resp := l.authorize(req); resp != nil {
+		t.Errorf("expected mid node not to check tokens, got %d", resp.StatusCode)
+	}
+}
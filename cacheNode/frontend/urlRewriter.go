@@ -0,0 +1,27 @@
+package frontend
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/hcl/cdn/cacheNode/urlRewrite"
+	coCfg "github.com/hcl/cdn/common/config"
+	"github.com/hcl/cdn/common/helper"
+)
+
+// rewriteURL applies the URL rewrite rules of the DS to the client request before the cache key is
+// computed, the rewritten URL is cached & fetched. Mid nodes get the URL already rewritten by the edge.
+func (l *Listener) rewriteURL(req *http.Request) {
+	if l.cfg.NodeType() == coCfg.CacheNodeMid {
+		return
+	}
+	configDS, err := l.cfg.DSLookup(req)
+	if err != nil || len(configDS.URLRewrites) == 0 {
+		return
+	}
+	original := helper.GetString(req)
+	if urlRewrite.Rewrite(configDS.URLRewrites, false, req.URL) {
+		req.RequestURI = req.URL.RequestURI()
+		slog.Info("FE urlRewriter.go : URL rewritten", "url", original, "rewritten", helper.GetString(req))
+	}
+}
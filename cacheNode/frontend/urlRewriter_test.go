@@ -0,0 +1,47 @@
+package frontend
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hcl/cdn/cacheNode/config"
+	coCfg "github.com/hcl/cdn/common/config"
+)
+
+func TestRewriteURL(t *testing.T) {
+	l := &Listener{
+		cfg: &config.RunConfig{
+			Node: &coCfg.CacheNode{Type: coCfg.CacheNodeEdge},
+			ServiceList: &coCfg.DeliveryServices{ServiceList: []coCfg.DeliveryService{
+				{Name: "abc", ClientURL: "http://abc.com", OriginURL: "http://origin.abc.com", URLRewrites: []coCfg.URLRewriteRule{
+					{Pattern: `^/v1/(.*)$`, Replacement: "/v2/$1"},
+					{Pattern: `^/(.*)\.jpg$`, Replacement: "/$1.webp", UpstreamOnly: true},
+				}},
+			}},
+		},
+	}
+	req := httptest.NewRequest(http.MethodGet, "http://abc.com/v1/a.js?x=1", nil)
+	l.reformat(req)
+	l.rewriteURL(req)
+	if req.URL.String() != "http://abc.com/v2/a.js?x=1" || req.RequestURI != "/v2/a.js?x=1" {
+		t.Errorf("unexpected rewritten URL %s %s", req.URL, req.RequestURI)
+	}
+
+	// upstream only rules keep the client URL in the cache key
+	req = httptest.NewRequest(http.MethodGet, "http://abc.com/b.jpg", nil)
+	l.reformat(req)
+	l.rewriteURL(req)
+	if req.URL.Path != "/b.jpg" {
+		t.Errorf("expected the upstream only rule to be skipped, got %s", req.URL)
+	}
+
+	// the edge rewrote the URL for the mid
+	l.cfg.Node.Type = coCfg.CacheNodeMid
+	req = httptest.NewRequest(http.MethodGet, "http://abc.com/v1/a.js", nil)
+	l.reformat(req)
+	l.rewriteURL(req)
+	if req.URL.Path != "/v1/a.js" {
+		t.Errorf("expected no rewrite on the mid, got %s", req.URL)
+	}
+}
@@ -3,20 +3,35 @@ package frontend
 import (
 	"fmt"
 	"net/http"
-	"strconv"
 	"strings"
 	"errors"
 	"bytes"
 	"io"
 	"time"
 	"log/slog"
+	"github.com/hcl/cdn/cacheNode/cachePolicy"
 	"github.com/hcl/cdn/cacheNode/common"
+	"github.com/hcl/cdn/cacheNode/config"
 )
 
 type Validator struct {
 	BackendRequest common.CachedRequestHandler
 	RespFromBackend http.Response
 	ExpiredCnt, UsableCnt uint32
+	Config *config.RunConfig              // per DS stale serving defaults, optional
+	Refresher *BackgroundRefresher        // stale-while-revalidate refreshes, optional
+}
+
+// staleDefaults returns the stale-while-revalidate and stale-if-error defaults of the DS of the request
+func (v *Validator) staleDefaults(req *http.Request) (whileRevalidate time.Duration, ifError time.Duration) {
+	if v.Config == nil {
+		return 0, 0
+	}
+	ds, err := v.Config.DSLookup(req)
+	if err != nil {
+		return 0, 0
+	}
+	return time.Duration(ds.StaleWhileRevalidate) * time.Second, time.Duration(ds.StaleIfError) * time.Second
 }
 
 //CopyResponse copies the response headers and body from one response to another
@@ -54,45 +69,12 @@ func CopyResponse(src *http.Response, dest *http.Response) error {
 	return nil
 }
 
-// IsResourceUsable checks if the resource is still usable based on the Age and Max-Age headers
+// IsResourceUsable checks if the resource is still usable based on the cache policy, otherwise revalidates it with Backend.ReDo()
 func (v *Validator) IsResourceUsable(resp *http.Response, req *http.Request) (bool, error) {
 	slog.Info("FE validator.go : IsResourceUsable() - Start")
 
-	// Get Value of the Age header field from Response
-	ageStr := resp.Header.Get("Age")
-	age := 0
-	if len(ageStr) > 0 {
-		var err error
-		// Convert from string to integer
-		age, err = strconv.Atoi(ageStr)
-		if err != nil {
-			slog.Error("FE validator.go : Invalid Age header", "error", err.Error())
-			return false, fmt.Errorf("invalid Age header: %v", err)
-		}
-	}
-
-	cacheControl := resp.Header.Get("Cache-Control")
-	var maxAge int
-	if cacheControl != "" {
-		for _, directive := range strings.Split(cacheControl, ",") {
-			directive = strings.TrimSpace(directive)
-			// Use 'Prefix' logic to extract the max-age value in seconds
-			if strings.HasPrefix(directive, "max-age=") {
-				// Extract the time value for max-age directive in string
-				maxAgeStr := strings.TrimPrefix(directive, "max-age=")
-				var err error
-				// Convert string to integer
-				maxAge, err = strconv.Atoi(maxAgeStr)
-				if err != nil {
-					slog.Error("FE validator.go : Invalid Max-Age value", "error", err.Error())
-					return false, fmt.Errorf("invalid Max-Age value: %v", err)
-				}
-			}
-		}
-	}
-
-	// Compare Age and Max-Age
-	if maxAge > 0 && age <= maxAge {
+	// Freshness per RFC 9111 : s-maxage, max-age, Expires, heuristic, and the request Cache-Control directives
+	if cachePolicy.CanServeStored(req, resp.Header) {
 		slog.Info("FE validator.go : Resource is usable")
 		slog.Info("FE validator.go : IsResourceUsable() - End (Storage SUCCESS)")
 
@@ -100,11 +82,30 @@ func (v *Validator) IsResourceUsable(resp *http.Response, req *http.Request) (bo
 		resp.Header.Add("X-Is-Cached", "1")
 
 		return true, nil // Resource is usable
-	} else if (maxAge < age || maxAge == 0) {
+	} else {
+		swrDefault, sieDefault := v.staleDefaults(req)
+		if v.Refresher != nil && cachePolicy.CanServeWhileRevalidate(req, resp.Header, swrDefault) {
+			// Serve the stale copy now, the refresh happens in background
+			slog.Info(fmt.Sprintf("FE validator.go : Resource stale, serving while revalidating, Cache-Control : %s, age : %s", resp.Header.Get("Cache-Control"), resp.Header.Get("Age")))
+			v.Refresher.DoBg(req, resp)
+			resp.Header.Add("X-Is-Cached", "1")
+			return true, nil
+		}
+
 		// Resource expired - invoke Backend.ReDo()
-		slog.Info(fmt.Sprintf("FE validator.go : WARN : Resource expired, calling Backend.ReDo(), maxAge : %d, age : %d", maxAge, age))
+		slog.Info(fmt.Sprintf("FE validator.go : WARN : Resource expired, calling Backend.ReDo(), Cache-Control : %s, age : %s", resp.Header.Get("Cache-Control"), resp.Header.Get("Age")))
 		rspFromBknd, err := v.BackendRequest.ReDo(req, resp)
 
+		// Upstream failed or timed out, the stale copy may still be served
+		if (err != nil || rspFromBknd.StatusCode >= http.StatusInternalServerError) && cachePolicy.CanServeOnError(req, resp.Header, sieDefault) {
+			slog.Info("FE validator.go : Backend.ReDo() failed, serving stale resource")
+			if err == nil && rspFromBknd.Body != nil {
+				rspFromBknd.Body.Close()
+			}
+			resp.Header.Add("X-Is-Cached", "1")
+			return true, nil
+		}
+
 		if err != nil {
 			slog.Error("FE validator.go : Backend.ReDo() failed ", "error", err.Error())
 			return false, err
@@ -131,10 +132,6 @@ func (v *Validator) IsResourceUsable(resp *http.Response, req *http.Request) (bo
 		}
 	}
 
-	// Final
-	slog.Info("FE validator.go : Resource is not usable")
-	slog.Info("FE validator.go : IsResourceUsable() - End")
-	return false, nil
 }
 
 // Do method handles the main validator process, checking if a resource should be fetched again from the backend
@@ -155,6 +152,14 @@ func (v *Validator) Do(req *http.Request, oResp *http.Response) (*http.Response,
 	bkndResp.Header.Set("User-Agent", "Go Server Agent/1.23.4")
 
 	v.RespFromBackend.StatusCode = http.StatusInternalServerError           
+	// Client does not want the upstream to be contacted
+	if cachePolicy.OnlyIfCached(req) && !cachePolicy.CanServeStored(req, oResp.Header) {
+		slog.Info("FE validator.go : Stored resource is stale and only-if-cached requested")
+		if oResp.Body != nil {
+			oResp.Body.Close()
+		}
+		return GatewayTimeout(req), nil
+	}
 	// Check the usability status of the Storage Response
 	isUsable, err := v.IsResourceUsable(oResp, req)
 
@@ -195,3 +200,18 @@ func (v *Validator) Do(req *http.Request, oResp *http.Response) (*http.Response,
 	bkndResp.Body = io.NopCloser(strings.NewReader(errStr.Error()))
 	return bkndResp, nil
 }
+
+// GatewayTimeout is the answer to only-if-cached requests which cannot be served from the cache
+func GatewayTimeout(req *http.Request) *http.Response {
+	resp := &http.Response{
+		StatusCode:    http.StatusGatewayTimeout,
+		Status:        "504 Gateway Timeout",
+		Header:        make(http.Header),
+		Body:          http.NoBody,
+		ContentLength: 0,
+		Request:       req,
+	}
+	resp.Header.Set("Content-Length", "0")
+	resp.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	return resp
+}
@@ -0,0 +1,147 @@
+package headerRewrite
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+
+	coCfg "github.com/hcl/cdn/common/config"
+)
+
+// RequestIDHeader carries the id of the client request, set by the edge & forwarded upstream
+const RequestIDHeader = "X-Request-Id"
+
+// Vars are the values of the variables in the rule values
+type Vars struct {
+	ClientIP  string // ${client_ip}
+	DSName    string // ${ds_name}
+	NodeName  string // ${node_name}
+	RequestID string // ${request_id}
+}
+
+// NewVars takes the variables of the request to the DS on the node
+func NewVars(req *http.Request, dsName string, nodeName string) Vars {
+	clientIP := req.RemoteAddr
+	if host, _, err := net.SplitHostPort(req.RemoteAddr); err == nil {
+		clientIP = host
+	}
+	return Vars{
+		ClientIP:  clientIP,
+		DSName:    dsName,
+		NodeName:  nodeName,
+		RequestID: req.Header.Get(RequestIDHeader),
+	}
+}
+
+// Expand substitutes the variables in s, unknown variables are left as they are
+func (v Vars) Expand(s string) string {
+	if !strings.Contains(s, "${") {
+		return s
+	}
+	return strings.NewReplacer(
+		"${client_ip}", v.ClientIP,
+		"${ds_name}", v.DSName,
+		"${node_name}", v.NodeName,
+		"${request_id}", v.RequestID,
+	).Replace(s)
+}
+
+// NewRequestID returns a random id for a client request
+func NewRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// compiled regexes of the rules, the rules change seldom
+var regexCache sync.Map
+
+func compile(expr string) (*regexp.Regexp, error) {
+	if re, ok := regexCache.Load(expr); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	regexCache.Store(expr, re)
+	return re, nil
+}
+
+// matches evaluates the conditions of the rule on the client request
+func matches(rule *coCfg.RewriteRule, req *http.Request) bool {
+	if rule.IfHeader != "" {
+		values, ok := req.Header[http.CanonicalHeaderKey(rule.IfHeader)]
+		if !ok {
+			return false
+		}
+		if rule.IfHeaderMatch != "" {
+			re, err := compile(rule.IfHeaderMatch)
+			if err != nil {
+				slog.Error("headerRewrite : Invalid header condition", "header", rule.HeaderName, "regex", rule.IfHeaderMatch)
+				return false
+			}
+			if !re.MatchString(strings.Join(values, ", ")) {
+				return false
+			}
+		}
+	}
+	if rule.IfPath != "" {
+		re, err := compile(rule.IfPath)
+		if err != nil {
+			slog.Error("headerRewrite : Invalid path condition", "header", rule.HeaderName, "regex", rule.IfPath)
+			return false
+		}
+		if !re.MatchString(req.URL.Path) {
+			return false
+		}
+	}
+	return true
+}
+
+// Apply runs the rules of the phase on hdr in their order. req is the client request the conditions
+// are evaluated on, vars the values of the variables.
+func Apply(rules []coCfg.RewriteRule, phase int, hdr http.Header, req *http.Request, vars Vars) {
+	for i := range rules {
+		rule := &rules[i]
+		if rule.Phase != phase || !matches(rule, req) {
+			continue
+		}
+		value := vars.Expand(rule.Value)
+		switch rule.Operation {
+		case coCfg.HdrReWriteOpAdd:
+			hdr.Set(rule.HeaderName, value)
+		case coCfg.HdrReWriteOpOverwrite:
+			if len(hdr.Values(rule.HeaderName)) > 0 {
+				hdr.Set(rule.HeaderName, value)
+			}
+		case coCfg.HdrReWriteOpDelete:
+			hdr.Del(rule.HeaderName)
+		case coCfg.HdrReWriteOpAppend:
+			if existing := hdr.Values(rule.HeaderName); len(existing) > 0 {
+				hdr.Set(rule.HeaderName, strings.Join(existing, ", ")+", "+value)
+			} else {
+				hdr.Set(rule.HeaderName, value)
+			}
+		case coCfg.HdrReWriteOpReplace:
+			re, err := compile(rule.Pattern)
+			if err != nil {
+				slog.Error("headerRewrite : Invalid pattern", "header", rule.HeaderName, "regex", rule.Pattern)
+				continue
+			}
+			key := http.CanonicalHeaderKey(rule.HeaderName)
+			for j, v := range hdr[key] {
+				hdr[key][j] = re.ReplaceAllString(v, value)
+			}
+		default:
+			slog.Error("headerRewrite : Invalid operation", "header", rule.HeaderName, "operation", rule.Operation)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package headerRewrite
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	coCfg "github.com/hcl/cdn/common/config"
+)
+
+func TestApply(t *testing.T) {
+	rules := []coCfg.RewriteRule{
+		{HeaderName: "X-Served-By", Operation: coCfg.HdrReWriteOpAdd, Value: "${node_name}/${ds_name}"},
+		{HeaderName: "Server", Operation: coCfg.HdrReWriteOpOverwrite, Value: "cdn"},
+		{HeaderName: "X-Missing", Operation: coCfg.HdrReWriteOpOverwrite, Value: "never"},
+		{HeaderName: "X-Powered-By", Operation: coCfg.HdrReWriteOpDelete},
+		{HeaderName: "Cache-Control", Operation: coCfg.HdrReWriteOpAppend, Value: "no-transform"},
+		{HeaderName: "Location", Operation: coCfg.HdrReWriteOpReplace, Pattern: `^http://origin\.abc\.com/(.*)$`, Value: "https://abc.com/$1"},
+		{HeaderName: "X-Mobile", Operation: coCfg.HdrReWriteOpAdd, Value: "1", IfHeader: "User-Agent", IfHeaderMatch: "(?i)mobile"},
+		{HeaderName: "X-Video", Operation: coCfg.HdrReWriteOpAdd, Value: "1", IfPath: "^/video/"},
+		{HeaderName: "X-Forwarded-For", Operation: coCfg.HdrReWriteOpAppend, Value: "${client_ip}", Phase: coCfg.HdrReWritePhaseRequest},
+	}
+	req := httptest.NewRequest(http.MethodGet, "http://abc.com/img/a.png", nil)
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Linux; Android 14) Mobile")
+	req.RemoteAddr = "10.1.2.3:40000"
+	req.Header.Set(RequestIDHeader, "abc123")
+	vars := NewVars(req, "abc", "edge1")
+	if vars.ClientIP != "10.1.2.3" || vars.RequestID != "abc123" {
+		t.Fatalf("unexpected variables %+v", vars)
+	}
+
+	hdr := http.Header{}
+	hdr.Set("Server", "nginx")
+	hdr.Set("X-Powered-By", "php")
+	hdr.Set("Cache-Control", "max-age=60")
+	hdr.Set("Location", "http://origin.abc.com/img/b.png")
+	Apply(rules, coCfg.HdrReWritePhaseResponse, hdr, req, vars)
+
+	expected := map[string]string{
+		"X-Served-By":     "edge1/abc",
+		"Server":          "cdn",
+		"X-Missing":       "",
+		"X-Powered-By":    "",
+		"Cache-Control":   "max-age=60, no-transform",
+		"Location":        "https://abc.com/img/b.png",
+		"X-Mobile":        "1",
+		"X-Video":         "",
+		"X-Forwarded-For": "",
+	}
+	for name, want := range expected {
+		if got := hdr.Get(name); got != want {
+			t.Errorf("%s: got %q, want %q", name, got, want)
+		}
+	}
+
+	// request phase only
+	upstream := http.Header{}
+	upstream.Set("X-Forwarded-For", "192.0.2.1")
+	Apply(rules, coCfg.HdrReWritePhaseRequest, upstream, req, vars)
+	if len(upstream) != 1 || upstream.Get("X-Forwarded-For") != "192.0.2.1, 10.1.2.3" {
+		t.Errorf("unexpected request headers %v", upstream)
+	}
+}
+
+func TestExpand(t *testing.T) {
+	vars := Vars{ClientIP: "::1", DSName: "abc", NodeName: "mid1", RequestID: "42"}
+	if got := vars.Expand("${request_id}@${node_name} ${unknown}"); got != "42@mid1 ${unknown}" {
+		t.Errorf("unexpected expansion %q", got)
+	}
+	if id := NewRequestID(); len(id) != 32 || id == NewRequestID() {
+		t.Errorf("expected unique request ids, got %q", id)
+	}
+}
@@ -21,21 +21,25 @@ const CONFIG_FILENAME string = "config.json"
 const CONFIG_DIR string = "."
 
 var CDN_DIR string = path.Join(".", "cdn")
+var GEOIP_FILE string = path.Join(".", "geoip.csv")
 var mgmtPort *uint
 var promPort *uint
 var cdnDir *string
 var configDir *string
+var geoIPFile *string
 
 func parseFlags() {
 	promPort = flag.Uint("promPort", observability.DEFAULT_PROM_PORT, "Prometheus Server port")
 	mgmtPort = flag.Uint("mgmtport", cMgmtApi.DEFAULT_MGMT_PORT, "MGMT Server port")
 	configDir = flag.String("dir", CONFIG_DIR, "Directory to persist config files")
 	cdnDir = flag.String("cdndir", CDN_DIR, "Directory to persist CDN cache content")
+	geoIPFile = flag.String("geoipdb", GEOIP_FILE, "CSV IP range to country database, reloaded on change")
 	flag.Parse()
 	slog.Info("Flags:", "mgmtPort", *mgmtPort)
 	slog.Info("Flags:", "promPort", *promPort)
 	slog.Info("Flags:", "cdnDir", *cdnDir)
 	slog.Info("Flags:", "configDir", *configDir)
+	slog.Info("Flags:", "geoIPFile", *geoIPFile)
 }
 
 func main() {
@@ -103,7 +107,7 @@ func main() {
 		return
 	}
 
-	err = frontend.Init(ctx, &wg, cfg, backendHandler, storageHandler, observabilityHandler)
+	err = frontend.Init(ctx, &wg, cfg, backendHandler, storageHandler, observabilityHandler, *geoIPFile)
 	if err != nil {
 		slog.Error("Error initing frontend", "error", err)
 		return
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"log/slog"
 
+	"github.com/hcl/cdn/cacheNode/backend"
 	endec "github.com/hcl/cdn/common/mgmtApi" // Import the endec package with an alias
 	pb "github.com/hcl/cdn/common/mgmtApi"
 )
@@ -91,3 +92,22 @@ func (s *MgmtApiServer) InvalidateCacheStatus(ctx context.Context, req *pb.Inval
 		Status: "Cache status invalidated successfully",
 	}, nil
 }
+
+// OriginHealth returns the health of the origins probed by the node
+func (s *MgmtApiServer) OriginHealth(ctx context.Context, req *pb.OriginHealthRequest) (*pb.OriginHealthResponse, error) {
+	resp := &pb.OriginHealthResponse{}
+	for _, status := range backend.OriginHealth(req.Ds) {
+		origin := &pb.OriginStatus{
+			Ds:        status.DS,
+			Url:       status.URL,
+			Backup:    status.Backup,
+			Healthy:   status.Healthy,
+			LastError: status.LastError,
+		}
+		if !status.LastCheck.IsZero() {
+			origin.LastCheck = status.LastCheck.Unix()
+		}
+		resp.Origins = append(resp.Origins, origin)
+	}
+	return resp, nil
+}
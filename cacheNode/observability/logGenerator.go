@@ -16,6 +16,8 @@ func logEventToFile(eventType string, logMessage string) error {
 		"BackendEvent":  "backend.log",
 		"StorageEvent":  "storage.log",
 		"StorageDiskMetricsEvent": "storagedisk.log",
+		"OriginHealthEvent": "originhealth.log",
+		"CircuitBreakerEvent": "circuitbreaker.log",
 	}
 	fileName, exists := fileMapping[eventType]
 	if !exists {
@@ -36,8 +38,8 @@ func logEventToFile(eventType string, logMessage string) error {
 // logFrontendEvent processes and logs FrontendEvent
 func logFrontendEvent(e FrontendEvent) {
 	logMessage := fmt.Sprintf(
-		"Timestamp: %s, ClientIP: %s, URL: %s, UserAgent: %s, ResponseTime: %dms, TTFB: %dms, Bytes: %d, StatusCode: %d, CacheHit: %t",
-		e.Timestamp.Format(time.RFC3339), e.ClientIP, e.URL, e.UserAgent, e.ResponseTime, e.TTFB, e.Bytes, e.StatusCode, e.CacheHit,
+		"Timestamp: %s, ClientIP: %s, URL: %s, UserAgent: %s, ResponseTime: %dms, TTFB: %dms, Bytes: %d, StatusCode: %d, CacheHit: %t, Protocol: %s, DS: %s, DenyReason: %s, Collapse: %s",
+		e.Timestamp.Format(time.RFC3339), e.ClientIP, e.URL, e.UserAgent, e.ResponseTime, e.TTFB, e.Bytes, e.StatusCode, e.CacheHit, e.Protocol, e.DS, e.DenyReason, e.Collapse,
 	)
 	if err := logEventToFile("FrontendEvent", logMessage); err != nil {
 		slog.Info("Error logging frontend event", "error", err)
@@ -47,14 +49,36 @@ func logFrontendEvent(e FrontendEvent) {
 // logBackendEvent processes and logs BackendEvent
 func logBackendEvent(e BackendEvent) {
 	logMessage := fmt.Sprintf(
-		"Timestamp: %s, OriginServerIP: %s, URL: %s, ResponseTime: %dms, TTFB: %dms, Bytes: %d, StatusCode: %d",
-		e.Timestamp.Format(time.RFC3339), e.OriginServerIP, e.URL, e.ResponseTime, e.TTFB, e.Bytes, e.StatusCode,
+		"Timestamp: %s, OriginServerIP: %s, URL: %s, ResponseTime: %dms, TTFB: %dms, Bytes: %d, StatusCode: %d, DS: %s, Error: %s",
+		e.Timestamp.Format(time.RFC3339), e.OriginServerIP, e.URL, e.ResponseTime, e.TTFB, e.Bytes, e.StatusCode, e.DS, e.Error,
 	)
 	if err := logEventToFile("BackendEvent", logMessage); err != nil {
 		slog.Info("Error logging backend event", "error", err)
 	}
 }
 
+// logOriginHealthEvent processes and logs OriginHealthEvent
+func logOriginHealthEvent(e OriginHealthEvent) {
+	logMessage := fmt.Sprintf(
+		"Timestamp: %s, DS: %s, Origin: %s, Backup: %t, Healthy: %t, ResponseTime: %dms, Error: %s",
+		e.Timestamp.Format(time.RFC3339), e.DS, e.Origin, e.Backup, e.Healthy, e.ResponseTime, e.Error,
+	)
+	if err := logEventToFile("OriginHealthEvent", logMessage); err != nil {
+		slog.Info("Error logging origin health event", "error", err)
+	}
+}
+
+// logCircuitBreakerEvent processes and logs CircuitBreakerEvent
+func logCircuitBreakerEvent(e CircuitBreakerEvent) {
+	logMessage := fmt.Sprintf(
+		"Timestamp: %s, DS: %s, Host: %s, From: %s, To: %s, Failures: %d, Error: %s",
+		e.Timestamp.Format(time.RFC3339), e.DS, e.Host, e.From, e.To, e.Failures, e.Error,
+	)
+	if err := logEventToFile("CircuitBreakerEvent", logMessage); err != nil {
+		slog.Info("Error logging circuit breaker event", "error", err)
+	}
+}
+
 // logStorageEvent processes and logs StorageEvent
 func logStorageEvent(e StorageEvent) {
     logMessage := fmt.Sprintf(
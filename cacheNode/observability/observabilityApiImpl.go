@@ -57,6 +57,22 @@ func (o *ObservabilityHandlerImpl) RecordEventStorageDiskMetrics(event StorageDi
 	}
 }
 
+func (o *ObservabilityHandlerImpl) RecordEventOriginHealth(event OriginHealthEvent) {
+	slog.Info("origin health event received at observability API")
+	select {
+	case o.events <- event:
+	case <-o.ctx.Done():
+	}
+}
+
+func (o *ObservabilityHandlerImpl) RecordEventCircuitBreaker(event CircuitBreakerEvent) {
+	slog.Info("circuit breaker event received at observability API")
+	select {
+	case o.events <- event:
+	case <-o.ctx.Done():
+	}
+}
+
 func (o *ObservabilityHandlerImpl) runPromAgg(ctx context.Context, wg *sync.WaitGroup) {
 	defer wg.Done() // Ensure the wait group counter is decremented when the function finishes.
 	if o.promPort <= 0 {
@@ -110,6 +126,12 @@ func (o *ObservabilityHandlerImpl) readEventFromChannel(ctx context.Context, wg
 			case StorageDiskMetricsEvent:
 				processsStorageDiskMetricsEvent(e)
 				logStorageDiskMetricsEvent(e)
+			case OriginHealthEvent:
+				processsOriginHealthEvent(e)
+				logOriginHealthEvent(e)
+			case CircuitBreakerEvent:
+				processsCircuitBreakerEvent(e)
+				logCircuitBreakerEvent(e)
 			default:
 				slog.Warn("Unknown event type", "event", event)
 			}
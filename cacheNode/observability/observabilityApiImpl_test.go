@@ -34,6 +34,8 @@ func TestRecordEventFunctions(t *testing.T) {
   handler.RecordEventBackend(BackendEvent{})
   handler.RecordEventStorage(StorageEvent{})
   handler.RecordEventStorageDiskMetrics(StorageDiskMetricsEvent{})
+  handler.RecordEventOriginHealth(OriginHealthEvent{})
+  handler.RecordEventCircuitBreaker(CircuitBreakerEvent{})
  }()
 
  // Allow time for events to be processed
@@ -54,8 +56,11 @@ func TestReadEventFromChannel(t *testing.T) {
  // Send different event types
  handler.events <- FrontendEvent{}
  handler.events <- BackendEvent{}
+ handler.events <- BackendEvent{DS: "ds1", OriginServerIP: "origin1:443", Error: "tls_verify"}
  handler.events <- StorageEvent{}
  handler.events <- StorageDiskMetricsEvent{}
+ handler.events <- OriginHealthEvent{DS: "ds1", Origin: "http://origin1", Healthy: true}
+ handler.events <- CircuitBreakerEvent{DS: "ds1", Host: "origin1", From: "closed", To: "open", Failures: 5}
  //handler.events <- MockEvent{} // Unknown event
 
  time.Sleep(100 * time.Millisecond)
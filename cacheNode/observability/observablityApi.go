@@ -9,6 +9,8 @@ type ObservabilityHandler interface {
 	RecordEventBackend(BackendEvent)
 	RecordEventStorage(StorageEvent)
 	RecordEventStorageDiskMetrics(StorageDiskMetricsEvent)
+	RecordEventOriginHealth(OriginHealthEvent)
+	RecordEventCircuitBreaker(CircuitBreakerEvent)
 }
 
 type FrontendEvent struct{
@@ -21,6 +23,10 @@ type FrontendEvent struct{
 	Bytes int  					//number of bytes of content
 	StatusCode int  			//http response code to client 
  	CacheHit bool  				// true or false
+	Protocol string				// negotiated protocol e.g. HTTP/1.1, HTTP/2.0
+	DS string					// name of the Delivery Service, "" if none matched
+	DenyReason string			// why the request was denied e.g. acl_ip, acl_country, token, rate_client, rate_ds, concurrency; "" if served
+	Collapse string				// role of the request in collapsing: leader, waiter, promoted, timeout, canceled, variant; "" if not collapsed
  }
 
 type BackendEvent struct{
@@ -31,6 +37,28 @@ type BackendEvent struct{
  	TTFB int					//time to first byte in milliseconds
 	Bytes int					//number of bytes of content
 	StatusCode int				//http response code to client
+	DS string					//name of the Delivery Service, "" if none
+	Error string				//kind of the failure: tls_verify, tls_handshake, connect, timeout, reset, other; "" if the upstream answered
+}
+
+type OriginHealthEvent struct{
+	Timestamp time.Time			//time of the probe
+	DS string					//name of the Delivery Service
+	Origin string				//origin probed
+	Backup bool					//backup origin of the DS
+	Healthy bool				//health of the origin after the probe
+	ResponseTime int			//response time of the probe in miliseconds
+	Error string				//why the probe failed, "" if it passed
+}
+
+type CircuitBreakerEvent struct{
+	Timestamp time.Time			//time of the transition
+	DS string					//name of the Delivery Service of the fetch
+	Host string					//upstream host of the circuit
+	From string					//state left: closed, open, half-open
+	To string					//state entered: closed, open, half-open
+	Failures int				//failed fetches in a row
+	Error string				//last failure, "" when the circuit closes
 }
 
 type StorageEvent struct{
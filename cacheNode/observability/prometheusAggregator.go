@@ -8,13 +8,39 @@ func processsFrontendEvent(e FrontendEvent) {
 	statusCodeStr := strconv.Itoa(e.StatusCode) // Convert StatusCode to string
 	cacheHitStr := strconv.FormatBool(e.CacheHit)
 
-	fe_total_req_count.WithLabelValues(e.ClientIP, e.URL, e.UserAgent, statusCodeStr, cacheHitStr).Inc()
-	fe_total_bytes_transferred.WithLabelValues(e.ClientIP, e.URL, e.UserAgent, statusCodeStr, cacheHitStr).Add(float64(e.Bytes))
-	fe_req_time_to_serve_msec.WithLabelValues(e.ClientIP, e.URL, e.UserAgent, statusCodeStr, cacheHitStr).Observe(float64(e.ResponseTime))
-	fe_req_ttfb_msec.WithLabelValues(e.ClientIP, e.URL, e.UserAgent, statusCodeStr, cacheHitStr).Observe(float64(e.ResponseTime))
+	fe_total_req_count.WithLabelValues(e.ClientIP, e.URL, e.UserAgent, statusCodeStr, cacheHitStr, e.Protocol).Inc()
+	fe_total_bytes_transferred.WithLabelValues(e.ClientIP, e.URL, e.UserAgent, statusCodeStr, cacheHitStr, e.Protocol).Add(float64(e.Bytes))
+	fe_req_time_to_serve_msec.WithLabelValues(e.ClientIP, e.URL, e.UserAgent, statusCodeStr, cacheHitStr, e.Protocol).Observe(float64(e.ResponseTime))
+	fe_req_ttfb_msec.WithLabelValues(e.ClientIP, e.URL, e.UserAgent, statusCodeStr, cacheHitStr, e.Protocol).Observe(float64(e.ResponseTime))
+	if e.DenyReason != "" {
+		fe_denied_req_count.WithLabelValues(e.DS, e.URL, e.DenyReason).Inc()
+	}
+	if e.Collapse != "" {
+		processCollapse(e)
+	}
+}
+
+// role of the requests served by the fetch of another request, frontend.CollapseWaiter
+const collapseWaiter = "waiter"
+
+// collapsed requests of each DS, all roles & waiters, only the aggregator goroutine uses them
+var collapseTotal = map[string]int{}
+var collapseWaiters = map[string]int{}
+
+func processCollapse(e FrontendEvent) {
+	fe_collapse_req_count.WithLabelValues(e.DS, e.Collapse).Inc()
+	collapseTotal[e.DS]++
+	if e.Collapse == collapseWaiter {
+		collapseWaiters[e.DS]++
+	}
+	fe_collapse_ratio.WithLabelValues(e.DS).Set(float64(collapseWaiters[e.DS]) / float64(collapseTotal[e.DS]))
 }
 
 func processsBackendEvent(e BackendEvent) {
+	if e.Error != "" {
+		be_error_count.WithLabelValues(e.DS, e.OriginServerIP, e.Error).Inc()
+		return
+	}
 	statusCodeStr := strconv.Itoa(e.StatusCode) // Convert StatusCode to string
 
 	be_total_req_count.WithLabelValues(e.OriginServerIP, e.URL, statusCodeStr).Inc()
@@ -23,6 +49,27 @@ func processsBackendEvent(e BackendEvent) {
 	be_req_ttfb_msec.WithLabelValues(e.OriginServerIP, e.URL, statusCodeStr).Observe(float64(e.ResponseTime))
 }
 
+func processsOriginHealthEvent(e OriginHealthEvent) {
+	role := "primary"
+	if e.Backup {
+		role = "backup"
+	}
+	healthy := 0.0
+	if e.Healthy {
+		healthy = 1
+	}
+	be_origin_healthy.WithLabelValues(e.DS, e.Origin, role).Set(healthy)
+	be_origin_probe_time_msec.WithLabelValues(e.DS, e.Origin).Observe(float64(e.ResponseTime))
+}
+
+// value of be_circuit_state for each state of a circuit
+var circuitStates = map[string]float64{"closed": 0, "half-open": 1, "open": 2}
+
+func processsCircuitBreakerEvent(e CircuitBreakerEvent) {
+	be_circuit_state.WithLabelValues(e.Host).Set(circuitStates[e.To])
+	be_circuit_transition_count.WithLabelValues(e.Host, e.To).Inc()
+}
+
 func processsStorageEvent(e StorageEvent) {
 	storage_event_count.WithLabelValues(e.URL, e.Operation).Inc()
 	storage_total_bytes_served.WithLabelValues(e.URL, e.Operation).Add(float64(e.Bytes))
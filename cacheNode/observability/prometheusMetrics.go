@@ -13,7 +13,7 @@ var (
 			Name:      "fe_total_req_count",
 			Help:      "Total request count for frontend",
 		},
-		[]string{"clientip", "url", "user_agent", "status_code", "cache_hit"},
+		[]string{"clientip", "url", "user_agent", "status_code", "cache_hit", "protocol"},
 	)
 	fe_total_bytes_transferred = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
@@ -21,7 +21,7 @@ var (
 			Name:      "fe_total_bytes_transferred",
 			Help:      "Total bytes transferred for frontend",
 		},
-		[]string{"clientip", "url", "user_agent", "status_code", "cache_hit"},
+		[]string{"clientip", "url", "user_agent", "status_code", "cache_hit", "protocol"},
 	)
 	fe_req_time_to_serve_msec = prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
@@ -30,7 +30,7 @@ var (
 			Help:      "Time taken to serve frontend requests",
 			Buckets:   []float64{10, 100, 200, 500, 1000, 2000, 5000, 10000},
 		},
-		[]string{"clientip", "url", "user_agent", "status_code", "cache_hit"},
+		[]string{"clientip", "url", "user_agent", "status_code", "cache_hit", "protocol"},
 	)
 	fe_req_ttfb_msec = prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
@@ -39,7 +39,32 @@ var (
 			Help:      "Time to first byte for frontend",
 			Buckets:   []float64{10, 100, 200, 500, 1000, 2000, 5000, 10000},
 		},
-		[]string{"clientip", "url", "user_agent", "status_code", "cache_hit"},
+		[]string{"clientip", "url", "user_agent", "status_code", "cache_hit", "protocol"},
+	)
+
+	fe_denied_req_count = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "namespace_mycdn",
+			Name:      "fe_denied_req_count",
+			Help:      "Requests denied by access control, token authentication or rate limits",
+		},
+		[]string{"ds", "url", "reason"},
+	)
+	fe_collapse_req_count = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "namespace_mycdn",
+			Name:      "fe_collapse_req_count",
+			Help:      "Collapsible requests by role: leader, waiter, promoted, timeout, canceled, variant",
+		},
+		[]string{"ds", "role"},
+	)
+	fe_collapse_ratio = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "namespace_mycdn",
+			Name:      "fe_collapse_ratio",
+			Help:      "Share of the collapsible requests served by the fetch of another request",
+		},
+		[]string{"ds"},
 	)
 
 	// Backend Metrics
@@ -77,6 +102,47 @@ var (
 		},
 		[]string{"origin_ip", "url", "status_code"},
 	)
+	be_error_count = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "namespace_mycdn",
+			Name:      "be_error_count",
+			Help:      "Failed upstream fetches by kind: tls_verify, tls_handshake, connect, timeout, reset, other",
+		},
+		[]string{"ds", "origin_ip", "error"},
+	)
+	be_origin_healthy = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "namespace_mycdn",
+			Name:      "be_origin_healthy",
+			Help:      "Health of the origins probed by the node, 1 healthy 0 down",
+		},
+		[]string{"ds", "origin", "role"},
+	)
+	be_origin_probe_time_msec = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "namespace_mycdn",
+			Name:      "be_origin_probe_time",
+			Help:      "Response time of the origin health probes",
+			Buckets:   []float64{10, 100, 200, 500, 1000, 2000, 5000, 10000},
+		},
+		[]string{"ds", "origin"},
+	)
+	be_circuit_state = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "namespace_mycdn",
+			Name:      "be_circuit_state",
+			Help:      "State of the circuit of the upstream hosts, 0 closed 1 half-open 2 open",
+		},
+		[]string{"host"},
+	)
+	be_circuit_transition_count = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "namespace_mycdn",
+			Name:      "be_circuit_transition_count",
+			Help:      "Transitions of the circuit of the upstream hosts by the state entered",
+		},
+		[]string{"host", "state"},
+	)
 
 	// Storage Metrics
 	storage_event_count = prometheus.NewGaugeVec(
@@ -140,10 +206,18 @@ func RegisterPromMetrics() {
 		fe_total_bytes_transferred,
 		fe_req_time_to_serve_msec,
 		fe_req_ttfb_msec,
+		fe_denied_req_count,
+		fe_collapse_req_count,
+		fe_collapse_ratio,
 		be_total_req_count,
 		be_total_bytes_transferred,
 		be_req_response_time_msec,
 		be_req_ttfb_msec,
+		be_error_count,
+		be_origin_healthy,
+		be_origin_probe_time_msec,
+		be_circuit_state,
+		be_circuit_transition_count,
 		storage_event_count,
 		storage_total_bytes_served,
 		storage_req_response_time_msec,
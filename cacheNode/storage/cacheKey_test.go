@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hcl/cdn/cacheNode/config"
+	coCfg "github.com/hcl/cdn/common/config"
+)
+
+/*
+ * Test Functions
+ *		TestCacheKey
+ */
+
+func TestCacheKey(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	cfg := &config.RunConfig{
+		ServiceList: &coCfg.DeliveryServices{
+			ServiceList: []coCfg.DeliveryService{{
+				Name:      "abc",
+				ClientURL: "http://abc.com",
+				OriginURL: "http://origin.abc.com",
+				CacheKey: coCfg.CacheKey{
+					QueryMode:   coCfg.CacheKeyQueryDenyList,
+					QueryParams: []string{"utm_*"},
+					SortQuery:   true,
+				},
+			}},
+		},
+	}
+	storageHandler, err := Init(ctx, &wg, t.TempDir(), cfg, nil)
+	if err != nil {
+		t.Fatalf("TestCacheKey:Failed to init storage")
+	}
+
+	store := func(URL string, data string) {
+		request, _ := http.NewRequest(http.MethodPost, URL, strings.NewReader(data))
+		request.Header.Set("Cache-Control", "max-age=600")
+		response, err := storageHandler.Do(request)
+		if err != nil || response.StatusCode != http.StatusCreated {
+			t.Fatalf("TestCacheKey:Failed to store %s", URL)
+		}
+	}
+	read := func(URL string) (int, string) {
+		request, _ := http.NewRequest(http.MethodGet, URL, nil)
+		response, err := storageHandler.Do(request)
+		if err != nil {
+			t.Fatalf("TestCacheKey:Read failed %v", err)
+		}
+		if response.StatusCode != http.StatusOK {
+			return response.StatusCode, ""
+		}
+		defer response.Body.Close()
+		body, _ := io.ReadAll(response.Body)
+		return response.StatusCode, string(body)
+	}
+
+	store("http://abc.com/key/app.js?v=1", "version 1")
+	store("http://abc.com/key/app.js?v=2&lang=en", "version 2")
+
+	if status, body := read("http://abc.com/key/app.js?utm_source=mail&v=1"); status != http.StatusOK || body != "version 1" {
+		t.Errorf("TestCacheKey:Expected version 1, got %d %q", status, body)
+	}
+	if status, body := read("http://abc.com/key/app.js?lang=en&v=2"); status != http.StatusOK || body != "version 2" {
+		t.Errorf("TestCacheKey:Expected version 2 with sorted query, got %d %q", status, body)
+	}
+	if status, _ := read("http://abc.com/key/app.js"); status != http.StatusNotFound {
+		t.Errorf("TestCacheKey:Expected a miss without query, got %d", status)
+	}
+
+	// Invalidating the path without query removes every keyed object of the path
+	request, _ := http.NewRequest(http.MethodDelete, "http://abc.com/key/app.js", nil)
+	if response, err := storageHandler.Do(request); err != nil || response.StatusCode != http.StatusOK {
+		t.Fatalf("TestCacheKey:Failed to invalidate")
+	}
+	for _, URL := range []string{"http://abc.com/key/app.js?v=1", "http://abc.com/key/app.js?v=2&lang=en"} {
+		if status, _ := read(URL); status != http.StatusNotFound {
+			t.Errorf("TestCacheKey:Expected %s to be invalidated, got %d", URL, status)
+		}
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"errors"
 	"io/fs"
 	"log/slog"
-	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
@@ -76,16 +75,15 @@ func loadCachedContentInMap(storageObj *StorageHandler) (err error) {
 				return err
 			}
 
-			_, ok1 := metadata["Last-Modified"]
-			_, ok2 := metadata["Age"]
-			_, ok3 := metadata["Cache-Control"]
-			if !ok1 || !ok2 || !ok3 {
+			_, ok := metadata["Age"]
+			if !ok {
 				slog.Error("Storage:CacheEvictor:CDNDATASTORE directory got corrupted. CacheEvictor will behave abnormally...")
 				return errors.New("CDNDATASTORE directory got corrupted")
 			}
 
-			maxAge, age, lastModified, contentLength := validateCacheHeaders(metadata)
-			storageObj.cacheManagerObj.updateCacheContentInMap(maxAge, age, lastModified, contentLength, filepath.Dir(path))
+			// The metadata file is written when the content is stored or revalidated
+			maxAge, age, contentLength := validateCacheHeaders(metadata, info.ModTime())
+			storageObj.cacheManagerObj.updateCacheContentInMap(maxAge, age, info.ModTime(), contentLength, filepath.Dir(path), path)
 			count += 1
 		}
 		return nil
@@ -170,9 +168,7 @@ func deleteStaleContent(limit int, storageObj *StorageHandler) (delCount int) {
 			// Update in-memory stale content map only if content file is present. Otherwise delete the entry from stale content map.
 			_, err := os.Stat(metadata.path)
 			if err == nil {
-				lastModifiedTimestamp, _ := http.ParseTime(metadata.lastModified)
-				age := metadata.age + int(time.Now().Unix()-lastModifiedTimestamp.Unix())
-				storageObj.cacheManagerObj.updateCacheContentInMap(metadata.maxAge, age, metadata.lastModified, metadata.contentLength, metadata.path)
+				storageObj.cacheManagerObj.updateCacheContentInMap(metadata.maxAge, metadata.age, metadata.responseTime, metadata.contentLength, metadata.path, metadata.metadataFile)
 			}
 		}
 	}
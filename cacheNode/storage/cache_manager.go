@@ -4,6 +4,7 @@ import (
 	"log/slog"
 	"sort"
 	"sync"
+	"time"
 )
 
 /*
@@ -17,13 +18,15 @@ import (
  *		logStaleContentMap()
  *		logRemainingCacheDurationSlice()
  *		updateCacheContentInMap()
+ *		removeCacheContentFromMap()
  */
 
 type metadataStruct struct {
 	path          string
+	metadataFile  string    //Metadata file of the content or of one of its variants
 	maxAge        int
-	age           int
-	lastModified  string
+	age           int       //Age of the content when stored
+	responseTime  time.Time //Time the content was stored or revalidated
 	contentLength int
 }
 
@@ -38,7 +41,7 @@ func(cacheManagerObj *cacheManager) pushEntryInMap(remainingCacheDuration int, n
 	cacheManagerObj.mutex.Lock()
 	defer cacheManagerObj.mutex.Unlock()
 	for i, value := range cacheManagerObj.staleContentMap[remainingCacheDuration] {
-		if value.path == newEntry.path {
+		if value.path == newEntry.path && value.metadataFile == newEntry.metadataFile {
 			//Delete old entry from StaleContentMap inner slice
 			cacheManagerObj.staleContentMap[remainingCacheDuration] = append(cacheManagerObj.staleContentMap[remainingCacheDuration][:i], cacheManagerObj.staleContentMap[remainingCacheDuration][i+1:]...)
 			cacheManagerObj.decrementContentCount()
@@ -105,12 +108,33 @@ func(cacheManagerObj *cacheManager) logRemainingCacheDurationSlice() {
 	slog.Debug("storage:DEBUG", "RemainingCacheDurationSliceLength", remainingCacheDurationSliceLen, "RemainingCacheDurationSlice", remainingCacheDurationSlice)
 }
 
-func (cacheManagerObj *cacheManager) updateCacheContentInMap(maxAge int, age int, lastModified string, contentLength int, path string) {
-	cachedDuration := age
+func (cacheManagerObj *cacheManager) updateCacheContentInMap(maxAge int, age int, responseTime time.Time, contentLength int, path string, metadataFile string) {
+	cachedDuration := age + int(time.Since(responseTime).Seconds())
 	remainingCacheDuration := maxAge - cachedDuration
-	newEntry := metadataStruct{path, maxAge, age, lastModified, contentLength}
+	newEntry := metadataStruct{path, metadataFile, maxAge, age, responseTime, contentLength}
 
 	cacheManagerObj.pushEntryInMap(remainingCacheDuration, newEntry)
 	cacheManagerObj.logStaleContentMap()
 }
 
+
+// removeCacheContentFromMap removes the entry of the content, or of its variant, with the metadata file
+func (cacheManagerObj *cacheManager) removeCacheContentFromMap(path string, metadataFile string) {
+	cacheManagerObj.mutex.Lock()
+	defer cacheManagerObj.mutex.Unlock()
+	for duration, entries := range cacheManagerObj.staleContentMap {
+		remaining := entries[:0]
+		for _, value := range entries {
+			if value.path == path && value.metadataFile == metadataFile {
+				cacheManagerObj.decrementContentCount()
+				continue
+			}
+			remaining = append(remaining, value)
+		}
+		if len(remaining) == 0 {
+			delete(cacheManagerObj.staleContentMap, duration)
+		} else {
+			cacheManagerObj.staleContentMap[duration] = remaining
+		}
+	}
+}
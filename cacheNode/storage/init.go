@@ -9,6 +9,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/hcl/cdn/cacheNode/cachePolicy"
 	"github.com/hcl/cdn/cacheNode/common"
 	"github.com/hcl/cdn/cacheNode/config"
 	"github.com/hcl/cdn/cacheNode/observability"
@@ -29,14 +30,23 @@ var (
 	storageLogLevel                 = slog.LevelInfo
 )
 
-// Get metadata file name and bin file name by merging CDN base directory and parsed url.
-func fileNames(parsedUrl *url.URL, host string) (contentDir string, contentMetaDataFile string, contentFile string) {
-	if host == "" {
-		host = parsedUrl.Hostname()
+// Suffix of the content directory of objects keyed on query params, headers or cookies
+const keyedDirSuffix = "_key_"
+
+/*
+ * Get metadata file name and bin file name by merging CDN base directory and the cache key.
+ * Objects keyed on query params, headers or cookies are stored next to the object of the bare path
+ *		{path}_key_{hash}/{base}_key_{hash}_metadata.json
+ */
+func fileNames(key cachePolicy.Key) (contentDir string, contentMetaDataFile string, contentFile string) {
+	contentDir = filepath.Join(CDNDatastore, key.Host, key.Path)
+	base := filepath.Base(key.Path)
+	if hash := key.Hash(); hash != "" {
+		contentDir += keyedDirSuffix + hash
+		base += keyedDirSuffix + hash
 	}
-	contentDir = filepath.Join(CDNDatastore, host, parsedUrl.Path)
-	contentMetaDataFile = filepath.Join(contentDir, filepath.Base(parsedUrl.Path)+"_metadata.json")
-	contentFile = filepath.Join(contentDir, filepath.Base(parsedUrl.Path)+".bin")
+	contentMetaDataFile = filepath.Join(contentDir, base+"_metadata.json")
+	contentFile = filepath.Join(contentDir, base+".bin")
 	return
 }
 
@@ -77,6 +87,7 @@ func Init(ctx context.Context, wg *sync.WaitGroup, cdnDir string, cfg *config.Ru
 	}
 
 	storageObj := &StorageHandler{
+		cfg: cfg,
 		observabilityObj: observabilityHandler,
 		cacheManagerObj: cacheManagerHandler,
 	}
@@ -65,6 +65,27 @@ func deletedPatternMatchDirectories(contentDir string, cacheManagerObj *cacheMan
 	return
 }
 
+/*
+ * Objects keyed on query params, headers or cookies are stored next to the object of the bare path,
+ * they are invalidated along with it.
+ */
+func deleteKeyedDirectories(contentDir string) (bytesDeleted int, found bool, err error) {
+	keyedDirs, err := filepath.Glob(contentDir + keyedDirSuffix + "*")
+	if err != nil {
+		return
+	}
+	for _, keyedDir := range keyedDirs {
+		found = true
+		bytesDeleted += getDirectorySize(keyedDir, nil)
+		err = os.RemoveAll(keyedDir)
+		if err != nil {
+			slog.Error("Storage:Invalidator:Failed to delete directory:", "dir", keyedDir, "error", err)
+			return
+		}
+	}
+	return
+}
+
 /*
  * Function to delete a particular stale delivery service and its associated contents from storage.
  * The DELETE request will come from Config Mgmt API module.
@@ -90,7 +111,22 @@ func invalidator(request *http.Request, storageObj *StorageHandler) (response *h
 	}
 
 	slog.Info("Storage:Invalidator:Received request ", "method", request.Method, "url", request.URL.String())
-	contentDir, _, _ := fileNames(parsedUrl, request.Host)
+	key := storageObj.cacheKey(request, parsedUrl, request.Header)
+	contentDir, _, _ := fileNames(key)
+
+	/*
+	 * A URL without query params invalidates all objects of the path keyed on query params, headers or cookies.
+	 * A URL with query params invalidates the one object of its cache key.
+	 */
+	keyedFound := false
+	if key.Qualifier == "" {
+		bytesDeleted, keyedFound, err = deleteKeyedDirectories(contentDir)
+		if err != nil {
+			response.StatusCode = http.StatusInternalServerError
+			response.Status = strconv.Itoa(http.StatusInternalServerError) + " Content directory deletion failed"
+			return
+		}
+	}
 
 	/*
 	 * The Invalidator module will treat the URL as a simple directory path and try to locate it.
@@ -98,8 +134,10 @@ func invalidator(request *http.Request, storageObj *StorageHandler) (response *h
 	 * delete all matching directories
 	 */
 	_, err = os.Stat(contentDir)
-	if err == nil {
-		bytesDeleted = getDirectorySize(contentDir, storageObj.cacheManagerObj)
+	if err == nil || keyedFound {
+		if err == nil {
+			bytesDeleted += getDirectorySize(contentDir, storageObj.cacheManagerObj)
+		}
 		slog.Info("Storage:Invalidator:Deletion of directories based on exact match")
 		err = os.RemoveAll(contentDir)
 		if err != nil {
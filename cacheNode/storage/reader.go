@@ -8,6 +8,8 @@ import (
 	"os"
 	"strconv"
 	"time"
+
+	"github.com/hcl/cdn/cacheNode/cachePolicy"
 )
 
 func readContentMetaDataFile(contentMetaDataFile string) (response *http.Response, err error) {
@@ -37,22 +39,63 @@ func readContentMetaDataFile(contentMetaDataFile string) (response *http.Respons
 	return
 }
 
-func updateCacheHeaders(responseHeader *http.Header) {
-	if responseHeader.Get("Last-Modified") != "" &&
-		responseHeader.Get("Age") != "" &&
-		responseHeader.Get("Last-Modified") != "" {
-		lastModifiedTimestamp, _ := http.ParseTime(responseHeader.Get("Last-Modified"))
-		age, _ := strconv.Atoi(responseHeader.Get("Age"))
-		age += int(time.Now().Unix() - lastModifiedTimestamp.Unix())
-		responseHeader.Set("Age", strconv.Itoa(age))
-	} else {
-		slog.Error("Storage:Reader:CDNDATASTORE directory got corrupted. CacheEvictor will behave abnormally...")
+/*
+ * Age of the content is its age when stored plus the time spent in the cache since then.
+ * The metadata file is written when the content is stored or revalidated.
+ */
+func updateCacheHeaders(responseHeader *http.Header, contentMetaDataFile string) {
+	metadataInfo, err := os.Stat(contentMetaDataFile)
+	if err != nil {
+		slog.Error("Storage:Reader:Failed to stat metadata file. CacheEvictor will behave abnormally...", "file", contentMetaDataFile, "error", err)
+		return
+	}
+	age := cachePolicy.CurrentAge(*responseHeader, metadataInfo.ModTime(), time.Now())
+	responseHeader.Set("Age", strconv.Itoa(int(age.Seconds())))
+}
+
+/*
+ * Content file is opened on first Read/Seek, so that requests answered from the metadata alone
+ * (304 Not Modified, 412 Precondition Failed) never touch the content file.
+ */
+type contentFileBody struct {
+	path string
+	file *os.File
+}
+
+func (b *contentFileBody) open() (err error) {
+	if b.file == nil {
+		b.file, err = os.Open(b.path)
+		if err != nil {
+			slog.Error("Storage:Reader:Failed to open content file", "file", b.path, "error", err)
+		}
+	}
+	return
+}
+
+func (b *contentFileBody) Read(p []byte) (int, error) {
+	if err := b.open(); err != nil {
+		return 0, err
+	}
+	return b.file.Read(p)
+}
+
+func (b *contentFileBody) Seek(offset int64, whence int) (int64, error) {
+	if err := b.open(); err != nil {
+		return 0, err
+	}
+	return b.file.Seek(offset, whence)
+}
+
+func (b *contentFileBody) Close() error {
+	if b.file == nil {
+		return nil
 	}
+	return b.file.Close()
 }
 
 func getContentFileHandle(contentFile string, responseHeader http.Header) (bytesRead int, response *http.Response, err error) {
 	response = &http.Response{}
-	contentFileHandle, err := os.Open(contentFile)
+	contentFileInfo, err := os.Stat(contentFile)
 	if err != nil {
 		if os.IsNotExist(err) {
 			slog.Error("Storage:Reader:Content file Not Found", "file", contentFile)
@@ -66,7 +109,8 @@ func getContentFileHandle(contentFile string, responseHeader http.Header) (bytes
 		}
 		return
 	}
-	response.Body = contentFileHandle
+	response.Body = &contentFileBody{path: contentFile}
+	response.ContentLength = contentFileInfo.Size()
 	if responseHeader.Get("Content-Length") != "" {
 		bytesRead, _ = strconv.Atoi(responseHeader.Get("Content-Length"))
 	} else {
@@ -99,7 +143,7 @@ func reader(request *http.Request, storageObj *StorageHandler) (response *http.R
 
 	slog.Info("Storage:Reader:Received request ", "method", request.Method, "url", request.URL.String(), "host", request.Host)
 
-	_, contentMetaDataFile, contentFile := fileNames(parsedUrl, request.Host)
+	_, contentMetaDataFile, contentFile := negotiateVariantFileNames(storageObj.cacheKey(request, parsedUrl, request.Header), request.Header)
 
 	response, err = readContentMetaDataFile(contentMetaDataFile)
 	if err != nil || response.StatusCode == http.StatusNotFound {
@@ -109,7 +153,7 @@ func reader(request *http.Request, storageObj *StorageHandler) (response *http.R
 	/*
 	 * Update Age of the content for every GET request in GET request header
 	 */
-	updateCacheHeaders(&response.Header)
+	updateCacheHeaders(&response.Header, contentMetaDataFile)
 	if request.Method == http.MethodHead {
 		response.StatusCode = http.StatusOK
 		return
@@ -4,11 +4,16 @@ import (
 	"errors"
 	"log/slog"
 	"net/http"
+	"net/url"
 
+	"github.com/hcl/cdn/cacheNode/cachePolicy"
+	"github.com/hcl/cdn/cacheNode/config"
 	"github.com/hcl/cdn/cacheNode/observability"
+	coCfg "github.com/hcl/cdn/common/config"
 )
 
 type StorageHandler struct {
+	cfg *config.RunConfig
 	observabilityObj observability.ObservabilityHandler
 	cacheManagerObj *cacheManager
 }
@@ -27,6 +32,8 @@ func (storageObj *StorageHandler) Do(request *http.Request) (response *http.Resp
 		response, err = reader(request, storageObj)
 	case http.MethodPost:
 		response, err = writer(request, storageObj)
+	case http.MethodPatch:
+		response, err = updater(request, storageObj)
 	case http.MethodDelete:
 		response, err = invalidator(request, storageObj)
 	default:
@@ -37,3 +44,17 @@ func (storageObj *StorageHandler) Do(request *http.Request) (response *http.Resp
 
 	return
 }
+
+/*
+ * Build the key of the stored object with the cache key policy of the DS of the request.
+ * requestHeader holds the client request headers the key may depend on.
+ */
+func (storageObj *StorageHandler) cacheKey(request *http.Request, parsedUrl *url.URL, requestHeader http.Header) cachePolicy.Key {
+	var policy *coCfg.CacheKey
+	if storageObj.cfg != nil {
+		if ds, err := storageObj.cfg.DSLookup(request); err == nil {
+			policy = &ds.CacheKey
+		}
+	}
+	return cachePolicy.NewKey(parsedUrl, request.Host, requestHeader, policy)
+}
@@ -3,6 +3,7 @@ package storage
 import (
 	"fmt"
 	"log/slog"
+	"os"
 	"os/exec"
 	"strconv"
 	"path"
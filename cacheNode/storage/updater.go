@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+/*
+ * Headers of the stored content which are never replaced by a metadata update,
+ * they describe the content file which is left untouched.
+ */
+var preservedMetadataHeaders = []string{
+	"Content-Length",
+	"Content-Encoding",
+	"Content-Range",
+	"Transfer-Encoding",
+}
+
+func mergeMetadataHeaders(storedHeader http.Header, freshHeader http.Header) {
+	for key, values := range freshHeader {
+		storedHeader[http.CanonicalHeaderKey(key)] = values
+	}
+}
+
+/*
+ * updater handles PATCH requests. The request headers (typically taken from a 304 Not Modified
+ * response of the parent/origin) are merged into the stored metadata, the content file is not rewritten.
+ * The metadata file is rewritten, so the age of the content is counted from now on.
+ * If Age is missing in the request, then reset Age to 0
+ */
+func updater(request *http.Request, storageObj *StorageHandler) (response *http.Response, err error) {
+	response = &http.Response{}
+	var parsedUrl *url.URL = nil
+	startTime := time.Now()
+	defer func() {
+		endTime := time.Now()
+		timeTaken := endTime.Sub(startTime)
+		recordStorageMetrics(parsedUrl, request.Host, "update", int(timeTaken.Milliseconds()), 0, storageObj.observabilityObj)
+		slog.Info("Storage:Updater:Time taken to update", "url", request.URL.String(), "host", request.Host, "timeTaken(milliseconds)", timeTaken.Milliseconds())
+	}()
+
+	parsedUrl, err = url.Parse(request.URL.String())
+	if err != nil {
+		slog.Error("Storage:Updater:Failed to parse PATCH request URL", "url", request.URL.String(), "host", request.Host, "error", err)
+		response.StatusCode = http.StatusBadRequest
+		response.Status = strconv.Itoa(http.StatusBadRequest) + " Bad Request URL"
+		return
+	}
+
+	slog.Info("Storage:Updater:Received request ", "method", request.Method, "url", request.URL.String(), "host", request.Host)
+
+	freshHeader := request.Header.Clone()
+	varyRequestHeader := extractVaryRequestHeaders(freshHeader)
+	contentDir, contentMetaDataFile, _ := selectVariantFileNames(storageObj.cacheKey(request, parsedUrl, varyRequestHeader), varyRequestHeader)
+
+	response, err = readContentMetaDataFile(contentMetaDataFile)
+	if err != nil || response.StatusCode == http.StatusNotFound {
+		return
+	}
+	metadataHeader := response.Header
+	if metadataHeader == nil {
+		metadataHeader = make(http.Header)
+	}
+
+	for _, key := range preservedMetadataHeaders {
+		freshHeader.Del(key)
+	}
+	if freshHeader.Get("Age") == "" {
+		freshHeader.Set("Age", "0")
+	}
+	mergeMetadataHeaders(metadataHeader, freshHeader)
+
+	maxAge, age, contentLength := validateCacheHeaders(metadataHeader, startTime)
+
+	contentMetadata, err := json.Marshal(metadataHeader)
+	if err != nil {
+		slog.Error("Storage:Updater:Failed to convert header fields to JSON", "url", request.URL.String(), "host", request.Host, "error", err)
+		response = &http.Response{}
+		response.StatusCode = http.StatusBadRequest
+		response.Status = strconv.Itoa(http.StatusBadRequest) + " Bad Request"
+		return
+	}
+
+	_, response, err = createFile(contentMetaDataFile, nil, contentMetadata)
+	if err != nil {
+		return
+	}
+	slog.Info("Storage:Updater:Successfully updated metadata", "url", request.URL.String(), "host", request.Host)
+
+	/*
+	 * Content is fresh again, move it to its new remaining cache duration in the in-memory map/slice.
+	 * Only the entry of the revalidated variant moves, the other variants keep theirs.
+	 */
+	storageObj.cacheManagerObj.removeCacheContentFromMap(contentDir, contentMetaDataFile)
+	storageObj.cacheManagerObj.updateCacheContentInMap(maxAge, age, startTime, contentLength, contentDir, contentMetaDataFile)
+	response.StatusCode = http.StatusOK
+	response.Header = metadataHeader
+	return
+}
@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hcl/cdn/cacheNode/cachePolicy"
+)
+
+/*
+ * Test Functions
+ *		TestUpdater
+ */
+
+func TestUpdater(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	storageHandler, err := Init(ctx, &wg, t.TempDir(), nil, nil)
+	if err != nil {
+		t.Fatalf("TestUpdater:Failed to init storage")
+	}
+	URL := "http://abc.com/updater/sample"
+
+	request, _ := http.NewRequest(http.MethodPatch, URL, nil)
+	request.Header.Set("Cache-Control", "max-age=600")
+	response, err := storageHandler.Do(request)
+	if err != nil || response.StatusCode != http.StatusNotFound {
+		t.Fatalf("TestUpdater:Expected 404 for missing content, got %d (%v)", response.StatusCode, err)
+	}
+
+	request, _ = http.NewRequest(http.MethodPost, URL, strings.NewReader("cached content"))
+	request.Header.Set("Content-Length", "14")
+	request.Header.Set("Cache-Control", "max-age=10")
+	request.Header.Set("Age", "50")
+	request.Header.Set("ETag", `"v1"`)
+	request.Header.Set("Testheader", "ABCD")
+	if response, err = storageHandler.Do(request); err != nil || response.StatusCode != http.StatusCreated {
+		t.Fatalf("TestUpdater:Failed to store content")
+	}
+
+	request, _ = http.NewRequest(http.MethodPatch, URL, nil)
+	request.Header.Set("Cache-Control", "max-age=600")
+	request.Header.Set("Content-Length", "0")
+	response, err = storageHandler.Do(request)
+	if err != nil || response.StatusCode != http.StatusOK {
+		t.Fatalf("TestUpdater:Expected 200, got %d (%v)", response.StatusCode, err)
+	}
+
+	request, _ = http.NewRequest(http.MethodGet, URL, nil)
+	response, err = storageHandler.Do(request)
+	if err != nil || response.StatusCode != http.StatusOK {
+		t.Fatalf("TestUpdater:Expected 200 on read, got %d (%v)", response.StatusCode, err)
+	}
+	defer response.Body.Close()
+	body, _ := io.ReadAll(response.Body)
+	if string(body) != "cached content" {
+		t.Errorf("TestUpdater:Content file changed: %q", body)
+	}
+	checks := map[string]string{
+		"Cache-Control":  "max-age=600",
+		"Content-Length": "14",
+		"ETag":           `"v1"`,
+		"Testheader":     "ABCD",
+	}
+	for key, want := range checks {
+		if got := response.Header.Get(key); got != want {
+			t.Errorf("TestUpdater:Header %s expected %q, got %q", key, want, got)
+		}
+	}
+
+	if age := response.Header.Get("Age"); age != "0" && age != "1" {
+		t.Errorf("TestUpdater:Age expected to be reset, got %q", age)
+	}
+
+	storageObj, _ := storageHandler.(*StorageHandler)
+	if storageObj.cacheManagerObj.getTotalContents() != 1 {
+		t.Errorf("TestUpdater:Expected one entry in stale content map, got %d", storageObj.cacheManagerObj.getTotalContents())
+	}
+
+	// Revalidating one variant keeps the entries of the others
+	URL = "http://abc.com/updater/variants"
+	storeVariant(t, storageHandler, URL, "gzip", "gzip content")
+	storeVariant(t, storageHandler, URL, "br", "br content")
+	request, _ = http.NewRequest(http.MethodPatch, URL, nil)
+	request.Header.Set("Cache-Control", "max-age=1200")
+	request.Header.Set(cachePolicy.VaryRequestHeaderPrefix+"Accept-Encoding", "gzip")
+	if response, err = storageHandler.Do(request); err != nil || response.StatusCode != http.StatusOK {
+		t.Fatalf("TestUpdater:Expected 200 for the variant, got %d (%v)", response.StatusCode, err)
+	}
+	if storageObj.cacheManagerObj.getTotalContents() != 3 {
+		t.Errorf("TestUpdater:Expected an entry per variant in stale content map, got %d", storageObj.cacheManagerObj.getTotalContents())
+	}
+}
@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/hcl/cdn/cacheNode/cachePolicy"
+)
+
+/*
+ * Responses with a Vary header are stored as variants inside the content directory of the URL
+ *		{base}_vary.json						===> names of the varied request headers
+ *		{base}_{variantKey}_metadata.json		===> metadata of one variant
+ *		{base}_{variantKey}.bin					===> content of one variant
+ * Variants live in the same directory, so Invalidator & CacheEvictor delete them along with the object.
+ */
+
+func varyIndexFileName(contentDir string) string {
+	return filepath.Join(contentDir, filepath.Base(contentDir)+"_vary.json")
+}
+
+func variantFileNames(contentDir string, variantKey string) (contentMetaDataFile string, contentFile string) {
+	base := filepath.Base(contentDir) + "_" + variantKey
+	contentMetaDataFile = filepath.Join(contentDir, base+"_metadata.json")
+	contentFile = filepath.Join(contentDir, base+".bin")
+	return
+}
+
+// readVaryIndex returns the varied request headers of the stored object, nil if it does not vary
+func readVaryIndex(contentDir string) []string {
+	data, err := os.ReadFile(varyIndexFileName(contentDir))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			slog.Error("Storage:Variants:Failed to read vary index", "dir", contentDir, "error", err)
+		}
+		return nil
+	}
+	var vary []string
+	if err = json.Unmarshal(data, &vary); err != nil {
+		slog.Error("Storage:Variants:Failed to parse vary index", "dir", contentDir, "error", err)
+		return nil
+	}
+	return vary
+}
+
+func writeVaryIndex(contentDir string, vary []string) (response *http.Response, err error) {
+	data, err := json.Marshal(vary)
+	if err != nil {
+		response = &http.Response{StatusCode: http.StatusInternalServerError}
+		return
+	}
+	_, response, err = createFile(varyIndexFileName(contentDir), nil, data)
+	return
+}
+
+func sameVary(a []string, b []string) bool {
+	return strings.Join(a, ",") == strings.Join(b, ",")
+}
+
+/*
+ * Get the metadata & content file names of the variant selected by the request headers.
+ * The default file names are returned for objects which do not vary.
+ */
+func selectVariantFileNames(key cachePolicy.Key, requestHeader http.Header) (contentDir string, contentMetaDataFile string, contentFile string) {
+	contentDir, contentMetaDataFile, contentFile = fileNames(key)
+	vary := readVaryIndex(contentDir)
+	if len(vary) == 0 {
+		return
+	}
+	contentMetaDataFile, contentFile = variantFileNames(contentDir, cachePolicy.VariantKey(vary, requestHeader))
+	return
+}
+
+/*
+ * Get the file names of the variant serving the client. Variants by Accept-Encoding are stored by their
+ * content coding, the first stored coding in the preference of the client is served. The frontend
+ * decodes gzip & br for clients which do not accept them, so the variants of any coding can serve.
+ */
+func negotiateVariantFileNames(key cachePolicy.Key, requestHeader http.Header) (contentDir string, contentMetaDataFile string, contentFile string) {
+	contentDir, contentMetaDataFile, contentFile = fileNames(key)
+	vary := readVaryIndex(contentDir)
+	if len(vary) == 0 {
+		return
+	}
+	if !slices.Contains(vary, "Accept-Encoding") {
+		contentMetaDataFile, contentFile = variantFileNames(contentDir, cachePolicy.VariantKey(vary, requestHeader))
+		return
+	}
+	candidateHeader := requestHeader.Clone()
+	for i, coding := range cachePolicy.EncodingPreference(requestHeader) {
+		candidateHeader.Set("Accept-Encoding", coding)
+		metaDataFile, file := variantFileNames(contentDir, cachePolicy.VariantKey(vary, candidateHeader))
+		if i == 0 {
+			contentMetaDataFile, contentFile = metaDataFile, file
+		}
+		if _, err := os.Stat(metaDataFile); err == nil {
+			return contentDir, metaDataFile, file
+		}
+	}
+	return
+}
+
+/*
+ * Remove the request headers handed over by the backend from the metadata (varied headers and
+ * cache key components), they are returned without the prefix to select the object & variant.
+ */
+func extractVaryRequestHeaders(requestHeader http.Header) (varyRequestHeader http.Header) {
+	varyRequestHeader = make(http.Header)
+	prefix := http.CanonicalHeaderKey(cachePolicy.VaryRequestHeaderPrefix)
+	for key, values := range requestHeader {
+		if strings.HasPrefix(key, prefix) {
+			varyRequestHeader[http.CanonicalHeaderKey(strings.TrimPrefix(key, prefix))] = values
+			delete(requestHeader, key)
+		}
+	}
+	return
+}
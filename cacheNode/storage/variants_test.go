@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hcl/cdn/cacheNode/cachePolicy"
+	"github.com/hcl/cdn/cacheNode/common"
+)
+
+/*
+ * Test Functions
+ *		TestVariants
+ */
+
+func storeVariant(t *testing.T, storageHandler common.RequestHandler, URL string, encoding string, data string) {
+	request, _ := http.NewRequest(http.MethodPost, URL, strings.NewReader(data))
+	request.Header.Set("Cache-Control", "max-age=600")
+	request.Header.Set("Vary", "Accept-Encoding")
+	request.Header.Set("Content-Encoding", encoding)
+	request.Header.Set(cachePolicy.VaryRequestHeaderPrefix+"Accept-Encoding", encoding)
+	response, err := storageHandler.Do(request)
+	if err != nil || response.StatusCode != http.StatusCreated {
+		t.Fatalf("TestVariants:Failed to store %s variant", encoding)
+	}
+}
+
+func readVariant(t *testing.T, storageHandler common.RequestHandler, URL string, acceptEncoding string) (int, string, http.Header) {
+	request, _ := http.NewRequest(http.MethodGet, URL, nil)
+	request.Header.Set("Accept-Encoding", acceptEncoding)
+	response, err := storageHandler.Do(request)
+	if err != nil {
+		t.Fatalf("TestVariants:Read failed %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		return response.StatusCode, "", response.Header
+	}
+	defer response.Body.Close()
+	body, _ := io.ReadAll(response.Body)
+	return response.StatusCode, string(body), response.Header
+}
+
+func TestVariants(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	storageHandler, err := Init(ctx, &wg, t.TempDir(), nil, nil)
+	if err != nil {
+		t.Fatalf("TestVariants:Failed to init storage")
+	}
+	URL := "http://abc.com/variants/sample"
+	storeVariant(t, storageHandler, URL, "gzip", "gzip content")
+	storeVariant(t, storageHandler, URL, "br", "br content")
+
+	status, body, header := readVariant(t, storageHandler, URL, "gzip")
+	if status != http.StatusOK || body != "gzip content" {
+		t.Errorf("TestVariants:Expected gzip variant, got %d %q", status, body)
+	}
+	if header.Get(cachePolicy.VaryRequestHeaderPrefix+"Accept-Encoding") != "" {
+		t.Errorf("TestVariants:Varied request headers must not be stored as metadata")
+	}
+	if status, body, _ = readVariant(t, storageHandler, URL, " BR "); status != http.StatusOK || body != "br content" {
+		t.Errorf("TestVariants:Expected br variant, got %d %q", status, body)
+	}
+	if status, body, _ = readVariant(t, storageHandler, URL, "gzip;q=0.5, br"); status != http.StatusOK || body != "br content" {
+		t.Errorf("TestVariants:Expected br variant by q value, got %d %q", status, body)
+	}
+	// Codings not stored fall back to a stored coding, the frontend decodes it for the client
+	if status, body, _ = readVariant(t, storageHandler, URL, "deflate"); status != http.StatusOK || body != "gzip content" {
+		t.Errorf("TestVariants:Expected gzip variant for a coding not stored, got %d %q", status, body)
+	}
+
+	// Invalidation removes every variant of the object
+	request, _ := http.NewRequest(http.MethodDelete, URL, nil)
+	if response, err := storageHandler.Do(request); err != nil || response.StatusCode != http.StatusOK {
+		t.Fatalf("TestVariants:Failed to invalidate")
+	}
+	for _, encoding := range []string{"gzip", "br"} {
+		if status, _, _ = readVariant(t, storageHandler, URL, encoding); status != http.StatusNotFound {
+			t.Errorf("TestVariants:Expected %s variant to be invalidated, got %d", encoding, status)
+		}
+	}
+}
@@ -8,48 +8,28 @@ import (
 	"net/url"
 	"os"
 	"strconv"
-	"strings"
 	"syscall"
 	"time"
+
+	"github.com/hcl/cdn/cacheNode/cachePolicy"
+	"github.com/hcl/cdn/common/helper"
 )
 
-func validateCacheHeaders(requestHeader http.Header) (maxAge int, age int, lastModified string, contentLength int) {
+/*
+ * Freshness lifetime & age are computed by cachePolicy (s-maxage, max-age, Expires/Date, heuristic from Last-Modified).
+ * age is the age of the content when it was stored at responseTime, the time the metadata file was written.
+ */
+func validateCacheHeaders(requestHeader http.Header, responseTime time.Time) (maxAge int, age int, contentLength int) {
 	var err error
-	v := requestHeader.Get("Cache-Control")
-	if v == "" {
-		requestHeader.Set("Cache-Control", "max-age=0")
-		maxAge = 0
-	} else {
-		maxAge, err = strconv.Atoi(strings.TrimPrefix(v, "max-age="))
-		if err != nil {
-			slog.Error("Storage:Writer:validateCacheHeaders:Failed to convert max-age to int", "error", err)
-			maxAge = 0
-		}
-	}
-
-	v = requestHeader.Get("Last-Modified")
-	if v == "" {
-		lastModified = time.Now().UTC().Format(http.TimeFormat)
-		requestHeader.Set("Last-Modified", lastModified)
-	} else {
-		lastModified = v
-	}
+	lifetime, _ := cachePolicy.FreshnessLifetime(requestHeader, http.StatusOK)
+	maxAge = int(lifetime.Seconds())
 
-	v = requestHeader.Get("Age")
-	if v == "" {
+	if requestHeader.Get("Age") == "" {
 		requestHeader.Set("Age", "0")
-		age = 0
-	} else {
-		age, err = strconv.Atoi(v)
-		if err != nil {
-			slog.Error("Storage:Writer:validateCacheHeaders:Failed to convert age to int", "error", err)
-			age = 0
-		}
 	}
-	lastModifiedTimestamp, _ := http.ParseTime(lastModified)
-	age += int(time.Now().Unix() - lastModifiedTimestamp.Unix())
+	age = int(cachePolicy.InitialAge(requestHeader, responseTime).Seconds())
 
-	v = requestHeader.Get("Content-Length")
+	v := requestHeader.Get("Content-Length")
 	if v == "" {
 		contentLength = 0
 	} else {
@@ -62,12 +42,15 @@ func validateCacheHeaders(requestHeader http.Header) (maxAge int, age int, lastM
 	return
 }
 
-func createContentDirectory(contentDir string) (response *http.Response, err error) {
+func createContentDirectory(contentDir string, keepVariants bool) (response *http.Response, err error) {
 	response = &http.Response{}
 	/*
-	 * Delete the content directory if already present. Treat it as new content
+	 * Delete the content directory if already present. Treat it as new content,
+	 * unless one more variant of the same object is added
 	 */
-	_ = os.RemoveAll(contentDir)
+	if !keepVariants {
+		_ = os.RemoveAll(contentDir)
+	}
 
 	err = os.MkdirAll(contentDir, os.ModePerm)
 	if err != nil {
@@ -87,6 +70,8 @@ func createContentDirectory(contentDir string) (response *http.Response, err err
 
 func createFile(fileName string, payload io.ReadCloser, metadata []byte) (numBytes int64, response *http.Response, err error) {
 	response = &http.Response{}
+	// a new file rather than the previous one truncated, the clients still reading the previous copy keep it
+	_ = os.Remove(fileName)
 	contentFileHandler, err := os.Create(fileName)
 	if err != nil {
 		diskErr, ok := err.(*os.PathError)
@@ -105,7 +90,8 @@ func createFile(fileName string, payload io.ReadCloser, metadata []byte) (numByt
 	if payload == nil {
 		err = os.WriteFile(fileName, metadata, 0755)
 	} else {
-		numBytes, err = io.Copy(contentFileHandler, payload)
+		// a body shared with the clients spools to the content file instead of a copy of its own
+		numBytes, err = helper.WriteToFile(contentFileHandler, payload)
 	}
 	if err != nil {
 		diskErr, ok := err.(*os.PathError)
@@ -151,22 +137,31 @@ func writer(request *http.Request, storageObj *StorageHandler) (response *http.R
 		return
 	}
 
-	contentDir, contentMetaDataFile, contentFile := fileNames(parsedUrl, request.Host)
+	varyRequestHeader := extractVaryRequestHeaders(request.Header)
+	contentDir, contentMetaDataFile, contentFile := fileNames(storageObj.cacheKey(request, parsedUrl, varyRequestHeader))
+	vary := cachePolicy.VaryHeaders(request.Header)
 
 	/*
-	 * Validate Cache headers Max-age, Age & Last-Modified
-	 * If below fields are missing in POST request header, then
-	 *      Reset Max-age & Age to 0
-	 *      Set Last-Modified as current timestamp
+	 * Validate Cache headers Cache-Control, Expires, Age & Last-Modified
+	 * If Age is missing in POST request header, then reset Age to 0
 	 */
-	maxAge, age, lastModified, contentLength := validateCacheHeaders(request.Header)
+	maxAge, age, contentLength := validateCacheHeaders(request.Header, startTime)
 
 	// Create the base directory
-	response, err = createContentDirectory(contentDir)
+	response, err = createContentDirectory(contentDir, len(vary) > 0 && sameVary(vary, readVaryIndex(contentDir)))
 	if err != nil {
 		return
 	}
 
+	// Responses with Vary are stored as one variant of the object
+	if len(vary) > 0 {
+		response, err = writeVaryIndex(contentDir, vary)
+		if err != nil {
+			return
+		}
+		contentMetaDataFile, contentFile = variantFileNames(contentDir, cachePolicy.VariantKey(vary, varyRequestHeader))
+	}
+
 	// Prepare meta content
 	contentMetadata, err := json.Marshal(request.Header)
 	if err != nil {
@@ -189,6 +184,9 @@ func writer(request *http.Request, storageObj *StorageHandler) (response *http.R
 	 */
 	bytesStored, response, err = createFile(contentFile, request.Body, nil)
 	if err != nil {
+		// A payload cut by the origin leaves a partial object, it must not be served
+		_ = os.Remove(contentFile)
+		_ = os.Remove(contentMetaDataFile)
 		return
 	}
 	slog.Info("Storage:Writer:Successfully stored", "url", request.URL.String(), "host", request.Host, "bytesStored", bytesStored)
@@ -198,6 +196,6 @@ func writer(request *http.Request, storageObj *StorageHandler) (response *http.R
 	 * when the disk threshold exceeds.
 	 */
 	response.StatusCode = http.StatusCreated
-	storageObj.cacheManagerObj.updateCacheContentInMap(maxAge, age, lastModified, contentLength, contentDir)
+	storageObj.cacheManagerObj.updateCacheContentInMap(maxAge, age, startTime, contentLength, contentDir, contentMetaDataFile)
 	return
 }
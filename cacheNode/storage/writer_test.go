@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -9,6 +10,7 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"testing/iotest"
 	"time"
 
 	"github.com/hcl/cdn/cacheNode/common"
@@ -17,6 +19,7 @@ import (
 /*
  * Test Functions
  *		TestWriter
+ *		TestWriterPartialPayload
  */
 
 func postRequest(t *testing.T, storageHandler common.RequestHandler, caseId int, caseName string) {
@@ -169,3 +172,34 @@ func TestWriter(t *testing.T) {
 	testNilHttpRequest(t, storageHandler)
 	//wg.Done()
 }
+
+/*
+ * A payload failing midway is not stored, the partial object is removed
+ */
+func TestWriterPartialPayload(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	storageHandler, err := Init(ctx, &wg, t.TempDir(), nil, nil)
+	if err != nil {
+		t.Fatalf("TestWriterPartialPayload:Failed to init storage")
+	}
+	URL := "http://abc.com/partial/sample"
+	payload := io.MultiReader(strings.NewReader("partial content"), iotest.ErrReader(errors.New("connection reset")))
+	request, _ := http.NewRequest(http.MethodPost, URL, payload)
+	request.Header.Set("Cache-Control", "max-age=600")
+	response, _ := storageHandler.Do(request)
+	if response.StatusCode != http.StatusInternalServerError {
+		t.Errorf("TestWriterPartialPayload:Expected status code: %d, but got %d", http.StatusInternalServerError, response.StatusCode)
+	}
+
+	request, _ = http.NewRequest(http.MethodGet, URL, nil)
+	response, err = storageHandler.Do(request)
+	if err != nil || response.StatusCode != http.StatusNotFound {
+		t.Errorf("TestWriterPartialPayload:Expected the partial object not to be found, got %v %v", response.StatusCode, err)
+	}
+}
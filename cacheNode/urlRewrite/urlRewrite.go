@@ -0,0 +1,84 @@
+package urlRewrite
+
+import (
+	"log/slog"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	coCfg "github.com/hcl/cdn/common/config"
+)
+
+// compiled patterns of the rules, the rules change seldom
+var regexCache sync.Map
+
+func compile(expr string) (*regexp.Regexp, error) {
+	if re, ok := regexCache.Load(expr); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	regexCache.Store(expr, re)
+	return re, nil
+}
+
+// Rewrite applies the first matching rule to the path & query of u. upstream selects the rules of the
+// request to the origin, otherwise the rules applied before the cache key. It reports if u was rewritten.
+func Rewrite(rules []coCfg.URLRewriteRule, upstream bool, u *url.URL) bool {
+	target := u.EscapedPath()
+	if u.RawQuery != "" {
+		target += "?" + u.RawQuery
+	}
+	for _, rule := range rules {
+		if rule.UpstreamOnly != upstream {
+			continue
+		}
+		re, err := compile(rule.Pattern)
+		if err != nil {
+			slog.Error("urlRewrite : Invalid pattern", "regex", rule.Pattern)
+			continue
+		}
+		match := re.FindStringSubmatchIndex(target)
+		if match == nil {
+			continue
+		}
+		rewritten := string(re.ExpandString(nil, rule.Replacement, target, match))
+		if !strings.HasPrefix(rewritten, "/") {
+			rewritten = "/" + rewritten
+		}
+		ref, err := url.Parse(rewritten)
+		if err != nil {
+			slog.Error("urlRewrite : Invalid rewritten URL", "url", rewritten, "regex", rule.Pattern, "error", err.Error())
+			return false
+		}
+		u.Path = ref.Path
+		u.RawPath = ref.RawPath
+		u.RawQuery = ref.RawQuery
+		return true
+	}
+	return false
+}
+
+// MapOriginPath replaces the path prefix of the ClientURL in u by the path prefix of the OriginURL,
+// e.g. http://cdn.example.com/assets/x.js maps to http://origin/static/v2/x.js
+func MapOriginPath(ds *coCfg.DeliveryService, u *url.URL) {
+	clientURL, err1 := url.Parse(ds.ClientURL)
+	originURL, err2 := url.Parse(ds.OriginURL)
+	if err1 != nil || err2 != nil {
+		return
+	}
+	clientPrefix := strings.TrimSuffix(clientURL.Path, "/")
+	originPrefix := strings.TrimSuffix(originURL.Path, "/")
+	// the prefix matches whole path segments, /img does not map /imgx/a
+	if clientPrefix == originPrefix || (u.Path != clientPrefix && !strings.HasPrefix(u.Path, clientPrefix+"/")) {
+		return
+	}
+	u.Path = originPrefix + strings.TrimPrefix(u.Path, clientPrefix)
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	u.RawPath = ""
+}
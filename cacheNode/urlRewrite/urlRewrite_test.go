@@ -0,0 +1,62 @@
+package urlRewrite
+
+import (
+	"net/url"
+	"testing"
+
+	coCfg "github.com/hcl/cdn/common/config"
+)
+
+func TestRewrite(t *testing.T) {
+	rules := []coCfg.URLRewriteRule{
+		{Pattern: `^/img/([0-9]+)x([0-9]+)/(.*)$`, Replacement: "/img/$3?w=$1&h=$2"},
+		{Pattern: `^/old/(.*)$`, Replacement: "/new/$1"},
+		{Pattern: `^/new/(.*)$`, Replacement: "/never/$1"},
+		{Pattern: `^/(.*)\.jpg$`, Replacement: "/$1.webp", UpstreamOnly: true},
+	}
+	cases := []struct {
+		url      string
+		upstream bool
+		want     string
+		changed  bool
+	}{
+		{"http://abc.com/img/200x100/a.png", false, "http://abc.com/img/a.png?w=200&h=100", true},
+		{"http://abc.com/old/b.js?v=2", false, "http://abc.com/new/b.js?v=2", true},
+		{"http://abc.com/c.jpg", false, "http://abc.com/c.jpg", false},
+		{"http://abc.com/c.jpg", true, "http://abc.com/c.webp", true},
+		{"http://abc.com/old/d%20e.js", false, "http://abc.com/new/d%20e.js", true},
+	}
+	for _, c := range cases {
+		u, _ := url.Parse(c.url)
+		changed := Rewrite(rules, c.upstream, u)
+		if changed != c.changed || u.String() != c.want {
+			t.Errorf("%s: got %s %v, want %s %v", c.url, u, changed, c.want, c.changed)
+		}
+	}
+}
+
+func TestMapOriginPath(t *testing.T) {
+	cases := []struct {
+		clientURL string
+		originURL string
+		url       string
+		want      string
+	}{
+		{"http://cdn.example.com/assets", "http://origin/static/v2", "http://cdn.example.com/assets/x.js", "http://cdn.example.com/static/v2/x.js"},
+		{"http://cdn.example.com/assets/", "http://origin/static/v2/", "http://cdn.example.com/assets/js/x.js?v=1", "http://cdn.example.com/static/v2/js/x.js?v=1"},
+		{"http://cdn.example.com", "http://origin/static", "http://cdn.example.com/x.js", "http://cdn.example.com/static/x.js"},
+		{"http://cdn.example.com/assets", "http://origin", "http://cdn.example.com/assets/x.js", "http://cdn.example.com/x.js"},
+		{"http://cdn.example.com/assets", "http://origin", "http://cdn.example.com/assets", "http://cdn.example.com/"},
+		{"http://cdn.example.com", "http://origin:8080", "http://cdn.example.com/x.js", "http://cdn.example.com/x.js"},
+		{"http://cdn.example.com/img", "http://origin/static", "http://cdn.example.com/imgx/a.png", "http://cdn.example.com/imgx/a.png"},
+		{"http://cdn.example.com/img/", "http://origin/static", "http://cdn.example.com/img/a.png", "http://cdn.example.com/static/a.png"},
+	}
+	for _, c := range cases {
+		ds := &coCfg.DeliveryService{ClientURL: c.clientURL, OriginURL: c.originURL}
+		u, _ := url.Parse(c.url)
+		MapOriginPath(ds, u)
+		if u.String() != c.want {
+			t.Errorf("%s -> %s: got %s, want %s", c.clientURL, c.originURL, u, c.want)
+		}
+	}
+}
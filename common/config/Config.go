@@ -4,6 +4,23 @@ const (
 	HdrReWriteOpAdd = iota
 	HdrReWriteOpOverwrite
 	HdrReWriteOpDelete
+	HdrReWriteOpAppend  // append Value to the existing value, comma separated
+	HdrReWriteOpReplace // replace the matches of Pattern in the values by Value
+)
+
+// Where the header rewrite rules apply
+const (
+	HdrReWritePhaseResponse = iota // response from upstream, before it is stored
+	HdrReWritePhaseRequest         // request going upstream
+	HdrReWritePhaseClient          // response to the client
+)
+
+// Query params in the cache key
+const (
+	CacheKeyQueryIgnoreAll = iota
+	CacheKeyQueryIncludeAll
+	CacheKeyQueryAllowList
+	CacheKeyQueryDenyList
 )
 
 const (
@@ -11,11 +28,165 @@ const (
 	CacheNodeEdge = "Edge"
 )
 
-// Rule for rewriting HTTP headers
+// Rule for rewriting HTTP headers. Value may hold the variables ${client_ip}, ${ds_name}, ${node_name}
+// & ${request_id}. The response phase is stored, per client values belong to the client phase.
 type RewriteRule struct {
-	HeaderName string `json:"headerName"` //name of the http header
-	Operation  int    `json:"operation"`  //HdrRewriteOp
-	Value      string `json:"value"`      //Value for Add/Overwrite
+	HeaderName string `json:"headerName"`        //name of the http header
+	Operation  int    `json:"operation"`         //HdrRewriteOp
+	Value      string `json:"value"`             //Value for Add/Overwrite/Append, replacement ($1 for groups) for Replace
+	Phase      int    `json:"phase,omitempty"`   //HdrReWritePhase
+	Pattern    string `json:"pattern,omitempty"` //regex matched by Replace
+
+	// Conditions on the client request, the rule applies only when all given ones match
+	IfHeader      string `json:"ifHeader,omitempty"`      //request header which must be present
+	IfHeaderMatch string `json:"ifHeaderMatch,omitempty"` //regex the value of IfHeader must match
+	IfPath        string `json:"ifPath,omitempty"`        //regex the request path must match
+}
+
+// Components of the cache key besides host and path
+type CacheKey struct {
+	QueryMode   int      `json:"queryMode"`             //CacheKeyQuery...
+	QueryParams []string `json:"queryParams,omitempty"` //allow/deny list, "utm_*" matches by prefix
+	SortQuery   bool     `json:"sortQuery,omitempty"`   //sort the query params
+	Headers     []string `json:"headers,omitempty"`     //request headers added to the key
+	Cookies     []string `json:"cookies,omitempty"`     //cookies added to the key
+	IgnoreCase  bool     `json:"ignoreCase,omitempty"`  //lower case path and query
+}
+
+// TLS termination of a Deliver Service, the certificate is selected by SNI on the ClientURL hostname
+type TLSConfig struct {
+	Certificate     string `json:"certificate,omitempty"`     //PEM certificate chain
+	PrivateKey      string `json:"privateKey,omitempty"`      //PEM private key of the certificate
+	RedirectToHTTPS bool   `json:"redirectToHttps,omitempty"` //redirect HTTP clients to HTTPS
+}
+
+// On the fly compression of a Deliver Service
+type Compression struct {
+	Enabled   bool     `json:"enabled,omitempty"`   //compress for clients accepting br or gzip
+	MimeTypes []string `json:"mimeTypes,omitempty"` //compressible content types, "text/*" matches by prefix, default list if empty
+	MinSize   int      `json:"minSize,omitempty"`   //minimum content length in bytes
+}
+
+// Key signing the tokens of a Deliver Service, several keys are active during rotation
+type TokenKey struct {
+	ID     string `json:"id"`     //key id named by the token (kid)
+	Secret string `json:"secret"` //HMAC-SHA256 secret
+}
+
+// Signed URL (token) authentication of a Deliver Service
+type TokenAuth struct {
+	Enabled    bool       `json:"enabled,omitempty"`    //reject requests without a valid token
+	QueryParam string     `json:"queryParam,omitempty"` //query param carrying the token, "token" if empty
+	CookieName string     `json:"cookieName,omitempty"` //cookie carrying the token if not in the query, "token" if empty
+	RequireIP  bool       `json:"requireIp,omitempty"`  //tokens must be bound to the client IP
+	Keys       []TokenKey `json:"keys,omitempty"`       //active signing keys
+}
+
+// Access control lists of a Deliver Service. Deny lists win, a client must match an allow list
+// (CIDR or country) when any is given. Countries are ISO 3166-1 alpha-2 codes.
+type AccessControl struct {
+	AllowCIDRs     []string `json:"allowCidrs,omitempty"`     //client networks allowed, e.g. "10.1.0.0/16"
+	DenyCIDRs      []string `json:"denyCidrs,omitempty"`      //client networks denied
+	AllowCountries []string `json:"allowCountries,omitempty"` //client countries allowed, e.g. "IN"
+	DenyCountries  []string `json:"denyCountries,omitempty"`  //client countries denied
+}
+
+// Admission control of a Deliver Service, 0 disables a limit
+type RateLimit struct {
+	ClientRate    float64 `json:"clientRate,omitempty"`    //requests per second of one client IP
+	ClientBurst   int     `json:"clientBurst,omitempty"`   //requests a client IP may send at once, ClientRate if 0
+	DSRate        float64 `json:"dsRate,omitempty"`        //requests per second of all clients
+	DSBurst       int     `json:"dsBurst,omitempty"`       //requests all clients may send at once, DSRate if 0
+	MaxConcurrent int     `json:"maxConcurrent,omitempty"` //requests in progress
+}
+
+// Rule rewriting the URL of the request. Pattern is matched against the path & query, e.g. /assets/x.js?v=1,
+// the first matching rule of the DS is applied. The rewritten URL must stay within the ClientURL.
+type URLRewriteRule struct {
+	Pattern      string `json:"pattern"`                //regex
+	Replacement  string `json:"replacement"`            //new path & query, $1 for the groups
+	UpstreamOnly bool   `json:"upstreamOnly,omitempty"` //rewrite only the request to the origin, the cache key keeps the client URL
+}
+
+// Redirect answered by the edge. Source is matched against the path, the first matching rule of the DS is applied.
+type RedirectRule struct {
+	Source        string `json:"source"`                  //regex
+	Target        string `json:"target"`                  //path or absolute URL of the Location, $1 for the groups of Source
+	StatusCode    int    `json:"statusCode,omitempty"`    //301, 302, 307 or 308, 302 if 0
+	PreserveQuery bool   `json:"preserveQuery,omitempty"` //append the query of the request to the Location
+}
+
+// CORS policy of the DS answered by the edge, the Access-Control headers of the origin are replaced
+type CORS struct {
+	Enabled          bool     `json:"enabled"`
+	AllowOrigins     []string `json:"allowOrigins,omitempty"`     //origins allowed, * as wildcard e.g. https://*.example.com
+	AllowMethods     []string `json:"allowMethods,omitempty"`     //methods allowed, GET & HEAD if empty
+	AllowHeaders     []string `json:"allowHeaders,omitempty"`     //request headers allowed, * for any
+	ExposeHeaders    []string `json:"exposeHeaders,omitempty"`    //response headers readable by the scripts
+	AllowCredentials bool     `json:"allowCredentials,omitempty"` //cookies & authorization
+	MaxAge           int      `json:"maxAge,omitempty"`           //seconds the browser may cache the preflight
+}
+
+// Page replacing the body of the error responses to the clients
+type ErrorPage struct {
+	Status      string `json:"status"`                //status code e.g. "404", or class e.g. "5xx"
+	URL         string `json:"url,omitempty"`         //path of the page on the origin, cached like the content
+	Content     string `json:"content,omitempty"`     //inline page, used when the URL is not given or fails
+	ContentType string `json:"contentType,omitempty"` //of the inline page, text/html if empty
+}
+
+// Member of the origin group of a Deliver Service
+type Origin struct {
+	URL    string `json:"url"`              //scheme & host of the origin, the path of the OriginURL still maps the paths
+	Backup bool   `json:"backup,omitempty"` //used only when no primary origin is healthy
+	Weight int    `json:"weight,omitempty"` //share of the requests among the healthy origins of its tier, 1 if 0
+}
+
+// Origin group of a Deliver Service, the nodes without parent probe the origins & fail over between them
+type OriginGroup struct {
+	Origins          []Origin `json:"origins,omitempty"`          //primary & backup origins, the OriginURL alone if empty
+	HealthPath       string   `json:"healthPath,omitempty"`       //path probed with GET, a 2xx or 3xx passes; no probes if empty
+	HealthInterval   int      `json:"healthInterval,omitempty"`   //seconds between the probes of an origin, 10 if 0
+	HealthTimeout    int      `json:"healthTimeout,omitempty"`    //seconds a probe may take, 5 if 0
+	UnhealthyAfter   int      `json:"unhealthyAfter,omitempty"`   //failed probes in a row marking an origin down, 3 if 0
+	HealthyAfter     int      `json:"healthyAfter,omitempty"`     //passed probes in a row marking an origin up again, 2 if 0
+	FailoverStatuses []int    `json:"failoverStatuses,omitempty"` //5xx statuses of an origin fetching from the next origin, connect errors always do
+}
+
+// Retries of the upstream fetches of a Deliver Service, only the idempotent requests are retried
+type RetryPolicy struct {
+	MaxAttempts int      `json:"maxAttempts,omitempty"` //attempts of a fetch including the first one, no retry if 0 or 1
+	Statuses    []int    `json:"statuses,omitempty"`    //5xx statuses retried e.g. 502, 503, 504
+	Errors      []string `json:"errors,omitempty"`      //errors retried: connect, timeout, reset; connect & timeout if empty
+	Backoff     int      `json:"backoff,omitempty"`     //milliseconds before the first retry, doubled for each retry, 100 if 0
+	MaxBackoff  int      `json:"maxBackoff,omitempty"`  //milliseconds between the retries at most, 2000 if 0
+}
+
+// Circuit breaker of the upstream hosts of a Deliver Service, an open circuit fails the fetches without sending them
+type CircuitBreaker struct {
+	Enabled          bool `json:"enabled"`
+	FailureThreshold int  `json:"failureThreshold,omitempty"` //failed fetches in a row opening the circuit, 5 if 0
+	Cooldown         int  `json:"cooldown,omitempty"`         //seconds open before one trial fetch is let through, 30 if 0
+}
+
+// Timeouts (milliseconds) & connections of the upstream fetches of a Deliver Service, to its origins or
+// through the parents of the node
+type Upstream struct {
+	ConnectTimeout      int `json:"connectTimeout,omitempty"`      //to connect to the upstream, 10000 if 0
+	TLSHandshakeTimeout int `json:"tlsHandshakeTimeout,omitempty"` //for the TLS handshake with an HTTPS origin, 10000 if 0
+	TTFBTimeout         int `json:"ttfbTimeout,omitempty"`         //from the request sent to the response header, 10000 if 0
+	IdleReadTimeout     int `json:"idleReadTimeout,omitempty"`     //between two reads of the body, no limit if 0
+	TotalTimeout        int `json:"totalTimeout,omitempty"`        //for the whole fetch including the body, no limit if 0
+	MaxConnsPerHost     int `json:"maxConnsPerHost,omitempty"`     //connections to one upstream host, no limit if 0
+}
+
+// TLS towards the HTTPS origins of a Deliver Service
+type OriginTLS struct {
+	CABundle          string `json:"caBundle,omitempty"`          //PEM certificates trusted for the origins, the system roots if empty
+	ServerName        string `json:"serverName,omitempty"`        //SNI & name verified in the certificate of the origin, its host if empty
+	MinVersion        string `json:"minVersion,omitempty"`        //"1.0", "1.1", "1.2" or "1.3", 1.2 if empty
+	ClientCertificate string `json:"clientCertificate,omitempty"` //PEM certificate chain presented to the origins requiring one
+	ClientKey         string `json:"clientKey,omitempty"`         //PEM private key of the client certificate
 }
 
 // One Deliver Service
@@ -24,6 +195,29 @@ type DeliveryService struct {
 	ClientURL    string        `json:"clientURL"`    //URL from Client
 	OriginURL    string        `json:"originURL"`    //URL to Origin
 	RewriteRules []RewriteRule `json:"rewriteRules"` //ReWrite Rules
+
+	// Defaults (seconds) when the origin does not send the Cache-Control directives, 0 disables
+	StaleWhileRevalidate int `json:"staleWhileRevalidate,omitempty"` //serve stale while refreshing in background
+	StaleIfError         int `json:"staleIfError,omitempty"`         //serve stale when upstream fails
+
+	CacheKey CacheKey  `json:"cacheKey"` //query, header & cookie components of the cache key
+	TLS      TLSConfig `json:"tls"`      //HTTPS certificate & redirect
+
+	Compression Compression   `json:"compression"` //gzip & brotli compression
+	TokenAuth   TokenAuth     `json:"tokenAuth"`   //signed URLs
+	ACL         AccessControl `json:"acl"`         //client IP & country access control
+	RateLimit   RateLimit     `json:"rateLimit"`   //rate & concurrency limits
+
+	URLRewrites []URLRewriteRule `json:"urlRewrites,omitempty"` //URL rewrite rules in their order
+	Redirects   []RedirectRule   `json:"redirects,omitempty"`   //redirect rules in their order
+	CORS        CORS             `json:"cors"`                  //cross origin resource sharing
+	ErrorPages  []ErrorPage      `json:"errorPages,omitempty"`  //error pages per status code or class
+	OriginGroup OriginGroup      `json:"originGroup"`           //origins with health checks & failover
+
+	Retry          RetryPolicy    `json:"retry"`          //retries of the upstream fetches
+	CircuitBreaker CircuitBreaker `json:"circuitBreaker"` //circuit breaker of the upstream hosts
+	Upstream       Upstream       `json:"upstream"`       //timeouts & connections of the upstream fetches
+	OriginTLS      OriginTLS      `json:"originTLS"`      //trust & client certificate towards the HTTPS origins
 }
 
 // One Cache Node
@@ -36,6 +230,23 @@ type CacheNode struct {
     ParentPort int    `json:"parentPort"`         // Port for the CacheNode where upstream cache is listening
     MgmtPort   int    `json:"mgmtPort,omitempty"` //management port
 	PromPort   int    `json:"promPort,omitempty"` // prometheus port
+	TLSPort    int    `json:"tlsPort,omitempty"`  // Port for the CacheNode to listen for HTTPS, 0 disables
+	H2C        bool   `json:"h2c,omitempty"`       // accept cleartext HTTP/2 (h2c) on Port
+	ParentH2C  bool   `json:"parentH2C,omitempty"` // use cleartext HTTP/2 (h2c) towards the upstream cache
+
+	MaxConcurrentStreams int `json:"maxConcurrentStreams,omitempty"` // HTTP/2 streams per client connection, 0 for default
+	CollapseTimeout      int `json:"collapseTimeout,omitempty"`      // seconds a collapsed request waits for the fetch of another, 0 for default
+
+	ErrorPages []ErrorPage `json:"errorPages,omitempty"` // inline error pages when no DS matches or the DS has none
+
+	Parents        []Parent `json:"parents,omitempty"`        // upstream caches, each object goes through one by consistent hashing of its cache key; ParentIP/ParentPort if empty
+	OriginFallback bool     `json:"originFallback,omitempty"` // fetch from the origin when all the parents are down
+}
+
+// Upstream cache of a Cache Node
+type Parent struct {
+	IP   string `json:"ip"`   // IP address of the upstream cache
+	Port int    `json:"port"` // Port where the upstream cache is listening
 }
 // List of Cache Nodes
 type CacheNodes struct {
@@ -0,0 +1,345 @@
+package helper
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+)
+
+// bytes of a shared body kept in memory, the rest is spooled to the stored copy or a temporary file
+var sharedBodyMemLimit = 1 << 20
+
+// size of the reads from the source
+const sharedBodyChunk = 32 * 1024
+
+// ErrSharedBodyAborted ends the fill of a body nobody reads anymore
+var ErrSharedBodyAborted = errors.New("shared body aborted, no readers left")
+
+// SharedBody reads a body once and lets any number of readers stream it at their own pace, also
+// readers started while it is being filled. The source is read independently of the readers, so a
+// slow reader does not hold back the others. The first sharedBodyMemLimit bytes are kept in memory,
+// the rest in the file the body is stored to (WriteToFile), or a temporary file when it is not stored,
+// so a body is written to the disk once. Both are dropped once the fill is done and the last reference
+// is gone. A read error of the source reaches the readers after the data read before it.
+type SharedBody struct {
+	src         io.ReadCloser
+	mu          sync.Mutex
+	cond        *sync.Cond
+	mem         []byte
+	spool       *os.File // bytes past the memory until a stored copy is adopted
+	store       *os.File // file the body is stored to, handed over by WriteToFile
+	storeReader *os.File // read handle of the store once adopted, it holds the whole body
+	size        int64
+	err         error // io.EOF once filled, the error of the source otherwise
+	refs        int   // the owner & the open readers
+	done        chan struct{}
+}
+
+// NewSharedBody starts filling from src. The caller owns one reference, dropped with Release.
+func NewSharedBody(src io.ReadCloser) *SharedBody {
+	b := &SharedBody{
+		src:  src,
+		refs: 1,
+		done: make(chan struct{}),
+	}
+	b.cond = sync.NewCond(&b.mu)
+	go b.fill()
+	return b
+}
+
+// Share returns the shared body behind rc, when rc is a reader of a shared body which was not read from yet,
+// and a new shared body filled from rc otherwise. The caller owns one reference, dropped with Release.
+func Share(rc io.ReadCloser) *SharedBody {
+	if r, ok := rc.(*sharedBodyReader); ok {
+		r.b.mu.Lock()
+		shareable := !r.closed && r.off == 0
+		if shareable {
+			// the reference of the reader becomes the one of the caller
+			r.closed = true
+		}
+		r.b.mu.Unlock()
+		if shareable {
+			return r.b
+		}
+	}
+	return NewSharedBody(rc)
+}
+
+// InProgress reports if rc is a reader of a shared body still being filled
+func InProgress(rc io.ReadCloser) bool {
+	r, ok := rc.(*sharedBodyReader)
+	if !ok {
+		return false
+	}
+	select {
+	case <-r.b.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// Done is closed once the source is read to the end or failed
+func (b *SharedBody) Done() <-chan struct{} {
+	return b.done
+}
+
+// NewReader returns a reader from the start of the body, the caller must hold a reference
+func (b *SharedBody) NewReader() io.ReadCloser {
+	b.mu.Lock()
+	b.refs++
+	b.mu.Unlock()
+	return &sharedBodyReader{b: b}
+}
+
+// Release drops the reference of the owner
+func (b *SharedBody) Release() {
+	b.mu.Lock()
+	b.unref()
+	b.mu.Unlock()
+}
+
+// unref drops a reference, b.mu is held
+func (b *SharedBody) unref() {
+	b.refs--
+	if b.refs == 0 {
+		b.cleanup()
+	}
+}
+
+// cleanup drops the data once filled, the fill stops at its next read otherwise. b.mu is held.
+func (b *SharedBody) cleanup() {
+	if b.err == nil {
+		return
+	}
+	b.mem = nil
+	if b.spool != nil {
+		b.spool.Close()
+		os.Remove(b.spool.Name())
+		b.spool = nil
+	}
+	if b.storeReader != nil {
+		// the stored copy belongs to the store
+		b.storeReader.Close()
+		b.storeReader = nil
+	}
+}
+
+func (b *SharedBody) fill() {
+	var err error
+	if b.src == nil {
+		err = io.EOF
+	}
+	buf := make([]byte, sharedBodyChunk)
+	for err == nil {
+		b.mu.Lock()
+		unused := b.refs == 0
+		b.mu.Unlock()
+		if unused {
+			err = ErrSharedBodyAborted
+			break
+		}
+		var n int
+		n, err = b.src.Read(buf)
+		if n > 0 {
+			if werr := b.write(buf[:n]); werr != nil {
+				err = werr
+			}
+		}
+	}
+	if b.src != nil {
+		b.src.Close()
+	}
+	if !errors.Is(err, io.EOF) {
+		slog.Warn("Shared body fill failed", "error", err)
+	}
+
+	b.mu.Lock()
+	b.err = err
+	close(b.done)
+	b.cond.Broadcast()
+	if b.refs == 0 {
+		b.cleanup()
+	}
+	b.mu.Unlock()
+}
+
+// write appends p to the body, only the fill writes
+func (b *SharedBody) write(p []byte) error {
+	b.mu.Lock()
+	pending := b.store != nil && b.storeReader == nil
+	b.mu.Unlock()
+	if pending {
+		b.adopt()
+	}
+
+	b.mu.Lock()
+	if b.storeReader != nil {
+		// the stored copy gets all the bytes, the readers read the ones past the memory from it
+		store, off := b.store, b.size
+		if room := sharedBodyMemLimit - len(b.mem); room > 0 {
+			b.mem = append(b.mem, p[:min(room, len(p))]...)
+		}
+		b.mu.Unlock()
+		if _, err := store.WriteAt(p, off); err != nil {
+			return err
+		}
+		b.mu.Lock()
+		b.size += int64(len(p))
+		b.cond.Broadcast()
+		b.mu.Unlock()
+		return nil
+	}
+	if room := sharedBodyMemLimit - len(b.mem); room > 0 {
+		n := min(room, len(p))
+		b.mem = append(b.mem, p[:n]...)
+		b.size += int64(n)
+		p = p[n:]
+		b.cond.Broadcast()
+	}
+	spool, off := b.spool, b.size-int64(len(b.mem))
+	b.mu.Unlock()
+	if len(p) == 0 {
+		return nil
+	}
+
+	if spool == nil {
+		var err error
+		spool, err = os.CreateTemp("", "cdn-body-")
+		if err != nil {
+			return err
+		}
+		b.mu.Lock()
+		b.spool = spool
+		b.mu.Unlock()
+	}
+	// the readers only read below size, the bytes written here are not read yet
+	if _, err := spool.WriteAt(p, off); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	b.size += int64(len(p))
+	b.cond.Broadcast()
+	b.mu.Unlock()
+	return nil
+}
+
+// adopt makes the file handed over by WriteToFile the spool of the body: the bytes filled so far are
+// written to it, the next ones are only written there. The temporary file of the bytes filled before, if
+// any, stays for the readers in it until the cleanup. A failed adoption leaves the copy to WriteToFile.
+func (b *SharedBody) adopt() {
+	b.mu.Lock()
+	store, mem, spool, size := b.store, b.mem, b.spool, b.size
+	b.mu.Unlock()
+
+	reader, err := os.Open(store.Name())
+	if err == nil {
+		_, err = store.WriteAt(mem, 0)
+	}
+	if err == nil && spool != nil {
+		_, err = io.Copy(io.NewOffsetWriter(store, int64(len(mem))), io.NewSectionReader(spool, 0, size-int64(len(mem))))
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err != nil {
+		slog.Warn("Shared body not spooled to the stored copy", "file", store.Name(), "error", err)
+		if reader != nil {
+			reader.Close()
+		}
+		b.store = nil
+		return
+	}
+	b.storeReader = reader
+}
+
+// WriteToFile writes the body read by rc to f and returns the number of bytes written. When rc is a reader
+// of a shared body which was not read from yet, the fill writes the body to f as it goes and the readers
+// read the bytes past the memory from f, the body is not spooled to a temporary file as well. f must
+// not be written to until WriteToFile returns.
+func WriteToFile(f *os.File, rc io.ReadCloser) (int64, error) {
+	if r, ok := rc.(*sharedBodyReader); ok {
+		b := r.b
+		b.mu.Lock()
+		handover := !r.closed && r.off == 0 && b.err == nil && b.store == nil
+		if handover {
+			b.store = f
+		}
+		b.mu.Unlock()
+		if handover {
+			<-b.done
+			b.mu.Lock()
+			adopted, size, err := b.storeReader != nil, b.size, b.err
+			b.mu.Unlock()
+			if adopted {
+				if errors.Is(err, io.EOF) {
+					err = nil
+				}
+				return size, err
+			}
+			// the fill ended before it took f over, or failed to
+		}
+	}
+	return io.Copy(f, rc)
+}
+
+type sharedBodyReader struct {
+	b      *SharedBody
+	off    int64
+	closed bool // guarded by b.mu
+}
+
+func (r *sharedBodyReader) Read(p []byte) (int, error) {
+	b := r.b
+	b.mu.Lock()
+	for !r.closed && r.off >= b.size && b.err == nil {
+		b.cond.Wait()
+	}
+	if r.closed {
+		b.mu.Unlock()
+		return 0, os.ErrClosed
+	}
+	if r.off >= b.size {
+		err := b.err
+		b.mu.Unlock()
+		return 0, err
+	}
+	if r.off < int64(len(b.mem)) {
+		n := copy(p, b.mem[r.off:])
+		r.off += int64(n)
+		b.mu.Unlock()
+		return n, nil
+	}
+	var spool io.ReaderAt = b.spool
+	base := int64(len(b.mem))
+	if b.storeReader != nil {
+		spool, base = b.storeReader, 0
+	}
+	avail := b.size - r.off
+	b.mu.Unlock()
+
+	// the reference of the reader keeps the spool open
+	if int64(len(p)) > avail {
+		p = p[:avail]
+	}
+	n, err := spool.ReadAt(p, r.off-base)
+	r.off += int64(n)
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+func (r *sharedBodyReader) Close() error {
+	b := r.b
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	b.cond.Broadcast()
+	b.unref()
+	return nil
+}
@@ -0,0 +1,258 @@
+package helper
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// chunkSource hands out the chunks sent on its channel, then err once the channel is closed
+type chunkSource struct {
+	chunks chan string
+	err    error
+	closed chan struct{}
+}
+
+func newChunkSource(err error) *chunkSource {
+	return &chunkSource{chunks: make(chan string), err: err, closed: make(chan struct{})}
+}
+
+func (s *chunkSource) Read(p []byte) (int, error) {
+	chunk, ok := <-s.chunks
+	if !ok {
+		return 0, s.err
+	}
+	return copy(p, chunk), nil
+}
+
+func (s *chunkSource) Close() error {
+	close(s.closed)
+	return nil
+}
+
+func waitDone(t *testing.T, b *SharedBody) {
+	t.Helper()
+	select {
+	case <-b.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("fill not done")
+	}
+}
+
+func TestSharedBodyReaders(t *testing.T) {
+	defer func(limit int) { sharedBodyMemLimit = limit }(sharedBodyMemLimit)
+	sharedBodyMemLimit = 10
+
+	content := strings.Repeat("0123456789abcdef", 1000)
+	b := NewSharedBody(io.NopCloser(strings.NewReader(content)))
+	early := b.NewReader()
+	waitDone(t, b)
+	late := b.NewReader()
+	b.Release()
+
+	b.mu.Lock()
+	spool := b.spool.Name()
+	b.mu.Unlock()
+	for name, r := range map[string]io.ReadCloser{"early": early, "late": late} {
+		got, err := io.ReadAll(r)
+		if err != nil || string(got) != content {
+			t.Errorf("%s reader: got %d bytes, error %v, want %d bytes", name, len(got), err, len(content))
+		}
+		r.Close()
+	}
+	if _, err := os.Stat(spool); !os.IsNotExist(err) {
+		t.Errorf("spool file %s left after the last reader closed", spool)
+	}
+}
+
+func TestSharedBodySlowReader(t *testing.T) {
+	src := newChunkSource(io.EOF)
+	b := NewSharedBody(src)
+	slow := b.NewReader()
+	fast := b.NewReader()
+	b.Release()
+
+	go func() {
+		for _, chunk := range []string{"one ", "two ", "three"} {
+			src.chunks <- chunk
+		}
+		close(src.chunks)
+	}()
+	// the slow reader has not read yet, the fill and the fast reader go on
+	got, err := io.ReadAll(fast)
+	if err != nil || string(got) != "one two three" {
+		t.Fatalf("fast reader: got %q, error %v", got, err)
+	}
+	waitDone(t, b)
+	got, err = io.ReadAll(slow)
+	if err != nil || string(got) != "one two three" {
+		t.Fatalf("slow reader: got %q, error %v", got, err)
+	}
+	slow.Close()
+	fast.Close()
+}
+
+func TestSharedBodySourceError(t *testing.T) {
+	errUpstream := errors.New("connection reset")
+	src := newChunkSource(errUpstream)
+	b := NewSharedBody(src)
+	r := b.NewReader()
+	b.Release()
+	defer r.Close()
+
+	go func() {
+		src.chunks <- "partial"
+		close(src.chunks)
+	}()
+	got, err := io.ReadAll(r)
+	if string(got) != "partial" || !errors.Is(err, errUpstream) {
+		t.Fatalf("got %q, error %v, want the data then %v", got, err, errUpstream)
+	}
+	select {
+	case <-src.closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("source not closed")
+	}
+}
+
+func TestSharedBodyAbort(t *testing.T) {
+	src := newChunkSource(io.EOF)
+	b := NewSharedBody(src)
+	r := b.NewReader()
+	b.Release()
+
+	src.chunks <- "first"
+	r.Close()
+	// the fill notices nobody reads once the pending read returns
+	go func() {
+		src.chunks <- "second"
+	}()
+	waitDone(t, b)
+	b.mu.Lock()
+	err := b.err
+	b.mu.Unlock()
+	if !errors.Is(err, ErrSharedBodyAborted) {
+		t.Errorf("fill ended with %v, want %v", err, ErrSharedBodyAborted)
+	}
+	select {
+	case <-src.closed:
+	default:
+		t.Error("source not closed")
+	}
+}
+
+func TestShare(t *testing.T) {
+	src := newChunkSource(io.EOF)
+	b := NewSharedBody(src)
+	r := b.NewReader()
+	b.Release()
+	if !InProgress(r) {
+		t.Error("reader of a filling body not in progress")
+	}
+	if got := Share(r); got != b {
+		t.Error("Share of an unread reader did not return its body")
+	}
+	// the reference of r is now the one of the caller
+	r2 := b.NewReader()
+	b.Release()
+	src.chunks <- "content"
+	close(src.chunks)
+	got, err := io.ReadAll(r2)
+	r2.Close()
+	if err != nil || string(got) != "content" {
+		t.Errorf("got %q, error %v", got, err)
+	}
+	if InProgress(r2) {
+		t.Error("reader of a filled body in progress")
+	}
+
+	other := io.NopCloser(bytes.NewReader([]byte("other")))
+	if InProgress(other) {
+		t.Error("plain body reported in progress")
+	}
+	shared := Share(other)
+	r3 := shared.NewReader()
+	shared.Release()
+	got, _ = io.ReadAll(r3)
+	r3.Close()
+	if string(got) != "other" {
+		t.Errorf("got %q from a shared plain body", got)
+	}
+}
+
+func TestWriteToFile(t *testing.T) {
+	defer func(limit int) { sharedBodyMemLimit = limit }(sharedBodyMemLimit)
+	sharedBodyMemLimit = 10
+
+	// handed over before the body spills, or after
+	for _, spilled := range []string{"", "0123456789abcdef"} {
+		src := newChunkSource(io.EOF)
+		b := NewSharedBody(src)
+		client := b.NewReader()
+		stored := b.NewReader()
+		b.Release()
+		waitFor := func(cond func() bool) {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			for !cond() {
+				b.mu.Unlock()
+				time.Sleep(time.Millisecond)
+				b.mu.Lock()
+			}
+		}
+		if spilled != "" {
+			src.chunks <- spilled
+			waitFor(func() bool { return b.size == int64(len(spilled)) })
+		}
+
+		f, err := os.CreateTemp(t.TempDir(), "object-")
+		if err != nil {
+			t.Fatal(err)
+		}
+		type result struct {
+			n   int64
+			err error
+		}
+		written := make(chan result)
+		go func() {
+			n, err := WriteToFile(f, stored)
+			written <- result{n, err}
+		}()
+		waitFor(func() bool { return b.store != nil })
+		go func() {
+			for _, chunk := range []string{"one ", "two ", "three"} {
+				src.chunks <- chunk
+			}
+			close(src.chunks)
+		}()
+
+		content := spilled + "one two three"
+		got, err := io.ReadAll(client)
+		if err != nil || string(got) != content {
+			t.Errorf("client reader: got %q, error %v", got, err)
+		}
+		res := <-written
+		if res.err != nil || res.n != int64(len(content)) {
+			t.Errorf("WriteToFile: %d bytes, error %v, want %d bytes", res.n, res.err, len(content))
+		}
+		f.Close()
+		if data, _ := os.ReadFile(f.Name()); string(data) != content {
+			t.Errorf("stored copy: got %q", data)
+		}
+		b.mu.Lock()
+		tmp, adopted := b.spool, b.storeReader != nil
+		b.mu.Unlock()
+		if !adopted {
+			t.Error("stored copy not read by the readers")
+		}
+		if spilled == "" && tmp != nil {
+			t.Error("body spooled to a temporary file while it is stored")
+		}
+		client.Close()
+		stored.Close()
+	}
+}
@@ -23,6 +23,98 @@ func ConfigToProtoDS(serviceList *config.DeliveryServices) []*DeliveryService {
 			ClientURL:    service.ClientURL,
 			OriginURL:    service.OriginURL,
 			RewriteRules: make([]*RewriteRule, len(service.RewriteRules)),
+
+			StaleWhileRevalidate: int32(service.StaleWhileRevalidate),
+			StaleIfError:         int32(service.StaleIfError),
+
+			CacheKey: &CacheKey{
+				QueryMode:   int32(service.CacheKey.QueryMode),
+				QueryParams: service.CacheKey.QueryParams,
+				SortQuery:   service.CacheKey.SortQuery,
+				Headers:     service.CacheKey.Headers,
+				Cookies:     service.CacheKey.Cookies,
+				IgnoreCase:  service.CacheKey.IgnoreCase,
+			},
+			Tls: &TLSConfig{
+				Certificate:     service.TLS.Certificate,
+				PrivateKey:      service.TLS.PrivateKey,
+				RedirectToHttps: service.TLS.RedirectToHTTPS,
+			},
+			Compression: &Compression{
+				Enabled:   service.Compression.Enabled,
+				MimeTypes: service.Compression.MimeTypes,
+				MinSize:   int32(service.Compression.MinSize),
+			},
+			TokenAuth: &TokenAuth{
+				Enabled:    service.TokenAuth.Enabled,
+				QueryParam: service.TokenAuth.QueryParam,
+				CookieName: service.TokenAuth.CookieName,
+				RequireIp:  service.TokenAuth.RequireIP,
+				Keys:       make([]*TokenKey, len(service.TokenAuth.Keys)),
+			},
+			Acl: &AccessControl{
+				AllowCidrs:     service.ACL.AllowCIDRs,
+				DenyCidrs:      service.ACL.DenyCIDRs,
+				AllowCountries: service.ACL.AllowCountries,
+				DenyCountries:  service.ACL.DenyCountries,
+			},
+			RateLimit: &RateLimit{
+				ClientRate:    service.RateLimit.ClientRate,
+				ClientBurst:   int32(service.RateLimit.ClientBurst),
+				DsRate:        service.RateLimit.DSRate,
+				DsBurst:       int32(service.RateLimit.DSBurst),
+				MaxConcurrent: int32(service.RateLimit.MaxConcurrent),
+			},
+			Cors: &Cors{
+				Enabled:          service.CORS.Enabled,
+				AllowOrigins:     service.CORS.AllowOrigins,
+				AllowMethods:     service.CORS.AllowMethods,
+				AllowHeaders:     service.CORS.AllowHeaders,
+				ExposeHeaders:    service.CORS.ExposeHeaders,
+				AllowCredentials: service.CORS.AllowCredentials,
+				MaxAge:           int32(service.CORS.MaxAge),
+			},
+		}
+		for j, key := range service.TokenAuth.Keys {
+			protoService.TokenAuth.Keys[j] = &TokenKey{Id: key.ID, Secret: key.Secret}
+		}
+		for _, rule := range service.URLRewrites {
+			protoService.UrlRewrites = append(protoService.UrlRewrites, &UrlRewriteRule{
+				Pattern:      rule.Pattern,
+				Replacement:  rule.Replacement,
+				UpstreamOnly: rule.UpstreamOnly,
+			})
+		}
+		protoService.ErrorPages = configToProtoErrorPages(service.ErrorPages)
+		protoService.OriginGroup = configToProtoOriginGroup(&service.OriginGroup)
+		protoService.Retry = configToProtoRetry(&service.Retry)
+		protoService.CircuitBreaker = &CircuitBreaker{
+			Enabled:          service.CircuitBreaker.Enabled,
+			FailureThreshold: int32(service.CircuitBreaker.FailureThreshold),
+			Cooldown:         int32(service.CircuitBreaker.Cooldown),
+		}
+		protoService.Upstream = &Upstream{
+			ConnectTimeout:      int32(service.Upstream.ConnectTimeout),
+			TlsHandshakeTimeout: int32(service.Upstream.TLSHandshakeTimeout),
+			TtfbTimeout:         int32(service.Upstream.TTFBTimeout),
+			IdleReadTimeout:     int32(service.Upstream.IdleReadTimeout),
+			TotalTimeout:        int32(service.Upstream.TotalTimeout),
+			MaxConnsPerHost:     int32(service.Upstream.MaxConnsPerHost),
+		}
+		protoService.OriginTLS = &OriginTLS{
+			CaBundle:          service.OriginTLS.CABundle,
+			ServerName:        service.OriginTLS.ServerName,
+			MinVersion:        service.OriginTLS.MinVersion,
+			ClientCertificate: service.OriginTLS.ClientCertificate,
+			ClientKey:         service.OriginTLS.ClientKey,
+		}
+		for _, rule := range service.Redirects {
+			protoService.Redirects = append(protoService.Redirects, &RedirectRule{
+				Source:        rule.Source,
+				Target:        rule.Target,
+				StatusCode:    int32(rule.StatusCode),
+				PreserveQuery: rule.PreserveQuery,
+			})
 		}
 
 		// Iterate over rewrite rules for the service
@@ -30,9 +122,14 @@ func ConfigToProtoDS(serviceList *config.DeliveryServices) []*DeliveryService {
 
 			// Create a new protobuf RewriteRule
 			protoRule := &RewriteRule{
-				HeaderName: rule.HeaderName,
-				Operation:  int32(rule.Operation),
-				Value:      rule.Value,
+				HeaderName:    rule.HeaderName,
+				Operation:     int32(rule.Operation),
+				Value:         rule.Value,
+				Phase:         int32(rule.Phase),
+				Pattern:       rule.Pattern,
+				IfHeader:      rule.IfHeader,
+				IfHeaderMatch: rule.IfHeaderMatch,
+				IfPath:        rule.IfPath,
 			}
 			protoService.RewriteRules[j] = protoRule
 		}
@@ -67,6 +164,127 @@ func ProtoToConfigDS(serviceList []*DeliveryService) *config.DeliveryServices {
 			ClientURL:    protoService.ClientURL,
 			OriginURL:    protoService.OriginURL,
 			RewriteRules: make([]config.RewriteRule, len(protoService.RewriteRules)),
+
+			StaleWhileRevalidate: int(protoService.StaleWhileRevalidate),
+			StaleIfError:         int(protoService.StaleIfError),
+		}
+
+		// A missing cache key keeps the defaults
+		if protoKey := protoService.CacheKey; protoKey != nil {
+			internalService.CacheKey = config.CacheKey{
+				QueryMode:   int(protoKey.QueryMode),
+				QueryParams: protoKey.QueryParams,
+				SortQuery:   protoKey.SortQuery,
+				Headers:     protoKey.Headers,
+				Cookies:     protoKey.Cookies,
+				IgnoreCase:  protoKey.IgnoreCase,
+			}
+		}
+		if protoTLS := protoService.Tls; protoTLS != nil {
+			internalService.TLS = config.TLSConfig{
+				Certificate:     protoTLS.Certificate,
+				PrivateKey:      protoTLS.PrivateKey,
+				RedirectToHTTPS: protoTLS.RedirectToHttps,
+			}
+		}
+		if protoCompression := protoService.Compression; protoCompression != nil {
+			internalService.Compression = config.Compression{
+				Enabled:   protoCompression.Enabled,
+				MimeTypes: protoCompression.MimeTypes,
+				MinSize:   int(protoCompression.MinSize),
+			}
+		}
+		if protoTokenAuth := protoService.TokenAuth; protoTokenAuth != nil {
+			internalService.TokenAuth = config.TokenAuth{
+				Enabled:    protoTokenAuth.Enabled,
+				QueryParam: protoTokenAuth.QueryParam,
+				CookieName: protoTokenAuth.CookieName,
+				RequireIP:  protoTokenAuth.RequireIp,
+			}
+			for _, protoKey := range protoTokenAuth.Keys {
+				if protoKey == nil {
+					continue
+				}
+				internalService.TokenAuth.Keys = append(internalService.TokenAuth.Keys, config.TokenKey{ID: protoKey.Id, Secret: protoKey.Secret})
+			}
+		}
+		if protoACL := protoService.Acl; protoACL != nil {
+			internalService.ACL = config.AccessControl{
+				AllowCIDRs:     protoACL.AllowCidrs,
+				DenyCIDRs:      protoACL.DenyCidrs,
+				AllowCountries: protoACL.AllowCountries,
+				DenyCountries:  protoACL.DenyCountries,
+			}
+		}
+		if protoRateLimit := protoService.RateLimit; protoRateLimit != nil {
+			internalService.RateLimit = config.RateLimit{
+				ClientRate:    protoRateLimit.ClientRate,
+				ClientBurst:   int(protoRateLimit.ClientBurst),
+				DSRate:        protoRateLimit.DsRate,
+				DSBurst:       int(protoRateLimit.DsBurst),
+				MaxConcurrent: int(protoRateLimit.MaxConcurrent),
+			}
+		}
+		if protoCORS := protoService.Cors; protoCORS != nil {
+			internalService.CORS = config.CORS{
+				Enabled:          protoCORS.Enabled,
+				AllowOrigins:     protoCORS.AllowOrigins,
+				AllowMethods:     protoCORS.AllowMethods,
+				AllowHeaders:     protoCORS.AllowHeaders,
+				ExposeHeaders:    protoCORS.ExposeHeaders,
+				AllowCredentials: protoCORS.AllowCredentials,
+				MaxAge:           int(protoCORS.MaxAge),
+			}
+		}
+		for _, protoRule := range protoService.UrlRewrites {
+			if protoRule == nil {
+				continue
+			}
+			internalService.URLRewrites = append(internalService.URLRewrites, config.URLRewriteRule{
+				Pattern:      protoRule.Pattern,
+				Replacement:  protoRule.Replacement,
+				UpstreamOnly: protoRule.UpstreamOnly,
+			})
+		}
+		internalService.ErrorPages = protoToConfigErrorPages(protoService.ErrorPages)
+		internalService.OriginGroup = protoToConfigOriginGroup(protoService.OriginGroup)
+		internalService.Retry = protoToConfigRetry(protoService.Retry)
+		if breaker := protoService.CircuitBreaker; breaker != nil {
+			internalService.CircuitBreaker = config.CircuitBreaker{
+				Enabled:          breaker.Enabled,
+				FailureThreshold: int(breaker.FailureThreshold),
+				Cooldown:         int(breaker.Cooldown),
+			}
+		}
+		if upstream := protoService.Upstream; upstream != nil {
+			internalService.Upstream = config.Upstream{
+				ConnectTimeout:      int(upstream.ConnectTimeout),
+				TLSHandshakeTimeout: int(upstream.TlsHandshakeTimeout),
+				TTFBTimeout:         int(upstream.TtfbTimeout),
+				IdleReadTimeout:     int(upstream.IdleReadTimeout),
+				TotalTimeout:        int(upstream.TotalTimeout),
+				MaxConnsPerHost:     int(upstream.MaxConnsPerHost),
+			}
+		}
+		if originTLS := protoService.OriginTLS; originTLS != nil {
+			internalService.OriginTLS = config.OriginTLS{
+				CABundle:          originTLS.CaBundle,
+				ServerName:        originTLS.ServerName,
+				MinVersion:        originTLS.MinVersion,
+				ClientCertificate: originTLS.ClientCertificate,
+				ClientKey:         originTLS.ClientKey,
+			}
+		}
+		for _, protoRule := range protoService.Redirects {
+			if protoRule == nil {
+				continue
+			}
+			internalService.Redirects = append(internalService.Redirects, config.RedirectRule{
+				Source:        protoRule.Source,
+				Target:        protoRule.Target,
+				StatusCode:    int(protoRule.StatusCode),
+				PreserveQuery: protoRule.PreserveQuery,
+			})
 		}
 
 		// Iterate over rewrite rules for the protobuf service
@@ -77,9 +295,14 @@ func ProtoToConfigDS(serviceList []*DeliveryService) *config.DeliveryServices {
 
 			// Create an internal RewriteRule object
 			internalRule := config.RewriteRule{
-				HeaderName: protoRule.HeaderName,
-				Operation:  int(protoRule.Operation), // Convert int32 to int
-				Value:      protoRule.Value,
+				HeaderName:    protoRule.HeaderName,
+				Operation:     int(protoRule.Operation), // Convert int32 to int
+				Value:         protoRule.Value,
+				Phase:         int(protoRule.Phase),
+				Pattern:       protoRule.Pattern,
+				IfHeader:      protoRule.IfHeader,
+				IfHeaderMatch: protoRule.IfHeaderMatch,
+				IfPath:        protoRule.IfPath,
 			}
 			internalService.RewriteRules[j] = internalRule
 		}
@@ -104,6 +327,17 @@ func ConfigToProtoCN(cacheNode *config.CacheNode) *CacheNode {
 		Type:       cacheNode.Type,
 		ParentIP:   cacheNode.ParentIP,
 		ParentPort: int32(cacheNode.ParentPort),
+		TlsPort:    int32(cacheNode.TLSPort),
+		H2C:        cacheNode.H2C,
+		ParentH2C:  cacheNode.ParentH2C,
+
+		MaxConcurrentStreams: int32(cacheNode.MaxConcurrentStreams),
+		CollapseTimeout:      int32(cacheNode.CollapseTimeout),
+		ErrorPages:           configToProtoErrorPages(cacheNode.ErrorPages),
+		OriginFallback:       cacheNode.OriginFallback,
+	}
+	for _, parent := range cacheNode.Parents {
+		ret.Parents = append(ret.Parents, &Parent{Ip: parent.IP, Port: int32(parent.Port)})
 	}
 	return ret
 }
@@ -123,6 +357,118 @@ func ProtoToConfigCN(cacheNode *CacheNode) *config.CacheNode {
 		Type:       cacheNode.Type,
 		ParentIP:   cacheNode.ParentIP,
 		ParentPort: int(cacheNode.ParentPort),
+		TLSPort:    int(cacheNode.TlsPort),
+		H2C:        cacheNode.H2C,
+		ParentH2C:  cacheNode.ParentH2C,
+
+		MaxConcurrentStreams: int(cacheNode.MaxConcurrentStreams),
+		CollapseTimeout:      int(cacheNode.CollapseTimeout),
+		ErrorPages:           protoToConfigErrorPages(cacheNode.ErrorPages),
+		OriginFallback:       cacheNode.OriginFallback,
+	}
+	for _, parent := range cacheNode.Parents {
+		if parent == nil {
+			continue
+		}
+		ret.Parents = append(ret.Parents, config.Parent{IP: parent.Ip, Port: int(parent.Port)})
+	}
+	return ret
+}
+
+func configToProtoErrorPages(pages []config.ErrorPage) []*ErrorPage {
+	var ret []*ErrorPage
+	for _, page := range pages {
+		ret = append(ret, &ErrorPage{
+			Status:      page.Status,
+			Url:         page.URL,
+			Content:     page.Content,
+			ContentType: page.ContentType,
+		})
+	}
+	return ret
+}
+
+func protoToConfigErrorPages(pages []*ErrorPage) []config.ErrorPage {
+	var ret []config.ErrorPage
+	for _, page := range pages {
+		if page == nil {
+			continue
+		}
+		ret = append(ret, config.ErrorPage{
+			Status:      page.Status,
+			URL:         page.Url,
+			Content:     page.Content,
+			ContentType: page.ContentType,
+		})
+	}
+	return ret
+}
+
+func configToProtoOriginGroup(group *config.OriginGroup) *OriginGroup {
+	ret := &OriginGroup{
+		HealthPath:     group.HealthPath,
+		HealthInterval: int32(group.HealthInterval),
+		HealthTimeout:  int32(group.HealthTimeout),
+		UnhealthyAfter: int32(group.UnhealthyAfter),
+		HealthyAfter:   int32(group.HealthyAfter),
+	}
+	for _, origin := range group.Origins {
+		ret.Origins = append(ret.Origins, &Origin{Url: origin.URL, Backup: origin.Backup, Weight: int32(origin.Weight)})
+	}
+	for _, status := range group.FailoverStatuses {
+		ret.FailoverStatuses = append(ret.FailoverStatuses, int32(status))
+	}
+	return ret
+}
+
+func protoToConfigOriginGroup(group *OriginGroup) config.OriginGroup {
+	if group == nil {
+		return config.OriginGroup{}
+	}
+	ret := config.OriginGroup{
+		HealthPath:     group.HealthPath,
+		HealthInterval: int(group.HealthInterval),
+		HealthTimeout:  int(group.HealthTimeout),
+		UnhealthyAfter: int(group.UnhealthyAfter),
+		HealthyAfter:   int(group.HealthyAfter),
+	}
+	for _, origin := range group.Origins {
+		if origin == nil {
+			continue
+		}
+		ret.Origins = append(ret.Origins, config.Origin{URL: origin.Url, Backup: origin.Backup, Weight: int(origin.Weight)})
+	}
+	for _, status := range group.FailoverStatuses {
+		ret.FailoverStatuses = append(ret.FailoverStatuses, int(status))
+	}
+	return ret
+}
+
+func configToProtoRetry(retry *config.RetryPolicy) *RetryPolicy {
+	ret := &RetryPolicy{
+		MaxAttempts: int32(retry.MaxAttempts),
+		Errors:      retry.Errors,
+		Backoff:     int32(retry.Backoff),
+		MaxBackoff:  int32(retry.MaxBackoff),
+	}
+	for _, status := range retry.Statuses {
+		ret.Statuses = append(ret.Statuses, int32(status))
+	}
+	return ret
+}
+
+func protoToConfigRetry(retry *RetryPolicy) config.RetryPolicy {
+	if retry == nil {
+		return config.RetryPolicy{}
+	}
+	ret := config.RetryPolicy{
+		MaxAttempts: int(retry.MaxAttempts),
+		Errors:      retry.Errors,
+		Backoff:     int(retry.Backoff),
+		MaxBackoff:  int(retry.MaxBackoff),
+	}
+	for _, status := range retry.Statuses {
+		ret.Statuses = append(ret.Statuses, int(status))
 	}
 	return ret
 }
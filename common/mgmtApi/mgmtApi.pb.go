@@ -420,30 +420,1432 @@ func (x *InvalidateCacheStatusResponse) GetStatus() string {
 	return ""
 }
 
+// Request to get the health of the origins
+type OriginHealthRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ds            string                 `protobuf:"bytes,1,opt,name=ds,proto3" json:"ds,omitempty"` // Name of the delivery service, all if empty
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OriginHealthRequest) Reset() {
+	*x = OriginHealthRequest{}
+	mi := &file_mgmtApi_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OriginHealthRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OriginHealthRequest) ProtoMessage() {}
+
+func (x *OriginHealthRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mgmtApi_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OriginHealthRequest.ProtoReflect.Descriptor instead.
+func (*OriginHealthRequest) Descriptor() ([]byte, []int) {
+	return file_mgmtApi_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *OriginHealthRequest) GetDs() string {
+	if x != nil {
+		return x.Ds
+	}
+	return ""
+}
+
+// Response for getting the health of the origins
+type OriginHealthResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Origins       []*OriginStatus        `protobuf:"bytes,1,rep,name=origins,proto3" json:"origins,omitempty"` // Health of each origin
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OriginHealthResponse) Reset() {
+	*x = OriginHealthResponse{}
+	mi := &file_mgmtApi_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OriginHealthResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OriginHealthResponse) ProtoMessage() {}
+
+func (x *OriginHealthResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_mgmtApi_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OriginHealthResponse.ProtoReflect.Descriptor instead.
+func (*OriginHealthResponse) Descriptor() ([]byte, []int) {
+	return file_mgmtApi_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *OriginHealthResponse) GetOrigins() []*OriginStatus {
+	if x != nil {
+		return x.Origins
+	}
+	return nil
+}
+
+// OriginStatus represents the health of one origin of a delivery service
+type OriginStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ds            string                 `protobuf:"bytes,1,opt,name=ds,proto3" json:"ds,omitempty"`                // Name of the delivery service
+	Url           string                 `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`              // Origin
+	Backup        bool                   `protobuf:"varint,3,opt,name=backup,proto3" json:"backup,omitempty"`       // Backup origin
+	Healthy       bool                   `protobuf:"varint,4,opt,name=healthy,proto3" json:"healthy,omitempty"`     // Result of the probes, true until probed
+	LastCheck     int64                  `protobuf:"varint,5,opt,name=lastCheck,proto3" json:"lastCheck,omitempty"` // Unix time of the last probe, 0 if not probed
+	LastError     string                 `protobuf:"bytes,6,opt,name=lastError,proto3" json:"lastError,omitempty"`  // Why the last probe failed, empty if it passed
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OriginStatus) Reset() {
+	*x = OriginStatus{}
+	mi := &file_mgmtApi_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OriginStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OriginStatus) ProtoMessage() {}
+
+func (x *OriginStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_mgmtApi_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OriginStatus.ProtoReflect.Descriptor instead.
+func (*OriginStatus) Descriptor() ([]byte, []int) {
+	return file_mgmtApi_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *OriginStatus) GetDs() string {
+	if x != nil {
+		return x.Ds
+	}
+	return ""
+}
+
+func (x *OriginStatus) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *OriginStatus) GetBackup() bool {
+	if x != nil {
+		return x.Backup
+	}
+	return false
+}
+
+func (x *OriginStatus) GetHealthy() bool {
+	if x != nil {
+		return x.Healthy
+	}
+	return false
+}
+
+func (x *OriginStatus) GetLastCheck() int64 {
+	if x != nil {
+		return x.LastCheck
+	}
+	return 0
+}
+
+func (x *OriginStatus) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
 // Config represents the configuration for the cache node
 type Config struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	ServiceList   []*DeliveryService     `protobuf:"bytes,1,rep,name=service_list,json=serviceList,proto3" json:"service_list,omitempty"` // List of delivery services
-	Node          *CacheNode             `protobuf:"bytes,2,opt,name=node,proto3" json:"node,omitempty"`                                  // Cache node details
+	ServiceList   []*DeliveryService     `protobuf:"bytes,1,rep,name=service_list,json=serviceList,proto3" json:"service_list,omitempty"` // List of delivery services
+	Node          *CacheNode             `protobuf:"bytes,2,opt,name=node,proto3" json:"node,omitempty"`                                  // Cache node details
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Config) Reset() {
+	*x = Config{}
+	mi := &file_mgmtApi_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Config) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Config) ProtoMessage() {}
+
+func (x *Config) ProtoReflect() protoreflect.Message {
+	mi := &file_mgmtApi_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Config.ProtoReflect.Descriptor instead.
+func (*Config) Descriptor() ([]byte, []int) {
+	return file_mgmtApi_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Config) GetServiceList() []*DeliveryService {
+	if x != nil {
+		return x.ServiceList
+	}
+	return nil
+}
+
+func (x *Config) GetNode() *CacheNode {
+	if x != nil {
+		return x.Node
+	}
+	return nil
+}
+
+// DeliveryService represents a single delivery service
+type DeliveryService struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	Name                 string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`                                  // Name of the delivery service
+	ClientURL            string                 `protobuf:"bytes,2,opt,name=clientURL,proto3" json:"clientURL,omitempty"`                        // URL from the client
+	OriginURL            string                 `protobuf:"bytes,3,opt,name=originURL,proto3" json:"originURL,omitempty"`                        // URL to the origin
+	RewriteRules         []*RewriteRule         `protobuf:"bytes,4,rep,name=rewriteRules,proto3" json:"rewriteRules,omitempty"`                  // List of rewrite rules
+	StaleWhileRevalidate int32                  `protobuf:"varint,5,opt,name=staleWhileRevalidate,proto3" json:"staleWhileRevalidate,omitempty"` // Default stale-while-revalidate in seconds
+	StaleIfError         int32                  `protobuf:"varint,6,opt,name=staleIfError,proto3" json:"staleIfError,omitempty"`                 // Default stale-if-error in seconds
+	CacheKey             *CacheKey              `protobuf:"bytes,7,opt,name=cacheKey,proto3" json:"cacheKey,omitempty"`                          // Components of the cache key
+	Tls                  *TLSConfig             `protobuf:"bytes,8,opt,name=tls,proto3" json:"tls,omitempty"`                                    // HTTPS certificate and redirect
+	Compression          *Compression           `protobuf:"bytes,9,opt,name=compression,proto3" json:"compression,omitempty"`                    // gzip and brotli compression
+	TokenAuth            *TokenAuth             `protobuf:"bytes,10,opt,name=tokenAuth,proto3" json:"tokenAuth,omitempty"`                       // Signed URLs
+	Acl                  *AccessControl         `protobuf:"bytes,11,opt,name=acl,proto3" json:"acl,omitempty"`                                   // Client IP and country access control
+	RateLimit            *RateLimit             `protobuf:"bytes,12,opt,name=rateLimit,proto3" json:"rateLimit,omitempty"`                       // Rate and concurrency limits
+	UrlRewrites          []*UrlRewriteRule      `protobuf:"bytes,13,rep,name=urlRewrites,proto3" json:"urlRewrites,omitempty"`                   // URL rewrite rules in their order
+	Redirects            []*RedirectRule        `protobuf:"bytes,14,rep,name=redirects,proto3" json:"redirects,omitempty"`                       // Redirect rules in their order
+	Cors                 *Cors                  `protobuf:"bytes,15,opt,name=cors,proto3" json:"cors,omitempty"`                                 // Cross origin resource sharing
+	ErrorPages           []*ErrorPage           `protobuf:"bytes,16,rep,name=errorPages,proto3" json:"errorPages,omitempty"`                     // Error pages per status code or class
+	OriginGroup          *OriginGroup           `protobuf:"bytes,17,opt,name=originGroup,proto3" json:"originGroup,omitempty"`                   // Origins with health checks and failover
+	Retry                *RetryPolicy           `protobuf:"bytes,18,opt,name=retry,proto3" json:"retry,omitempty"`                               // Retries of the upstream fetches
+	CircuitBreaker       *CircuitBreaker        `protobuf:"bytes,19,opt,name=circuitBreaker,proto3" json:"circuitBreaker,omitempty"`             // Circuit breaker of the upstream hosts
+	Upstream             *Upstream              `protobuf:"bytes,20,opt,name=upstream,proto3" json:"upstream,omitempty"`                         // Timeouts and connections of the upstream fetches
+	OriginTLS            *OriginTLS             `protobuf:"bytes,21,opt,name=originTLS,proto3" json:"originTLS,omitempty"`                       // Trust and client certificate towards the HTTPS origins
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *DeliveryService) Reset() {
+	*x = DeliveryService{}
+	mi := &file_mgmtApi_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeliveryService) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeliveryService) ProtoMessage() {}
+
+func (x *DeliveryService) ProtoReflect() protoreflect.Message {
+	mi := &file_mgmtApi_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeliveryService.ProtoReflect.Descriptor instead.
+func (*DeliveryService) Descriptor() ([]byte, []int) {
+	return file_mgmtApi_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *DeliveryService) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *DeliveryService) GetClientURL() string {
+	if x != nil {
+		return x.ClientURL
+	}
+	return ""
+}
+
+func (x *DeliveryService) GetOriginURL() string {
+	if x != nil {
+		return x.OriginURL
+	}
+	return ""
+}
+
+func (x *DeliveryService) GetRewriteRules() []*RewriteRule {
+	if x != nil {
+		return x.RewriteRules
+	}
+	return nil
+}
+
+func (x *DeliveryService) GetStaleWhileRevalidate() int32 {
+	if x != nil {
+		return x.StaleWhileRevalidate
+	}
+	return 0
+}
+
+func (x *DeliveryService) GetStaleIfError() int32 {
+	if x != nil {
+		return x.StaleIfError
+	}
+	return 0
+}
+
+func (x *DeliveryService) GetCacheKey() *CacheKey {
+	if x != nil {
+		return x.CacheKey
+	}
+	return nil
+}
+
+func (x *DeliveryService) GetTls() *TLSConfig {
+	if x != nil {
+		return x.Tls
+	}
+	return nil
+}
+
+func (x *DeliveryService) GetCompression() *Compression {
+	if x != nil {
+		return x.Compression
+	}
+	return nil
+}
+
+func (x *DeliveryService) GetTokenAuth() *TokenAuth {
+	if x != nil {
+		return x.TokenAuth
+	}
+	return nil
+}
+
+func (x *DeliveryService) GetAcl() *AccessControl {
+	if x != nil {
+		return x.Acl
+	}
+	return nil
+}
+
+func (x *DeliveryService) GetRateLimit() *RateLimit {
+	if x != nil {
+		return x.RateLimit
+	}
+	return nil
+}
+
+func (x *DeliveryService) GetUrlRewrites() []*UrlRewriteRule {
+	if x != nil {
+		return x.UrlRewrites
+	}
+	return nil
+}
+
+func (x *DeliveryService) GetRedirects() []*RedirectRule {
+	if x != nil {
+		return x.Redirects
+	}
+	return nil
+}
+
+func (x *DeliveryService) GetCors() *Cors {
+	if x != nil {
+		return x.Cors
+	}
+	return nil
+}
+
+func (x *DeliveryService) GetErrorPages() []*ErrorPage {
+	if x != nil {
+		return x.ErrorPages
+	}
+	return nil
+}
+
+func (x *DeliveryService) GetOriginGroup() *OriginGroup {
+	if x != nil {
+		return x.OriginGroup
+	}
+	return nil
+}
+
+func (x *DeliveryService) GetRetry() *RetryPolicy {
+	if x != nil {
+		return x.Retry
+	}
+	return nil
+}
+
+func (x *DeliveryService) GetCircuitBreaker() *CircuitBreaker {
+	if x != nil {
+		return x.CircuitBreaker
+	}
+	return nil
+}
+
+func (x *DeliveryService) GetUpstream() *Upstream {
+	if x != nil {
+		return x.Upstream
+	}
+	return nil
+}
+
+func (x *DeliveryService) GetOriginTLS() *OriginTLS {
+	if x != nil {
+		return x.OriginTLS
+	}
+	return nil
+}
+
+// OriginTLS represents the TLS settings towards the HTTPS origins of a delivery service
+type OriginTLS struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	CaBundle          string                 `protobuf:"bytes,1,opt,name=caBundle,proto3" json:"caBundle,omitempty"`                   // PEM certificates trusted for the origins
+	ServerName        string                 `protobuf:"bytes,2,opt,name=serverName,proto3" json:"serverName,omitempty"`               // SNI and name verified in the certificate of the origin
+	MinVersion        string                 `protobuf:"bytes,3,opt,name=minVersion,proto3" json:"minVersion,omitempty"`               // 1.0, 1.1, 1.2 or 1.3
+	ClientCertificate string                 `protobuf:"bytes,4,opt,name=clientCertificate,proto3" json:"clientCertificate,omitempty"` // PEM certificate chain presented to the origins
+	ClientKey         string                 `protobuf:"bytes,5,opt,name=clientKey,proto3" json:"clientKey,omitempty"`                 // PEM private key of the client certificate
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *OriginTLS) Reset() {
+	*x = OriginTLS{}
+	mi := &file_mgmtApi_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OriginTLS) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OriginTLS) ProtoMessage() {}
+
+func (x *OriginTLS) ProtoReflect() protoreflect.Message {
+	mi := &file_mgmtApi_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OriginTLS.ProtoReflect.Descriptor instead.
+func (*OriginTLS) Descriptor() ([]byte, []int) {
+	return file_mgmtApi_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *OriginTLS) GetCaBundle() string {
+	if x != nil {
+		return x.CaBundle
+	}
+	return ""
+}
+
+func (x *OriginTLS) GetServerName() string {
+	if x != nil {
+		return x.ServerName
+	}
+	return ""
+}
+
+func (x *OriginTLS) GetMinVersion() string {
+	if x != nil {
+		return x.MinVersion
+	}
+	return ""
+}
+
+func (x *OriginTLS) GetClientCertificate() string {
+	if x != nil {
+		return x.ClientCertificate
+	}
+	return ""
+}
+
+func (x *OriginTLS) GetClientKey() string {
+	if x != nil {
+		return x.ClientKey
+	}
+	return ""
+}
+
+// Upstream represents the timeouts in milliseconds and the connections of the upstream fetches
+type Upstream struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	ConnectTimeout      int32                  `protobuf:"varint,1,opt,name=connectTimeout,proto3" json:"connectTimeout,omitempty"`           // To connect to the upstream
+	TlsHandshakeTimeout int32                  `protobuf:"varint,2,opt,name=tlsHandshakeTimeout,proto3" json:"tlsHandshakeTimeout,omitempty"` // For the TLS handshake with an HTTPS origin
+	TtfbTimeout         int32                  `protobuf:"varint,3,opt,name=ttfbTimeout,proto3" json:"ttfbTimeout,omitempty"`                 // From the request sent to the response header
+	IdleReadTimeout     int32                  `protobuf:"varint,4,opt,name=idleReadTimeout,proto3" json:"idleReadTimeout,omitempty"`         // Between two reads of the body
+	TotalTimeout        int32                  `protobuf:"varint,5,opt,name=totalTimeout,proto3" json:"totalTimeout,omitempty"`               // For the whole fetch including the body
+	MaxConnsPerHost     int32                  `protobuf:"varint,6,opt,name=maxConnsPerHost,proto3" json:"maxConnsPerHost,omitempty"`         // Connections to one upstream host
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *Upstream) Reset() {
+	*x = Upstream{}
+	mi := &file_mgmtApi_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Upstream) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Upstream) ProtoMessage() {}
+
+func (x *Upstream) ProtoReflect() protoreflect.Message {
+	mi := &file_mgmtApi_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Upstream.ProtoReflect.Descriptor instead.
+func (*Upstream) Descriptor() ([]byte, []int) {
+	return file_mgmtApi_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *Upstream) GetConnectTimeout() int32 {
+	if x != nil {
+		return x.ConnectTimeout
+	}
+	return 0
+}
+
+func (x *Upstream) GetTlsHandshakeTimeout() int32 {
+	if x != nil {
+		return x.TlsHandshakeTimeout
+	}
+	return 0
+}
+
+func (x *Upstream) GetTtfbTimeout() int32 {
+	if x != nil {
+		return x.TtfbTimeout
+	}
+	return 0
+}
+
+func (x *Upstream) GetIdleReadTimeout() int32 {
+	if x != nil {
+		return x.IdleReadTimeout
+	}
+	return 0
+}
+
+func (x *Upstream) GetTotalTimeout() int32 {
+	if x != nil {
+		return x.TotalTimeout
+	}
+	return 0
+}
+
+func (x *Upstream) GetMaxConnsPerHost() int32 {
+	if x != nil {
+		return x.MaxConnsPerHost
+	}
+	return 0
+}
+
+// RetryPolicy represents the retries of the idempotent upstream fetches of a delivery service
+type RetryPolicy struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MaxAttempts   int32                  `protobuf:"varint,1,opt,name=maxAttempts,proto3" json:"maxAttempts,omitempty"`  // Attempts of a fetch including the first one
+	Statuses      []int32                `protobuf:"varint,2,rep,packed,name=statuses,proto3" json:"statuses,omitempty"` // 5xx statuses retried
+	Errors        []string               `protobuf:"bytes,3,rep,name=errors,proto3" json:"errors,omitempty"`             // Errors retried: connect, timeout, reset
+	Backoff       int32                  `protobuf:"varint,4,opt,name=backoff,proto3" json:"backoff,omitempty"`          // Milliseconds before the first retry, doubled for each retry
+	MaxBackoff    int32                  `protobuf:"varint,5,opt,name=maxBackoff,proto3" json:"maxBackoff,omitempty"`    // Milliseconds between the retries at most
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RetryPolicy) Reset() {
+	*x = RetryPolicy{}
+	mi := &file_mgmtApi_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RetryPolicy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetryPolicy) ProtoMessage() {}
+
+func (x *RetryPolicy) ProtoReflect() protoreflect.Message {
+	mi := &file_mgmtApi_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetryPolicy.ProtoReflect.Descriptor instead.
+func (*RetryPolicy) Descriptor() ([]byte, []int) {
+	return file_mgmtApi_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *RetryPolicy) GetMaxAttempts() int32 {
+	if x != nil {
+		return x.MaxAttempts
+	}
+	return 0
+}
+
+func (x *RetryPolicy) GetStatuses() []int32 {
+	if x != nil {
+		return x.Statuses
+	}
+	return nil
+}
+
+func (x *RetryPolicy) GetErrors() []string {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+func (x *RetryPolicy) GetBackoff() int32 {
+	if x != nil {
+		return x.Backoff
+	}
+	return 0
+}
+
+func (x *RetryPolicy) GetMaxBackoff() int32 {
+	if x != nil {
+		return x.MaxBackoff
+	}
+	return 0
+}
+
+// CircuitBreaker represents the circuit breaker of the upstream hosts of a delivery service
+type CircuitBreaker struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Enabled          bool                   `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	FailureThreshold int32                  `protobuf:"varint,2,opt,name=failureThreshold,proto3" json:"failureThreshold,omitempty"` // Failed fetches in a row opening the circuit
+	Cooldown         int32                  `protobuf:"varint,3,opt,name=cooldown,proto3" json:"cooldown,omitempty"`                 // Seconds open before one trial fetch is let through
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *CircuitBreaker) Reset() {
+	*x = CircuitBreaker{}
+	mi := &file_mgmtApi_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CircuitBreaker) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CircuitBreaker) ProtoMessage() {}
+
+func (x *CircuitBreaker) ProtoReflect() protoreflect.Message {
+	mi := &file_mgmtApi_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CircuitBreaker.ProtoReflect.Descriptor instead.
+func (*CircuitBreaker) Descriptor() ([]byte, []int) {
+	return file_mgmtApi_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *CircuitBreaker) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *CircuitBreaker) GetFailureThreshold() int32 {
+	if x != nil {
+		return x.FailureThreshold
+	}
+	return 0
+}
+
+func (x *CircuitBreaker) GetCooldown() int32 {
+	if x != nil {
+		return x.Cooldown
+	}
+	return 0
+}
+
+// OriginGroup represents the origins of a delivery service probed by the nodes without parent
+type OriginGroup struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Origins          []*Origin              `protobuf:"bytes,1,rep,name=origins,proto3" json:"origins,omitempty"`                           // Primary and backup origins
+	HealthPath       string                 `protobuf:"bytes,2,opt,name=healthPath,proto3" json:"healthPath,omitempty"`                     // Path probed with GET, no probes if empty
+	HealthInterval   int32                  `protobuf:"varint,3,opt,name=healthInterval,proto3" json:"healthInterval,omitempty"`            // Seconds between the probes of an origin
+	HealthTimeout    int32                  `protobuf:"varint,4,opt,name=healthTimeout,proto3" json:"healthTimeout,omitempty"`              // Seconds a probe may take
+	UnhealthyAfter   int32                  `protobuf:"varint,5,opt,name=unhealthyAfter,proto3" json:"unhealthyAfter,omitempty"`            // Failed probes in a row marking an origin down
+	HealthyAfter     int32                  `protobuf:"varint,6,opt,name=healthyAfter,proto3" json:"healthyAfter,omitempty"`                // Passed probes in a row marking an origin up again
+	FailoverStatuses []int32                `protobuf:"varint,7,rep,packed,name=failoverStatuses,proto3" json:"failoverStatuses,omitempty"` // 5xx statuses fetching from the next origin
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *OriginGroup) Reset() {
+	*x = OriginGroup{}
+	mi := &file_mgmtApi_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OriginGroup) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OriginGroup) ProtoMessage() {}
+
+func (x *OriginGroup) ProtoReflect() protoreflect.Message {
+	mi := &file_mgmtApi_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OriginGroup.ProtoReflect.Descriptor instead.
+func (*OriginGroup) Descriptor() ([]byte, []int) {
+	return file_mgmtApi_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *OriginGroup) GetOrigins() []*Origin {
+	if x != nil {
+		return x.Origins
+	}
+	return nil
+}
+
+func (x *OriginGroup) GetHealthPath() string {
+	if x != nil {
+		return x.HealthPath
+	}
+	return ""
+}
+
+func (x *OriginGroup) GetHealthInterval() int32 {
+	if x != nil {
+		return x.HealthInterval
+	}
+	return 0
+}
+
+func (x *OriginGroup) GetHealthTimeout() int32 {
+	if x != nil {
+		return x.HealthTimeout
+	}
+	return 0
+}
+
+func (x *OriginGroup) GetUnhealthyAfter() int32 {
+	if x != nil {
+		return x.UnhealthyAfter
+	}
+	return 0
+}
+
+func (x *OriginGroup) GetHealthyAfter() int32 {
+	if x != nil {
+		return x.HealthyAfter
+	}
+	return 0
+}
+
+func (x *OriginGroup) GetFailoverStatuses() []int32 {
+	if x != nil {
+		return x.FailoverStatuses
+	}
+	return nil
+}
+
+// Origin represents a member of an origin group
+type Origin struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`        // Scheme and host of the origin
+	Backup        bool                   `protobuf:"varint,2,opt,name=backup,proto3" json:"backup,omitempty"` // Used only when no primary origin is healthy
+	Weight        int32                  `protobuf:"varint,3,opt,name=weight,proto3" json:"weight,omitempty"` // Share of the requests among the healthy origins of its tier
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Origin) Reset() {
+	*x = Origin{}
+	mi := &file_mgmtApi_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Origin) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Origin) ProtoMessage() {}
+
+func (x *Origin) ProtoReflect() protoreflect.Message {
+	mi := &file_mgmtApi_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Origin.ProtoReflect.Descriptor instead.
+func (*Origin) Descriptor() ([]byte, []int) {
+	return file_mgmtApi_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *Origin) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Origin) GetBackup() bool {
+	if x != nil {
+		return x.Backup
+	}
+	return false
+}
+
+func (x *Origin) GetWeight() int32 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+// ErrorPage represents the page replacing the body of the error responses
+type ErrorPage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`           // Status code e.g. 404, or class e.g. 5xx
+	Url           string                 `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`                 // Path of the page on the origin
+	Content       string                 `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`         // Inline page
+	ContentType   string                 `protobuf:"bytes,4,opt,name=contentType,proto3" json:"contentType,omitempty"` // Content type of the inline page
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ErrorPage) Reset() {
+	*x = ErrorPage{}
+	mi := &file_mgmtApi_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ErrorPage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ErrorPage) ProtoMessage() {}
+
+func (x *ErrorPage) ProtoReflect() protoreflect.Message {
+	mi := &file_mgmtApi_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ErrorPage.ProtoReflect.Descriptor instead.
+func (*ErrorPage) Descriptor() ([]byte, []int) {
+	return file_mgmtApi_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ErrorPage) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ErrorPage) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *ErrorPage) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *ErrorPage) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+// Cors represents the CORS policy of a delivery service answered by the edge
+type Cors struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Enabled          bool                   `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	AllowOrigins     []string               `protobuf:"bytes,2,rep,name=allowOrigins,proto3" json:"allowOrigins,omitempty"`          // Origins allowed, * as wildcard
+	AllowMethods     []string               `protobuf:"bytes,3,rep,name=allowMethods,proto3" json:"allowMethods,omitempty"`          // Methods allowed, GET and HEAD if empty
+	AllowHeaders     []string               `protobuf:"bytes,4,rep,name=allowHeaders,proto3" json:"allowHeaders,omitempty"`          // Request headers allowed, * for any
+	ExposeHeaders    []string               `protobuf:"bytes,5,rep,name=exposeHeaders,proto3" json:"exposeHeaders,omitempty"`        // Response headers readable by the scripts
+	AllowCredentials bool                   `protobuf:"varint,6,opt,name=allowCredentials,proto3" json:"allowCredentials,omitempty"` // Cookies and authorization
+	MaxAge           int32                  `protobuf:"varint,7,opt,name=maxAge,proto3" json:"maxAge,omitempty"`                     // Seconds the browser may cache the preflight
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Cors) Reset() {
+	*x = Cors{}
+	mi := &file_mgmtApi_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Cors) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Cors) ProtoMessage() {}
+
+func (x *Cors) ProtoReflect() protoreflect.Message {
+	mi := &file_mgmtApi_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Cors.ProtoReflect.Descriptor instead.
+func (*Cors) Descriptor() ([]byte, []int) {
+	return file_mgmtApi_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *Cors) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *Cors) GetAllowOrigins() []string {
+	if x != nil {
+		return x.AllowOrigins
+	}
+	return nil
+}
+
+func (x *Cors) GetAllowMethods() []string {
+	if x != nil {
+		return x.AllowMethods
+	}
+	return nil
+}
+
+func (x *Cors) GetAllowHeaders() []string {
+	if x != nil {
+		return x.AllowHeaders
+	}
+	return nil
+}
+
+func (x *Cors) GetExposeHeaders() []string {
+	if x != nil {
+		return x.ExposeHeaders
+	}
+	return nil
+}
+
+func (x *Cors) GetAllowCredentials() bool {
+	if x != nil {
+		return x.AllowCredentials
+	}
+	return false
+}
+
+func (x *Cors) GetMaxAge() int32 {
+	if x != nil {
+		return x.MaxAge
+	}
+	return 0
+}
+
+// RedirectRule represents a redirect answered by the edge
+type RedirectRule struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Source        string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`                // Regex matched against the path
+	Target        string                 `protobuf:"bytes,2,opt,name=target,proto3" json:"target,omitempty"`                // Path or absolute URL of the Location, $1 for the groups
+	StatusCode    int32                  `protobuf:"varint,3,opt,name=statusCode,proto3" json:"statusCode,omitempty"`       // 301, 302, 307 or 308
+	PreserveQuery bool                   `protobuf:"varint,4,opt,name=preserveQuery,proto3" json:"preserveQuery,omitempty"` // Append the query of the request to the Location
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RedirectRule) Reset() {
+	*x = RedirectRule{}
+	mi := &file_mgmtApi_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RedirectRule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RedirectRule) ProtoMessage() {}
+
+func (x *RedirectRule) ProtoReflect() protoreflect.Message {
+	mi := &file_mgmtApi_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RedirectRule.ProtoReflect.Descriptor instead.
+func (*RedirectRule) Descriptor() ([]byte, []int) {
+	return file_mgmtApi_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *RedirectRule) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *RedirectRule) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *RedirectRule) GetStatusCode() int32 {
+	if x != nil {
+		return x.StatusCode
+	}
+	return 0
+}
+
+func (x *RedirectRule) GetPreserveQuery() bool {
+	if x != nil {
+		return x.PreserveQuery
+	}
+	return false
+}
+
+// UrlRewriteRule represents a regex rewrite of the path and query of the request
+type UrlRewriteRule struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Pattern       string                 `protobuf:"bytes,1,opt,name=pattern,proto3" json:"pattern,omitempty"`            // Regex matched against the path and query
+	Replacement   string                 `protobuf:"bytes,2,opt,name=replacement,proto3" json:"replacement,omitempty"`    // New path and query, $1 for the groups
+	UpstreamOnly  bool                   `protobuf:"varint,3,opt,name=upstreamOnly,proto3" json:"upstreamOnly,omitempty"` // Rewrite only the request to the origin
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UrlRewriteRule) Reset() {
+	*x = UrlRewriteRule{}
+	mi := &file_mgmtApi_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UrlRewriteRule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UrlRewriteRule) ProtoMessage() {}
+
+func (x *UrlRewriteRule) ProtoReflect() protoreflect.Message {
+	mi := &file_mgmtApi_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UrlRewriteRule.ProtoReflect.Descriptor instead.
+func (*UrlRewriteRule) Descriptor() ([]byte, []int) {
+	return file_mgmtApi_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *UrlRewriteRule) GetPattern() string {
+	if x != nil {
+		return x.Pattern
+	}
+	return ""
+}
+
+func (x *UrlRewriteRule) GetReplacement() string {
+	if x != nil {
+		return x.Replacement
+	}
+	return ""
+}
+
+func (x *UrlRewriteRule) GetUpstreamOnly() bool {
+	if x != nil {
+		return x.UpstreamOnly
+	}
+	return false
+}
+
+// RateLimit represents the admission control of a delivery service, 0 disables a limit
+type RateLimit struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ClientRate    float64                `protobuf:"fixed64,1,opt,name=clientRate,proto3" json:"clientRate,omitempty"`      // Requests per second of one client IP
+	ClientBurst   int32                  `protobuf:"varint,2,opt,name=clientBurst,proto3" json:"clientBurst,omitempty"`     // Requests a client IP may send at once
+	DsRate        float64                `protobuf:"fixed64,3,opt,name=dsRate,proto3" json:"dsRate,omitempty"`              // Requests per second of all clients
+	DsBurst       int32                  `protobuf:"varint,4,opt,name=dsBurst,proto3" json:"dsBurst,omitempty"`             // Requests all clients may send at once
+	MaxConcurrent int32                  `protobuf:"varint,5,opt,name=maxConcurrent,proto3" json:"maxConcurrent,omitempty"` // Requests in progress
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RateLimit) Reset() {
+	*x = RateLimit{}
+	mi := &file_mgmtApi_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RateLimit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RateLimit) ProtoMessage() {}
+
+func (x *RateLimit) ProtoReflect() protoreflect.Message {
+	mi := &file_mgmtApi_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RateLimit.ProtoReflect.Descriptor instead.
+func (*RateLimit) Descriptor() ([]byte, []int) {
+	return file_mgmtApi_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *RateLimit) GetClientRate() float64 {
+	if x != nil {
+		return x.ClientRate
+	}
+	return 0
+}
+
+func (x *RateLimit) GetClientBurst() int32 {
+	if x != nil {
+		return x.ClientBurst
+	}
+	return 0
+}
+
+func (x *RateLimit) GetDsRate() float64 {
+	if x != nil {
+		return x.DsRate
+	}
+	return 0
+}
+
+func (x *RateLimit) GetDsBurst() int32 {
+	if x != nil {
+		return x.DsBurst
+	}
+	return 0
+}
+
+func (x *RateLimit) GetMaxConcurrent() int32 {
+	if x != nil {
+		return x.MaxConcurrent
+	}
+	return 0
+}
+
+// AccessControl represents the client IP and country access control lists of a delivery service
+type AccessControl struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	AllowCidrs     []string               `protobuf:"bytes,1,rep,name=allowCidrs,proto3" json:"allowCidrs,omitempty"`         // Client networks allowed
+	DenyCidrs      []string               `protobuf:"bytes,2,rep,name=denyCidrs,proto3" json:"denyCidrs,omitempty"`           // Client networks denied
+	AllowCountries []string               `protobuf:"bytes,3,rep,name=allowCountries,proto3" json:"allowCountries,omitempty"` // Client countries allowed (ISO 3166-1 alpha-2)
+	DenyCountries  []string               `protobuf:"bytes,4,rep,name=denyCountries,proto3" json:"denyCountries,omitempty"`   // Client countries denied
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *AccessControl) Reset() {
+	*x = AccessControl{}
+	mi := &file_mgmtApi_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AccessControl) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AccessControl) ProtoMessage() {}
+
+func (x *AccessControl) ProtoReflect() protoreflect.Message {
+	mi := &file_mgmtApi_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AccessControl.ProtoReflect.Descriptor instead.
+func (*AccessControl) Descriptor() ([]byte, []int) {
+	return file_mgmtApi_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *AccessControl) GetAllowCidrs() []string {
+	if x != nil {
+		return x.AllowCidrs
+	}
+	return nil
+}
+
+func (x *AccessControl) GetDenyCidrs() []string {
+	if x != nil {
+		return x.DenyCidrs
+	}
+	return nil
+}
+
+func (x *AccessControl) GetAllowCountries() []string {
+	if x != nil {
+		return x.AllowCountries
+	}
+	return nil
+}
+
+func (x *AccessControl) GetDenyCountries() []string {
+	if x != nil {
+		return x.DenyCountries
+	}
+	return nil
+}
+
+// TokenAuth represents the signed URL (token) authentication of a delivery service
+type TokenAuth struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Enabled       bool                   `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`      // Reject requests without a valid token
+	QueryParam    string                 `protobuf:"bytes,2,opt,name=queryParam,proto3" json:"queryParam,omitempty"` // Query param carrying the token
+	CookieName    string                 `protobuf:"bytes,3,opt,name=cookieName,proto3" json:"cookieName,omitempty"` // Cookie carrying the token if not in the query
+	RequireIp     bool                   `protobuf:"varint,4,opt,name=requireIp,proto3" json:"requireIp,omitempty"`  // Tokens must be bound to the client IP
+	Keys          []*TokenKey            `protobuf:"bytes,5,rep,name=keys,proto3" json:"keys,omitempty"`             // Active signing keys
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TokenAuth) Reset() {
+	*x = TokenAuth{}
+	mi := &file_mgmtApi_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TokenAuth) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TokenAuth) ProtoMessage() {}
+
+func (x *TokenAuth) ProtoReflect() protoreflect.Message {
+	mi := &file_mgmtApi_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TokenAuth.ProtoReflect.Descriptor instead.
+func (*TokenAuth) Descriptor() ([]byte, []int) {
+	return file_mgmtApi_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *TokenAuth) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *TokenAuth) GetQueryParam() string {
+	if x != nil {
+		return x.QueryParam
+	}
+	return ""
+}
+
+func (x *TokenAuth) GetCookieName() string {
+	if x != nil {
+		return x.CookieName
+	}
+	return ""
+}
+
+func (x *TokenAuth) GetRequireIp() bool {
+	if x != nil {
+		return x.RequireIp
+	}
+	return false
+}
+
+func (x *TokenAuth) GetKeys() []*TokenKey {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+// TokenKey represents a key signing the tokens
+type TokenKey struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`         // Key id named by the token
+	Secret        string                 `protobuf:"bytes,2,opt,name=secret,proto3" json:"secret,omitempty"` // HMAC-SHA256 secret
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Config) Reset() {
-	*x = Config{}
-	mi := &file_mgmtApi_proto_msgTypes[8]
+func (x *TokenKey) Reset() {
+	*x = TokenKey{}
+	mi := &file_mgmtApi_proto_msgTypes[26]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Config) String() string {
+func (x *TokenKey) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Config) ProtoMessage() {}
+func (*TokenKey) ProtoMessage() {}
 
-func (x *Config) ProtoReflect() protoreflect.Message {
-	mi := &file_mgmtApi_proto_msgTypes[8]
+func (x *TokenKey) ProtoReflect() protoreflect.Message {
+	mi := &file_mgmtApi_proto_msgTypes[26]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -454,51 +1856,114 @@ func (x *Config) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Config.ProtoReflect.Descriptor instead.
-func (*Config) Descriptor() ([]byte, []int) {
-	return file_mgmtApi_proto_rawDescGZIP(), []int{8}
+// Deprecated: Use TokenKey.ProtoReflect.Descriptor instead.
+func (*TokenKey) Descriptor() ([]byte, []int) {
+	return file_mgmtApi_proto_rawDescGZIP(), []int{26}
 }
 
-func (x *Config) GetServiceList() []*DeliveryService {
+func (x *TokenKey) GetId() string {
 	if x != nil {
-		return x.ServiceList
+		return x.Id
 	}
-	return nil
+	return ""
 }
 
-func (x *Config) GetNode() *CacheNode {
+func (x *TokenKey) GetSecret() string {
 	if x != nil {
-		return x.Node
+		return x.Secret
+	}
+	return ""
+}
+
+// Compression represents the on the fly compression of a delivery service
+type Compression struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Enabled       bool                   `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`    // Compress for clients accepting br or gzip
+	MimeTypes     []string               `protobuf:"bytes,2,rep,name=mimeTypes,proto3" json:"mimeTypes,omitempty"` // Compressible content types, "text/*" matches by prefix
+	MinSize       int32                  `protobuf:"varint,3,opt,name=minSize,proto3" json:"minSize,omitempty"`    // Minimum content length in bytes
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Compression) Reset() {
+	*x = Compression{}
+	mi := &file_mgmtApi_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Compression) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Compression) ProtoMessage() {}
+
+func (x *Compression) ProtoReflect() protoreflect.Message {
+	mi := &file_mgmtApi_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Compression.ProtoReflect.Descriptor instead.
+func (*Compression) Descriptor() ([]byte, []int) {
+	return file_mgmtApi_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *Compression) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *Compression) GetMimeTypes() []string {
+	if x != nil {
+		return x.MimeTypes
 	}
 	return nil
 }
 
-// DeliveryService represents a single delivery service
-type DeliveryService struct {
+func (x *Compression) GetMinSize() int32 {
+	if x != nil {
+		return x.MinSize
+	}
+	return 0
+}
+
+// CacheKey represents the components of the cache key besides host and path
+type CacheKey struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`                 // Name of the delivery service
-	ClientURL     string                 `protobuf:"bytes,2,opt,name=clientURL,proto3" json:"clientURL,omitempty"`       // URL from the client
-	OriginURL     string                 `protobuf:"bytes,3,opt,name=originURL,proto3" json:"originURL,omitempty"`       // URL to the origin
-	RewriteRules  []*RewriteRule         `protobuf:"bytes,4,rep,name=rewriteRules,proto3" json:"rewriteRules,omitempty"` // List of rewrite rules
+	QueryMode     int32                  `protobuf:"varint,1,opt,name=queryMode,proto3" json:"queryMode,omitempty"`    // Query params in the key (ignore all, include all, allow list, deny list)
+	QueryParams   []string               `protobuf:"bytes,2,rep,name=queryParams,proto3" json:"queryParams,omitempty"` // Allow/deny list, "utm_*" matches by prefix
+	SortQuery     bool                   `protobuf:"varint,3,opt,name=sortQuery,proto3" json:"sortQuery,omitempty"`    // Sort the query params
+	Headers       []string               `protobuf:"bytes,4,rep,name=headers,proto3" json:"headers,omitempty"`         // Request headers added to the key
+	Cookies       []string               `protobuf:"bytes,5,rep,name=cookies,proto3" json:"cookies,omitempty"`         // Cookies added to the key
+	IgnoreCase    bool                   `protobuf:"varint,6,opt,name=ignoreCase,proto3" json:"ignoreCase,omitempty"`  // Lower case path and query
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeliveryService) Reset() {
-	*x = DeliveryService{}
-	mi := &file_mgmtApi_proto_msgTypes[9]
+func (x *CacheKey) Reset() {
+	*x = CacheKey{}
+	mi := &file_mgmtApi_proto_msgTypes[28]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeliveryService) String() string {
+func (x *CacheKey) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeliveryService) ProtoMessage() {}
+func (*CacheKey) ProtoMessage() {}
 
-func (x *DeliveryService) ProtoReflect() protoreflect.Message {
-	mi := &file_mgmtApi_proto_msgTypes[9]
+func (x *CacheKey) ProtoReflect() protoreflect.Message {
+	mi := &file_mgmtApi_proto_msgTypes[28]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -509,52 +1974,132 @@ func (x *DeliveryService) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeliveryService.ProtoReflect.Descriptor instead.
-func (*DeliveryService) Descriptor() ([]byte, []int) {
-	return file_mgmtApi_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use CacheKey.ProtoReflect.Descriptor instead.
+func (*CacheKey) Descriptor() ([]byte, []int) {
+	return file_mgmtApi_proto_rawDescGZIP(), []int{28}
 }
 
-func (x *DeliveryService) GetName() string {
+func (x *CacheKey) GetQueryMode() int32 {
 	if x != nil {
-		return x.Name
+		return x.QueryMode
 	}
-	return ""
+	return 0
 }
 
-func (x *DeliveryService) GetClientURL() string {
+func (x *CacheKey) GetQueryParams() []string {
 	if x != nil {
-		return x.ClientURL
+		return x.QueryParams
+	}
+	return nil
+}
+
+func (x *CacheKey) GetSortQuery() bool {
+	if x != nil {
+		return x.SortQuery
+	}
+	return false
+}
+
+func (x *CacheKey) GetHeaders() []string {
+	if x != nil {
+		return x.Headers
+	}
+	return nil
+}
+
+func (x *CacheKey) GetCookies() []string {
+	if x != nil {
+		return x.Cookies
+	}
+	return nil
+}
+
+func (x *CacheKey) GetIgnoreCase() bool {
+	if x != nil {
+		return x.IgnoreCase
+	}
+	return false
+}
+
+// TLSConfig represents the TLS termination of a delivery service
+type TLSConfig struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Certificate     string                 `protobuf:"bytes,1,opt,name=certificate,proto3" json:"certificate,omitempty"`          // PEM certificate chain
+	PrivateKey      string                 `protobuf:"bytes,2,opt,name=privateKey,proto3" json:"privateKey,omitempty"`            // PEM private key of the certificate
+	RedirectToHttps bool                   `protobuf:"varint,3,opt,name=redirectToHttps,proto3" json:"redirectToHttps,omitempty"` // Redirect HTTP clients to HTTPS
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *TLSConfig) Reset() {
+	*x = TLSConfig{}
+	mi := &file_mgmtApi_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TLSConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TLSConfig) ProtoMessage() {}
+
+func (x *TLSConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_mgmtApi_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TLSConfig.ProtoReflect.Descriptor instead.
+func (*TLSConfig) Descriptor() ([]byte, []int) {
+	return file_mgmtApi_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *TLSConfig) GetCertificate() string {
+	if x != nil {
+		return x.Certificate
 	}
 	return ""
 }
 
-func (x *DeliveryService) GetOriginURL() string {
+func (x *TLSConfig) GetPrivateKey() string {
 	if x != nil {
-		return x.OriginURL
+		return x.PrivateKey
 	}
 	return ""
 }
 
-func (x *DeliveryService) GetRewriteRules() []*RewriteRule {
+func (x *TLSConfig) GetRedirectToHttps() bool {
 	if x != nil {
-		return x.RewriteRules
+		return x.RedirectToHttps
 	}
-	return nil
+	return false
 }
 
 // RewriteRule represents a rule for rewriting HTTP headers
 type RewriteRule struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	HeaderName    string                 `protobuf:"bytes,1,opt,name=headerName,proto3" json:"headerName,omitempty"` // Name of the HTTP header
-	Operation     int32                  `protobuf:"varint,2,opt,name=operation,proto3" json:"operation,omitempty"`  // Operation to perform (e.g., add, overwrite, delete)
-	Value         string                 `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`           // Value for add/overwrite operations
+	HeaderName    string                 `protobuf:"bytes,1,opt,name=headerName,proto3" json:"headerName,omitempty"`       // Name of the HTTP header
+	Operation     int32                  `protobuf:"varint,2,opt,name=operation,proto3" json:"operation,omitempty"`        // Operation to perform (e.g., add, overwrite, delete, append, replace)
+	Value         string                 `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`                 // Value for add/overwrite/append operations, replacement for replace
+	Phase         int32                  `protobuf:"varint,4,opt,name=phase,proto3" json:"phase,omitempty"`                // Where the rule applies (response, request, client)
+	Pattern       string                 `protobuf:"bytes,5,opt,name=pattern,proto3" json:"pattern,omitempty"`             // Regex matched by replace
+	IfHeader      string                 `protobuf:"bytes,6,opt,name=ifHeader,proto3" json:"ifHeader,omitempty"`           // Request header which must be present
+	IfHeaderMatch string                 `protobuf:"bytes,7,opt,name=ifHeaderMatch,proto3" json:"ifHeaderMatch,omitempty"` // Regex the value of ifHeader must match
+	IfPath        string                 `protobuf:"bytes,8,opt,name=ifPath,proto3" json:"ifPath,omitempty"`               // Regex the request path must match
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *RewriteRule) Reset() {
 	*x = RewriteRule{}
-	mi := &file_mgmtApi_proto_msgTypes[10]
+	mi := &file_mgmtApi_proto_msgTypes[30]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -566,7 +2111,7 @@ func (x *RewriteRule) String() string {
 func (*RewriteRule) ProtoMessage() {}
 
 func (x *RewriteRule) ProtoReflect() protoreflect.Message {
-	mi := &file_mgmtApi_proto_msgTypes[10]
+	mi := &file_mgmtApi_proto_msgTypes[30]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -579,7 +2124,7 @@ func (x *RewriteRule) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RewriteRule.ProtoReflect.Descriptor instead.
 func (*RewriteRule) Descriptor() ([]byte, []int) {
-	return file_mgmtApi_proto_rawDescGZIP(), []int{10}
+	return file_mgmtApi_proto_rawDescGZIP(), []int{30}
 }
 
 func (x *RewriteRule) GetHeaderName() string {
@@ -603,23 +2148,66 @@ func (x *RewriteRule) GetValue() string {
 	return ""
 }
 
+func (x *RewriteRule) GetPhase() int32 {
+	if x != nil {
+		return x.Phase
+	}
+	return 0
+}
+
+func (x *RewriteRule) GetPattern() string {
+	if x != nil {
+		return x.Pattern
+	}
+	return ""
+}
+
+func (x *RewriteRule) GetIfHeader() string {
+	if x != nil {
+		return x.IfHeader
+	}
+	return ""
+}
+
+func (x *RewriteRule) GetIfHeaderMatch() string {
+	if x != nil {
+		return x.IfHeaderMatch
+	}
+	return ""
+}
+
+func (x *RewriteRule) GetIfPath() string {
+	if x != nil {
+		return x.IfPath
+	}
+	return ""
+}
+
 // CacheNode represents a single cache node
 type CacheNode struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`              // name of the cache node
-	Ip            string                 `protobuf:"bytes,2,opt,name=ip,proto3" json:"ip,omitempty"`                  // IP address of the cache node
-	Port          int32                  `protobuf:"varint,3,opt,name=port,proto3" json:"port,omitempty"`             // Port for the cache node to listen
-	Type          string                 `protobuf:"bytes,4,opt,name=type,proto3" json:"type,omitempty"`              // Type of the node (e.g., Mid, Edge)
-	ParentIP      string                 `protobuf:"bytes,5,opt,name=parentIP,proto3" json:"parentIP,omitempty"`      // IP of the upstream cache (empty if no upstream)
-	ParentPort    int32                  `protobuf:"varint,6,opt,name=parentPort,proto3" json:"parentPort,omitempty"` // Port for the upstream cache
-	PromPort      int32                  `protobuf:"varint,7,opt,name=promPort,proto3" json:"promPort,omitempty"`     // Port for the prometheus scrape point
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	Name                 string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`                                   // name of the cache node
+	Ip                   string                 `protobuf:"bytes,2,opt,name=ip,proto3" json:"ip,omitempty"`                                       // IP address of the cache node
+	Port                 int32                  `protobuf:"varint,3,opt,name=port,proto3" json:"port,omitempty"`                                  // Port for the cache node to listen
+	Type                 string                 `protobuf:"bytes,4,opt,name=type,proto3" json:"type,omitempty"`                                   // Type of the node (e.g., Mid, Edge)
+	ParentIP             string                 `protobuf:"bytes,5,opt,name=parentIP,proto3" json:"parentIP,omitempty"`                           // IP of the upstream cache (empty if no upstream)
+	ParentPort           int32                  `protobuf:"varint,6,opt,name=parentPort,proto3" json:"parentPort,omitempty"`                      // Port for the upstream cache
+	PromPort             int32                  `protobuf:"varint,7,opt,name=promPort,proto3" json:"promPort,omitempty"`                          // Port for the prometheus scrape point
+	TlsPort              int32                  `protobuf:"varint,8,opt,name=tlsPort,proto3" json:"tlsPort,omitempty"`                            // Port for the cache node to listen for HTTPS
+	H2C                  bool                   `protobuf:"varint,9,opt,name=h2c,proto3" json:"h2c,omitempty"`                                    // Accept cleartext HTTP/2 (h2c) on port
+	ParentH2C            bool                   `protobuf:"varint,10,opt,name=parentH2C,proto3" json:"parentH2C,omitempty"`                       // Use cleartext HTTP/2 (h2c) towards the upstream cache
+	MaxConcurrentStreams int32                  `protobuf:"varint,11,opt,name=maxConcurrentStreams,proto3" json:"maxConcurrentStreams,omitempty"` // HTTP/2 streams per client connection
+	ErrorPages           []*ErrorPage           `protobuf:"bytes,12,rep,name=errorPages,proto3" json:"errorPages,omitempty"`                      // Inline error pages when no delivery service matches
+	CollapseTimeout      int32                  `protobuf:"varint,13,opt,name=collapseTimeout,proto3" json:"collapseTimeout,omitempty"`           // Seconds a collapsed request waits for the fetch of another
+	Parents              []*Parent              `protobuf:"bytes,14,rep,name=parents,proto3" json:"parents,omitempty"`                            // Upstream caches chosen by consistent hashing of the cache key
+	OriginFallback       bool                   `protobuf:"varint,15,opt,name=originFallback,proto3" json:"originFallback,omitempty"`             // Fetch from the origin when all the parents are down
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
 }
 
 func (x *CacheNode) Reset() {
 	*x = CacheNode{}
-	mi := &file_mgmtApi_proto_msgTypes[11]
+	mi := &file_mgmtApi_proto_msgTypes[31]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -631,7 +2219,7 @@ func (x *CacheNode) String() string {
 func (*CacheNode) ProtoMessage() {}
 
 func (x *CacheNode) ProtoReflect() protoreflect.Message {
-	mi := &file_mgmtApi_proto_msgTypes[11]
+	mi := &file_mgmtApi_proto_msgTypes[31]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -644,7 +2232,7 @@ func (x *CacheNode) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CacheNode.ProtoReflect.Descriptor instead.
 func (*CacheNode) Descriptor() ([]byte, []int) {
-	return file_mgmtApi_proto_rawDescGZIP(), []int{11}
+	return file_mgmtApi_proto_rawDescGZIP(), []int{31}
 }
 
 func (x *CacheNode) GetName() string {
@@ -696,6 +2284,115 @@ func (x *CacheNode) GetPromPort() int32 {
 	return 0
 }
 
+func (x *CacheNode) GetTlsPort() int32 {
+	if x != nil {
+		return x.TlsPort
+	}
+	return 0
+}
+
+func (x *CacheNode) GetH2C() bool {
+	if x != nil {
+		return x.H2C
+	}
+	return false
+}
+
+func (x *CacheNode) GetParentH2C() bool {
+	if x != nil {
+		return x.ParentH2C
+	}
+	return false
+}
+
+func (x *CacheNode) GetMaxConcurrentStreams() int32 {
+	if x != nil {
+		return x.MaxConcurrentStreams
+	}
+	return 0
+}
+
+func (x *CacheNode) GetErrorPages() []*ErrorPage {
+	if x != nil {
+		return x.ErrorPages
+	}
+	return nil
+}
+
+func (x *CacheNode) GetCollapseTimeout() int32 {
+	if x != nil {
+		return x.CollapseTimeout
+	}
+	return 0
+}
+
+func (x *CacheNode) GetParents() []*Parent {
+	if x != nil {
+		return x.Parents
+	}
+	return nil
+}
+
+func (x *CacheNode) GetOriginFallback() bool {
+	if x != nil {
+		return x.OriginFallback
+	}
+	return false
+}
+
+// Parent represents an upstream cache of a cache node
+type Parent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ip            string                 `protobuf:"bytes,1,opt,name=ip,proto3" json:"ip,omitempty"`      // IP address of the upstream cache
+	Port          int32                  `protobuf:"varint,2,opt,name=port,proto3" json:"port,omitempty"` // Port where the upstream cache is listening
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Parent) Reset() {
+	*x = Parent{}
+	mi := &file_mgmtApi_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Parent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Parent) ProtoMessage() {}
+
+func (x *Parent) ProtoReflect() protoreflect.Message {
+	mi := &file_mgmtApi_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Parent.ProtoReflect.Descriptor instead.
+func (*Parent) Descriptor() ([]byte, []int) {
+	return file_mgmtApi_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *Parent) GetIp() string {
+	if x != nil {
+		return x.Ip
+	}
+	return ""
+}
+
+func (x *Parent) GetPort() int32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
 var File_mgmtApi_proto protoreflect.FileDescriptor
 
 var file_mgmtApi_proto_rawDesc = []byte{
@@ -740,66 +2437,336 @@ var file_mgmtApi_proto_rawDesc = []byte{
 	0x0a, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52,
 	0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74,
 	0x75, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73,
-	0x22, 0x6d, 0x0a, 0x06, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x3b, 0x0a, 0x0c, 0x73, 0x65,
-	0x72, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6c, 0x69, 0x73, 0x74, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
-	0x32, 0x18, 0x2e, 0x6d, 0x67, 0x6d, 0x74, 0x41, 0x70, 0x69, 0x2e, 0x44, 0x65, 0x6c, 0x69, 0x76,
-	0x65, 0x72, 0x79, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x52, 0x0b, 0x73, 0x65, 0x72, 0x76,
-	0x69, 0x63, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x26, 0x0a, 0x04, 0x6e, 0x6f, 0x64, 0x65, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x6d, 0x67, 0x6d, 0x74, 0x41, 0x70, 0x69, 0x2e,
-	0x43, 0x61, 0x63, 0x68, 0x65, 0x4e, 0x6f, 0x64, 0x65, 0x52, 0x04, 0x6e, 0x6f, 0x64, 0x65, 0x22,
-	0x9b, 0x01, 0x0a, 0x0f, 0x44, 0x65, 0x6c, 0x69, 0x76, 0x65, 0x72, 0x79, 0x53, 0x65, 0x72, 0x76,
-	0x69, 0x63, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x6c, 0x69, 0x65, 0x6e,
-	0x74, 0x55, 0x52, 0x4c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x6c, 0x69, 0x65,
-	0x6e, 0x74, 0x55, 0x52, 0x4c, 0x12, 0x1c, 0x0a, 0x09, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x55,
-	0x52, 0x4c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e,
-	0x55, 0x52, 0x4c, 0x12, 0x38, 0x0a, 0x0c, 0x72, 0x65, 0x77, 0x72, 0x69, 0x74, 0x65, 0x52, 0x75,
-	0x6c, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x6d, 0x67, 0x6d, 0x74,
-	0x41, 0x70, 0x69, 0x2e, 0x52, 0x65, 0x77, 0x72, 0x69, 0x74, 0x65, 0x52, 0x75, 0x6c, 0x65, 0x52,
-	0x0c, 0x72, 0x65, 0x77, 0x72, 0x69, 0x74, 0x65, 0x52, 0x75, 0x6c, 0x65, 0x73, 0x22, 0x61, 0x0a,
-	0x0b, 0x52, 0x65, 0x77, 0x72, 0x69, 0x74, 0x65, 0x52, 0x75, 0x6c, 0x65, 0x12, 0x1e, 0x0a, 0x0a,
-	0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x0a, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1c, 0x0a, 0x09,
-	0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52,
-	0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61,
-	0x6c, 0x75, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
-	0x22, 0xaf, 0x01, 0x0a, 0x09, 0x43, 0x61, 0x63, 0x68, 0x65, 0x4e, 0x6f, 0x64, 0x65, 0x12, 0x12,
-	0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61,
-	0x6d, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02,
-	0x69, 0x70, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05,
-	0x52, 0x04, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x04,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x61,
-	0x72, 0x65, 0x6e, 0x74, 0x49, 0x50, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x61,
-	0x72, 0x65, 0x6e, 0x74, 0x49, 0x50, 0x12, 0x1e, 0x0a, 0x0a, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74,
-	0x50, 0x6f, 0x72, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x70, 0x61, 0x72, 0x65,
-	0x6e, 0x74, 0x50, 0x6f, 0x72, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x6d, 0x50, 0x6f,
-	0x72, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x6d, 0x50, 0x6f,
-	0x72, 0x74, 0x32, 0xed, 0x02, 0x0a, 0x07, 0x4d, 0x67, 0x6d, 0x74, 0x41, 0x70, 0x69, 0x12, 0x4b,
-	0x0a, 0x0c, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x44, 0x73, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x1c,
-	0x2e, 0x6d, 0x67, 0x6d, 0x74, 0x41, 0x70, 0x69, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x44,
-	0x73, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x6d,
-	0x67, 0x6d, 0x74, 0x41, 0x70, 0x69, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x44, 0x73, 0x4c,
-	0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x57, 0x0a, 0x10, 0x55,
-	0x70, 0x64, 0x61, 0x74, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x4e, 0x6f, 0x64, 0x65, 0x12,
-	0x20, 0x2e, 0x6d, 0x67, 0x6d, 0x74, 0x41, 0x70, 0x69, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65,
-	0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x4e, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x1a, 0x21, 0x2e, 0x6d, 0x67, 0x6d, 0x74, 0x41, 0x70, 0x69, 0x2e, 0x55, 0x70, 0x64, 0x61,
-	0x74, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x4e, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x73, 0x70,
-	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x54, 0x0a, 0x0f, 0x49, 0x6e, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61,
-	0x74, 0x65, 0x43, 0x61, 0x63, 0x68, 0x65, 0x12, 0x1f, 0x2e, 0x6d, 0x67, 0x6d, 0x74, 0x41, 0x70,
-	0x69, 0x2e, 0x49, 0x6e, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x43, 0x61, 0x63, 0x68,
-	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x20, 0x2e, 0x6d, 0x67, 0x6d, 0x74, 0x41,
-	0x70, 0x69, 0x2e, 0x49, 0x6e, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x43, 0x61, 0x63,
-	0x68, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x66, 0x0a, 0x15, 0x49, 0x6e,
-	0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x43, 0x61, 0x63, 0x68, 0x65, 0x53, 0x74, 0x61,
-	0x74, 0x75, 0x73, 0x12, 0x25, 0x2e, 0x6d, 0x67, 0x6d, 0x74, 0x41, 0x70, 0x69, 0x2e, 0x49, 0x6e,
-	0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x43, 0x61, 0x63, 0x68, 0x65, 0x53, 0x74, 0x61,
-	0x74, 0x75, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x26, 0x2e, 0x6d, 0x67, 0x6d,
-	0x74, 0x41, 0x70, 0x69, 0x2e, 0x49, 0x6e, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x43,
-	0x61, 0x63, 0x68, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x42, 0x15, 0x5a, 0x13, 0x63, 0x64, 0x6e, 0x2f, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e,
-	0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x33,
+	0x22, 0x25, 0x0a, 0x13, 0x4f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x64, 0x73, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x02, 0x64, 0x73, 0x22, 0x47, 0x0a, 0x14, 0x4f, 0x72, 0x69, 0x67, 0x69,
+	0x6e, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x2f, 0x0a, 0x07, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x15, 0x2e, 0x6d, 0x67, 0x6d, 0x74, 0x41, 0x70, 0x69, 0x2e, 0x4f, 0x72, 0x69, 0x67, 0x69,
+	0x6e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x07, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x73,
+	0x22, 0x9e, 0x01, 0x0a, 0x0c, 0x4f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x12, 0x0e, 0x0a, 0x02, 0x64, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x64,
+	0x73, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x72, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
+	0x75, 0x72, 0x6c, 0x12, 0x16, 0x0a, 0x06, 0x62, 0x61, 0x63, 0x6b, 0x75, 0x70, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x06, 0x62, 0x61, 0x63, 0x6b, 0x75, 0x70, 0x12, 0x18, 0x0a, 0x07, 0x68,
+	0x65, 0x61, 0x6c, 0x74, 0x68, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x68, 0x65,
+	0x61, 0x6c, 0x74, 0x68, 0x79, 0x12, 0x1c, 0x0a, 0x09, 0x6c, 0x61, 0x73, 0x74, 0x43, 0x68, 0x65,
+	0x63, 0x6b, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x6c, 0x61, 0x73, 0x74, 0x43, 0x68,
+	0x65, 0x63, 0x6b, 0x12, 0x1c, 0x0a, 0x09, 0x6c, 0x61, 0x73, 0x74, 0x45, 0x72, 0x72, 0x6f, 0x72,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6c, 0x61, 0x73, 0x74, 0x45, 0x72, 0x72, 0x6f,
+	0x72, 0x22, 0x6d, 0x0a, 0x06, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x3b, 0x0a, 0x0c, 0x73,
+	0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6c, 0x69, 0x73, 0x74, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x18, 0x2e, 0x6d, 0x67, 0x6d, 0x74, 0x41, 0x70, 0x69, 0x2e, 0x44, 0x65, 0x6c, 0x69,
+	0x76, 0x65, 0x72, 0x79, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x52, 0x0b, 0x73, 0x65, 0x72,
+	0x76, 0x69, 0x63, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x26, 0x0a, 0x04, 0x6e, 0x6f, 0x64, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x6d, 0x67, 0x6d, 0x74, 0x41, 0x70, 0x69,
+	0x2e, 0x43, 0x61, 0x63, 0x68, 0x65, 0x4e, 0x6f, 0x64, 0x65, 0x52, 0x04, 0x6e, 0x6f, 0x64, 0x65,
+	0x22, 0xdb, 0x07, 0x0a, 0x0f, 0x44, 0x65, 0x6c, 0x69, 0x76, 0x65, 0x72, 0x79, 0x53, 0x65, 0x72,
+	0x76, 0x69, 0x63, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x6c, 0x69, 0x65,
+	0x6e, 0x74, 0x55, 0x52, 0x4c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x6c, 0x69,
+	0x65, 0x6e, 0x74, 0x55, 0x52, 0x4c, 0x12, 0x1c, 0x0a, 0x09, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e,
+	0x55, 0x52, 0x4c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6f, 0x72, 0x69, 0x67, 0x69,
+	0x6e, 0x55, 0x52, 0x4c, 0x12, 0x38, 0x0a, 0x0c, 0x72, 0x65, 0x77, 0x72, 0x69, 0x74, 0x65, 0x52,
+	0x75, 0x6c, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x6d, 0x67, 0x6d,
+	0x74, 0x41, 0x70, 0x69, 0x2e, 0x52, 0x65, 0x77, 0x72, 0x69, 0x74, 0x65, 0x52, 0x75, 0x6c, 0x65,
+	0x52, 0x0c, 0x72, 0x65, 0x77, 0x72, 0x69, 0x74, 0x65, 0x52, 0x75, 0x6c, 0x65, 0x73, 0x12, 0x32,
+	0x0a, 0x14, 0x73, 0x74, 0x61, 0x6c, 0x65, 0x57, 0x68, 0x69, 0x6c, 0x65, 0x52, 0x65, 0x76, 0x61,
+	0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x14, 0x73, 0x74,
+	0x61, 0x6c, 0x65, 0x57, 0x68, 0x69, 0x6c, 0x65, 0x52, 0x65, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61,
+	0x74, 0x65, 0x12, 0x22, 0x0a, 0x0c, 0x73, 0x74, 0x61, 0x6c, 0x65, 0x49, 0x66, 0x45, 0x72, 0x72,
+	0x6f, 0x72, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x73, 0x74, 0x61, 0x6c, 0x65, 0x49,
+	0x66, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x2d, 0x0a, 0x08, 0x63, 0x61, 0x63, 0x68, 0x65, 0x4b,
+	0x65, 0x79, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x6d, 0x67, 0x6d, 0x74, 0x41,
+	0x70, 0x69, 0x2e, 0x43, 0x61, 0x63, 0x68, 0x65, 0x4b, 0x65, 0x79, 0x52, 0x08, 0x63, 0x61, 0x63,
+	0x68, 0x65, 0x4b, 0x65, 0x79, 0x12, 0x24, 0x0a, 0x03, 0x74, 0x6c, 0x73, 0x18, 0x08, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x12, 0x2e, 0x6d, 0x67, 0x6d, 0x74, 0x41, 0x70, 0x69, 0x2e, 0x54, 0x4c, 0x53,
+	0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x03, 0x74, 0x6c, 0x73, 0x12, 0x36, 0x0a, 0x0b, 0x63,
+	0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x14, 0x2e, 0x6d, 0x67, 0x6d, 0x74, 0x41, 0x70, 0x69, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x72,
+	0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x0b, 0x63, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73,
+	0x69, 0x6f, 0x6e, 0x12, 0x30, 0x0a, 0x09, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x41, 0x75, 0x74, 0x68,
+	0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x6d, 0x67, 0x6d, 0x74, 0x41, 0x70, 0x69,
+	0x2e, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x41, 0x75, 0x74, 0x68, 0x52, 0x09, 0x74, 0x6f, 0x6b, 0x65,
+	0x6e, 0x41, 0x75, 0x74, 0x68, 0x12, 0x28, 0x0a, 0x03, 0x61, 0x63, 0x6c, 0x18, 0x0b, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x16, 0x2e, 0x6d, 0x67, 0x6d, 0x74, 0x41, 0x70, 0x69, 0x2e, 0x41, 0x63, 0x63,
+	0x65, 0x73, 0x73, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x52, 0x03, 0x61, 0x63, 0x6c, 0x12,
+	0x30, 0x0a, 0x09, 0x72, 0x61, 0x74, 0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x0c, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x12, 0x2e, 0x6d, 0x67, 0x6d, 0x74, 0x41, 0x70, 0x69, 0x2e, 0x52, 0x61, 0x74,
+	0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x52, 0x09, 0x72, 0x61, 0x74, 0x65, 0x4c, 0x69, 0x6d, 0x69,
+	0x74, 0x12, 0x39, 0x0a, 0x0b, 0x75, 0x72, 0x6c, 0x52, 0x65, 0x77, 0x72, 0x69, 0x74, 0x65, 0x73,
+	0x18, 0x0d, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x6d, 0x67, 0x6d, 0x74, 0x41, 0x70, 0x69,
+	0x2e, 0x55, 0x72, 0x6c, 0x52, 0x65, 0x77, 0x72, 0x69, 0x74, 0x65, 0x52, 0x75, 0x6c, 0x65, 0x52,
+	0x0b, 0x75, 0x72, 0x6c, 0x52, 0x65, 0x77, 0x72, 0x69, 0x74, 0x65, 0x73, 0x12, 0x33, 0x0a, 0x09,
+	0x72, 0x65, 0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x73, 0x18, 0x0e, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x15, 0x2e, 0x6d, 0x67, 0x6d, 0x74, 0x41, 0x70, 0x69, 0x2e, 0x52, 0x65, 0x64, 0x69, 0x72, 0x65,
+	0x63, 0x74, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x09, 0x72, 0x65, 0x64, 0x69, 0x72, 0x65, 0x63, 0x74,
+	0x73, 0x12, 0x21, 0x0a, 0x04, 0x63, 0x6f, 0x72, 0x73, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x0d, 0x2e, 0x6d, 0x67, 0x6d, 0x74, 0x41, 0x70, 0x69, 0x2e, 0x43, 0x6f, 0x72, 0x73, 0x52, 0x04,
+	0x63, 0x6f, 0x72, 0x73, 0x12, 0x32, 0x0a, 0x0a, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x50, 0x61, 0x67,
+	0x65, 0x73, 0x18, 0x10, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x6d, 0x67, 0x6d, 0x74, 0x41,
+	0x70, 0x69, 0x2e, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x50, 0x61, 0x67, 0x65, 0x52, 0x0a, 0x65, 0x72,
+	0x72, 0x6f, 0x72, 0x50, 0x61, 0x67, 0x65, 0x73, 0x12, 0x36, 0x0a, 0x0b, 0x6f, 0x72, 0x69, 0x67,
+	0x69, 0x6e, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x18, 0x11, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e,
+	0x6d, 0x67, 0x6d, 0x74, 0x41, 0x70, 0x69, 0x2e, 0x4f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x47, 0x72,
+	0x6f, 0x75, 0x70, 0x52, 0x0b, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x47, 0x72, 0x6f, 0x75, 0x70,
+	0x12, 0x2a, 0x0a, 0x05, 0x72, 0x65, 0x74, 0x72, 0x79, 0x18, 0x12, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x14, 0x2e, 0x6d, 0x67, 0x6d, 0x74, 0x41, 0x70, 0x69, 0x2e, 0x52, 0x65, 0x74, 0x72, 0x79, 0x50,
+	0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x05, 0x72, 0x65, 0x74, 0x72, 0x79, 0x12, 0x3f, 0x0a, 0x0e,
+	0x63, 0x69, 0x72, 0x63, 0x75, 0x69, 0x74, 0x42, 0x72, 0x65, 0x61, 0x6b, 0x65, 0x72, 0x18, 0x13,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x6d, 0x67, 0x6d, 0x74, 0x41, 0x70, 0x69, 0x2e, 0x43,
+	0x69, 0x72, 0x63, 0x75, 0x69, 0x74, 0x42, 0x72, 0x65, 0x61, 0x6b, 0x65, 0x72, 0x52, 0x0e, 0x63,
+	0x69, 0x72, 0x63, 0x75, 0x69, 0x74, 0x42, 0x72, 0x65, 0x61, 0x6b, 0x65, 0x72, 0x12, 0x2d, 0x0a,
+	0x08, 0x75, 0x70, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x18, 0x14, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x11, 0x2e, 0x6d, 0x67, 0x6d, 0x74, 0x41, 0x70, 0x69, 0x2e, 0x55, 0x70, 0x73, 0x74, 0x72, 0x65,
+	0x61, 0x6d, 0x52, 0x08, 0x75, 0x70, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x30, 0x0a, 0x09,
+	0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x54, 0x4c, 0x53, 0x18, 0x15, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x12, 0x2e, 0x6d, 0x67, 0x6d, 0x74, 0x41, 0x70, 0x69, 0x2e, 0x4f, 0x72, 0x69, 0x67, 0x69, 0x6e,
+	0x54, 0x4c, 0x53, 0x52, 0x09, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x54, 0x4c, 0x53, 0x22, 0xb3,
+	0x01, 0x0a, 0x09, 0x4f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x54, 0x4c, 0x53, 0x12, 0x1a, 0x0a, 0x08,
+	0x63, 0x61, 0x42, 0x75, 0x6e, 0x64, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08,
+	0x63, 0x61, 0x42, 0x75, 0x6e, 0x64, 0x6c, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x73, 0x65, 0x72, 0x76,
+	0x65, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x73, 0x65,
+	0x72, 0x76, 0x65, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x6d, 0x69, 0x6e, 0x56,
+	0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x6d, 0x69,
+	0x6e, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x2c, 0x0a, 0x11, 0x63, 0x6c, 0x69, 0x65,
+	0x6e, 0x74, 0x43, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x11, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x43, 0x65, 0x72, 0x74, 0x69,
+	0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74,
+	0x4b, 0x65, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x6c, 0x69, 0x65, 0x6e,
+	0x74, 0x4b, 0x65, 0x79, 0x22, 0xfe, 0x01, 0x0a, 0x08, 0x55, 0x70, 0x73, 0x74, 0x72, 0x65, 0x61,
+	0x6d, 0x12, 0x26, 0x0a, 0x0e, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x54, 0x69, 0x6d, 0x65,
+	0x6f, 0x75, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0e, 0x63, 0x6f, 0x6e, 0x6e, 0x65,
+	0x63, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x30, 0x0a, 0x13, 0x74, 0x6c, 0x73,
+	0x48, 0x61, 0x6e, 0x64, 0x73, 0x68, 0x61, 0x6b, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x13, 0x74, 0x6c, 0x73, 0x48, 0x61, 0x6e, 0x64, 0x73,
+	0x68, 0x61, 0x6b, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x20, 0x0a, 0x0b, 0x74,
+	0x74, 0x66, 0x62, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x0b, 0x74, 0x74, 0x66, 0x62, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x28, 0x0a,
+	0x0f, 0x69, 0x64, 0x6c, 0x65, 0x52, 0x65, 0x61, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0f, 0x69, 0x64, 0x6c, 0x65, 0x52, 0x65, 0x61, 0x64,
+	0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x22, 0x0a, 0x0c, 0x74, 0x6f, 0x74, 0x61, 0x6c,
+	0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x74,
+	0x6f, 0x74, 0x61, 0x6c, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x28, 0x0a, 0x0f, 0x6d,
+	0x61, 0x78, 0x43, 0x6f, 0x6e, 0x6e, 0x73, 0x50, 0x65, 0x72, 0x48, 0x6f, 0x73, 0x74, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x0f, 0x6d, 0x61, 0x78, 0x43, 0x6f, 0x6e, 0x6e, 0x73, 0x50, 0x65,
+	0x72, 0x48, 0x6f, 0x73, 0x74, 0x22, 0x9d, 0x01, 0x0a, 0x0b, 0x52, 0x65, 0x74, 0x72, 0x79, 0x50,
+	0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x20, 0x0a, 0x0b, 0x6d, 0x61, 0x78, 0x41, 0x74, 0x74, 0x65,
+	0x6d, 0x70, 0x74, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x6d, 0x61, 0x78, 0x41,
+	0x74, 0x74, 0x65, 0x6d, 0x70, 0x74, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x73, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x05, 0x52, 0x08, 0x73, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x65, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x18, 0x03, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x06, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x62,
+	0x61, 0x63, 0x6b, 0x6f, 0x66, 0x66, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x62, 0x61,
+	0x63, 0x6b, 0x6f, 0x66, 0x66, 0x12, 0x1e, 0x0a, 0x0a, 0x6d, 0x61, 0x78, 0x42, 0x61, 0x63, 0x6b,
+	0x6f, 0x66, 0x66, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x6d, 0x61, 0x78, 0x42, 0x61,
+	0x63, 0x6b, 0x6f, 0x66, 0x66, 0x22, 0x72, 0x0a, 0x0e, 0x43, 0x69, 0x72, 0x63, 0x75, 0x69, 0x74,
+	0x42, 0x72, 0x65, 0x61, 0x6b, 0x65, 0x72, 0x12, 0x18, 0x0a, 0x07, 0x65, 0x6e, 0x61, 0x62, 0x6c,
+	0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65,
+	0x64, 0x12, 0x2a, 0x0a, 0x10, 0x66, 0x61, 0x69, 0x6c, 0x75, 0x72, 0x65, 0x54, 0x68, 0x72, 0x65,
+	0x73, 0x68, 0x6f, 0x6c, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x10, 0x66, 0x61, 0x69,
+	0x6c, 0x75, 0x72, 0x65, 0x54, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x12, 0x1a, 0x0a,
+	0x08, 0x63, 0x6f, 0x6f, 0x6c, 0x64, 0x6f, 0x77, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x08, 0x63, 0x6f, 0x6f, 0x6c, 0x64, 0x6f, 0x77, 0x6e, 0x22, 0x9e, 0x02, 0x0a, 0x0b, 0x4f, 0x72,
+	0x69, 0x67, 0x69, 0x6e, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x12, 0x29, 0x0a, 0x07, 0x6f, 0x72, 0x69,
+	0x67, 0x69, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x6d, 0x67, 0x6d,
+	0x74, 0x41, 0x70, 0x69, 0x2e, 0x4f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x52, 0x07, 0x6f, 0x72, 0x69,
+	0x67, 0x69, 0x6e, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x50, 0x61,
+	0x74, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68,
+	0x50, 0x61, 0x74, 0x68, 0x12, 0x26, 0x0a, 0x0e, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x49, 0x6e,
+	0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0e, 0x68, 0x65,
+	0x61, 0x6c, 0x74, 0x68, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x12, 0x24, 0x0a, 0x0d,
+	0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x0d, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x54, 0x69, 0x6d, 0x65, 0x6f,
+	0x75, 0x74, 0x12, 0x26, 0x0a, 0x0e, 0x75, 0x6e, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x79, 0x41,
+	0x66, 0x74, 0x65, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0e, 0x75, 0x6e, 0x68, 0x65,
+	0x61, 0x6c, 0x74, 0x68, 0x79, 0x41, 0x66, 0x74, 0x65, 0x72, 0x12, 0x22, 0x0a, 0x0c, 0x68, 0x65,
+	0x61, 0x6c, 0x74, 0x68, 0x79, 0x41, 0x66, 0x74, 0x65, 0x72, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x0c, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x79, 0x41, 0x66, 0x74, 0x65, 0x72, 0x12, 0x2a,
+	0x0a, 0x10, 0x66, 0x61, 0x69, 0x6c, 0x6f, 0x76, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x65, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x05, 0x52, 0x10, 0x66, 0x61, 0x69, 0x6c, 0x6f, 0x76,
+	0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x65, 0x73, 0x22, 0x4a, 0x0a, 0x06, 0x4f, 0x72,
+	0x69, 0x67, 0x69, 0x6e, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x72, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x03, 0x75, 0x72, 0x6c, 0x12, 0x16, 0x0a, 0x06, 0x62, 0x61, 0x63, 0x6b, 0x75, 0x70,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x62, 0x61, 0x63, 0x6b, 0x75, 0x70, 0x12, 0x16,
+	0x0a, 0x06, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06,
+	0x77, 0x65, 0x69, 0x67, 0x68, 0x74, 0x22, 0x71, 0x0a, 0x09, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x50,
+	0x61, 0x67, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x10, 0x0a, 0x03, 0x75,
+	0x72, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x72, 0x6c, 0x12, 0x18, 0x0a,
+	0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
+	0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x12, 0x20, 0x0a, 0x0b, 0x63, 0x6f, 0x6e, 0x74, 0x65,
+	0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x63, 0x6f,
+	0x6e, 0x74, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x22, 0xf6, 0x01, 0x0a, 0x04, 0x43, 0x6f,
+	0x72, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x07, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x12, 0x22, 0x0a, 0x0c,
+	0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x4f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x0c, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x4f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x73,
+	0x12, 0x22, 0x0a, 0x0c, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x4d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x73,
+	0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0c, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x4d, 0x65, 0x74,
+	0x68, 0x6f, 0x64, 0x73, 0x12, 0x22, 0x0a, 0x0c, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x48, 0x65, 0x61,
+	0x64, 0x65, 0x72, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0c, 0x61, 0x6c, 0x6c, 0x6f,
+	0x77, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x12, 0x24, 0x0a, 0x0d, 0x65, 0x78, 0x70, 0x6f,
+	0x73, 0x65, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x09, 0x52,
+	0x0d, 0x65, 0x78, 0x70, 0x6f, 0x73, 0x65, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x12, 0x2a,
+	0x0a, 0x10, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x43, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61,
+	0x6c, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x10, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x43,
+	0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x6d, 0x61,
+	0x78, 0x41, 0x67, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x6d, 0x61, 0x78, 0x41,
+	0x67, 0x65, 0x22, 0x84, 0x01, 0x0a, 0x0c, 0x52, 0x65, 0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x52,
+	0x75, 0x6c, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x74,
+	0x61, 0x72, 0x67, 0x65, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x74, 0x61, 0x72,
+	0x67, 0x65, 0x74, 0x12, 0x1e, 0x0a, 0x0a, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x43, 0x6f, 0x64,
+	0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x43,
+	0x6f, 0x64, 0x65, 0x12, 0x24, 0x0a, 0x0d, 0x70, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x51,
+	0x75, 0x65, 0x72, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0d, 0x70, 0x72, 0x65, 0x73,
+	0x65, 0x72, 0x76, 0x65, 0x51, 0x75, 0x65, 0x72, 0x79, 0x22, 0x70, 0x0a, 0x0e, 0x55, 0x72, 0x6c,
+	0x52, 0x65, 0x77, 0x72, 0x69, 0x74, 0x65, 0x52, 0x75, 0x6c, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x70,
+	0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x70, 0x61,
+	0x74, 0x74, 0x65, 0x72, 0x6e, 0x12, 0x20, 0x0a, 0x0b, 0x72, 0x65, 0x70, 0x6c, 0x61, 0x63, 0x65,
+	0x6d, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x72, 0x65, 0x70, 0x6c,
+	0x61, 0x63, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x22, 0x0a, 0x0c, 0x75, 0x70, 0x73, 0x74, 0x72,
+	0x65, 0x61, 0x6d, 0x4f, 0x6e, 0x6c, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x75,
+	0x70, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x4f, 0x6e, 0x6c, 0x79, 0x22, 0xa5, 0x01, 0x0a, 0x09,
+	0x52, 0x61, 0x74, 0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x1e, 0x0a, 0x0a, 0x63, 0x6c, 0x69,
+	0x65, 0x6e, 0x74, 0x52, 0x61, 0x74, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x63,
+	0x6c, 0x69, 0x65, 0x6e, 0x74, 0x52, 0x61, 0x74, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x63, 0x6c, 0x69,
+	0x65, 0x6e, 0x74, 0x42, 0x75, 0x72, 0x73, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b,
+	0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x42, 0x75, 0x72, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x64,
+	0x73, 0x52, 0x61, 0x74, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06, 0x64, 0x73, 0x52,
+	0x61, 0x74, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x64, 0x73, 0x42, 0x75, 0x72, 0x73, 0x74, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x64, 0x73, 0x42, 0x75, 0x72, 0x73, 0x74, 0x12, 0x24, 0x0a,
+	0x0d, 0x6d, 0x61, 0x78, 0x43, 0x6f, 0x6e, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x0d, 0x6d, 0x61, 0x78, 0x43, 0x6f, 0x6e, 0x63, 0x75, 0x72, 0x72,
+	0x65, 0x6e, 0x74, 0x22, 0x9b, 0x01, 0x0a, 0x0d, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x43, 0x6f,
+	0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x12, 0x1e, 0x0a, 0x0a, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x43, 0x69,
+	0x64, 0x72, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x61, 0x6c, 0x6c, 0x6f, 0x77,
+	0x43, 0x69, 0x64, 0x72, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x64, 0x65, 0x6e, 0x79, 0x43, 0x69, 0x64,
+	0x72, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x09, 0x64, 0x65, 0x6e, 0x79, 0x43, 0x69,
+	0x64, 0x72, 0x73, 0x12, 0x26, 0x0a, 0x0e, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x43, 0x6f, 0x75, 0x6e,
+	0x74, 0x72, 0x69, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0e, 0x61, 0x6c, 0x6c,
+	0x6f, 0x77, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x12, 0x24, 0x0a, 0x0d, 0x64,
+	0x65, 0x6e, 0x79, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x0d, 0x64, 0x65, 0x6e, 0x79, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x72, 0x69, 0x65,
+	0x73, 0x22, 0xaa, 0x01, 0x0a, 0x09, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x41, 0x75, 0x74, 0x68, 0x12,
+	0x18, 0x0a, 0x07, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x07, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x12, 0x1e, 0x0a, 0x0a, 0x71, 0x75, 0x65,
+	0x72, 0x79, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x71,
+	0x75, 0x65, 0x72, 0x79, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x12, 0x1e, 0x0a, 0x0a, 0x63, 0x6f, 0x6f,
+	0x6b, 0x69, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x63,
+	0x6f, 0x6f, 0x6b, 0x69, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x72, 0x65, 0x71,
+	0x75, 0x69, 0x72, 0x65, 0x49, 0x70, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x72, 0x65,
+	0x71, 0x75, 0x69, 0x72, 0x65, 0x49, 0x70, 0x12, 0x25, 0x0a, 0x04, 0x6b, 0x65, 0x79, 0x73, 0x18,
+	0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x6d, 0x67, 0x6d, 0x74, 0x41, 0x70, 0x69, 0x2e,
+	0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x4b, 0x65, 0x79, 0x52, 0x04, 0x6b, 0x65, 0x79, 0x73, 0x22, 0x32,
+	0x0a, 0x08, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x4b, 0x65, 0x79, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x65,
+	0x63, 0x72, 0x65, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x65, 0x63, 0x72,
+	0x65, 0x74, 0x22, 0x5f, 0x0a, 0x0b, 0x43, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x12, 0x18, 0x0a, 0x07, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x07, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x6d,
+	0x69, 0x6d, 0x65, 0x54, 0x79, 0x70, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x09,
+	0x6d, 0x69, 0x6d, 0x65, 0x54, 0x79, 0x70, 0x65, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x69, 0x6e,
+	0x53, 0x69, 0x7a, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x6d, 0x69, 0x6e, 0x53,
+	0x69, 0x7a, 0x65, 0x22, 0xbc, 0x01, 0x0a, 0x08, 0x43, 0x61, 0x63, 0x68, 0x65, 0x4b, 0x65, 0x79,
+	0x12, 0x1c, 0x0a, 0x09, 0x71, 0x75, 0x65, 0x72, 0x79, 0x4d, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x09, 0x71, 0x75, 0x65, 0x72, 0x79, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x20,
+	0x0a, 0x0b, 0x71, 0x75, 0x65, 0x72, 0x79, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x18, 0x02, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x0b, 0x71, 0x75, 0x65, 0x72, 0x79, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73,
+	0x12, 0x1c, 0x0a, 0x09, 0x73, 0x6f, 0x72, 0x74, 0x51, 0x75, 0x65, 0x72, 0x79, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x09, 0x73, 0x6f, 0x72, 0x74, 0x51, 0x75, 0x65, 0x72, 0x79, 0x12, 0x18,
+	0x0a, 0x07, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52,
+	0x07, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6f, 0x6b,
+	0x69, 0x65, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x6f, 0x6b, 0x69,
+	0x65, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x69, 0x67, 0x6e, 0x6f, 0x72, 0x65, 0x43, 0x61, 0x73, 0x65,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x69, 0x67, 0x6e, 0x6f, 0x72, 0x65, 0x43, 0x61,
+	0x73, 0x65, 0x22, 0x77, 0x0a, 0x09, 0x54, 0x4c, 0x53, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12,
+	0x20, 0x0a, 0x0b, 0x63, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x63, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74,
+	0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x70, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x4b, 0x65, 0x79, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x70, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x4b, 0x65,
+	0x79, 0x12, 0x28, 0x0a, 0x0f, 0x72, 0x65, 0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x54, 0x6f, 0x48,
+	0x74, 0x74, 0x70, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0f, 0x72, 0x65, 0x64, 0x69,
+	0x72, 0x65, 0x63, 0x74, 0x54, 0x6f, 0x48, 0x74, 0x74, 0x70, 0x73, 0x22, 0xeb, 0x01, 0x0a, 0x0b,
+	0x52, 0x65, 0x77, 0x72, 0x69, 0x74, 0x65, 0x52, 0x75, 0x6c, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x68,
+	0x65, 0x61, 0x64, 0x65, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0a, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x6f,
+	0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09,
+	0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x12,
+	0x14, 0x0a, 0x05, 0x70, 0x68, 0x61, 0x73, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05,
+	0x70, 0x68, 0x61, 0x73, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x12,
+	0x1a, 0x0a, 0x08, 0x69, 0x66, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x08, 0x69, 0x66, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x24, 0x0a, 0x0d, 0x69,
+	0x66, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x18, 0x07, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0d, 0x69, 0x66, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x4d, 0x61, 0x74, 0x63,
+	0x68, 0x12, 0x16, 0x0a, 0x06, 0x69, 0x66, 0x50, 0x61, 0x74, 0x68, 0x18, 0x08, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x69, 0x66, 0x50, 0x61, 0x74, 0x68, 0x22, 0xde, 0x03, 0x0a, 0x09, 0x43, 0x61,
+	0x63, 0x68, 0x65, 0x4e, 0x6f, 0x64, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69,
+	0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x70, 0x12, 0x12, 0x0a, 0x04, 0x70,
+	0x6f, 0x72, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x70, 0x6f, 0x72, 0x74, 0x12,
+	0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74,
+	0x79, 0x70, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x49, 0x50, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x49, 0x50, 0x12,
+	0x1e, 0x0a, 0x0a, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x50, 0x6f, 0x72, 0x74, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x0a, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x50, 0x6f, 0x72, 0x74, 0x12,
+	0x1a, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x6d, 0x50, 0x6f, 0x72, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x6d, 0x50, 0x6f, 0x72, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x74,
+	0x6c, 0x73, 0x50, 0x6f, 0x72, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x74, 0x6c,
+	0x73, 0x50, 0x6f, 0x72, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x68, 0x32, 0x63, 0x18, 0x09, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x03, 0x68, 0x32, 0x63, 0x12, 0x1c, 0x0a, 0x09, 0x70, 0x61, 0x72, 0x65, 0x6e,
+	0x74, 0x48, 0x32, 0x43, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x70, 0x61, 0x72, 0x65,
+	0x6e, 0x74, 0x48, 0x32, 0x43, 0x12, 0x32, 0x0a, 0x14, 0x6d, 0x61, 0x78, 0x43, 0x6f, 0x6e, 0x63,
+	0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x18, 0x0b, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x14, 0x6d, 0x61, 0x78, 0x43, 0x6f, 0x6e, 0x63, 0x75, 0x72, 0x72, 0x65,
+	0x6e, 0x74, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x12, 0x32, 0x0a, 0x0a, 0x65, 0x72, 0x72,
+	0x6f, 0x72, 0x50, 0x61, 0x67, 0x65, 0x73, 0x18, 0x0c, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e,
+	0x6d, 0x67, 0x6d, 0x74, 0x41, 0x70, 0x69, 0x2e, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x50, 0x61, 0x67,
+	0x65, 0x52, 0x0a, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x50, 0x61, 0x67, 0x65, 0x73, 0x12, 0x28, 0x0a,
+	0x0f, 0x63, 0x6f, 0x6c, 0x6c, 0x61, 0x70, 0x73, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74,
+	0x18, 0x0d, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0f, 0x63, 0x6f, 0x6c, 0x6c, 0x61, 0x70, 0x73, 0x65,
+	0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x29, 0x0a, 0x07, 0x70, 0x61, 0x72, 0x65, 0x6e,
+	0x74, 0x73, 0x18, 0x0e, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x6d, 0x67, 0x6d, 0x74, 0x41,
+	0x70, 0x69, 0x2e, 0x50, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x52, 0x07, 0x70, 0x61, 0x72, 0x65, 0x6e,
+	0x74, 0x73, 0x12, 0x26, 0x0a, 0x0e, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x46, 0x61, 0x6c, 0x6c,
+	0x62, 0x61, 0x63, 0x6b, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0e, 0x6f, 0x72, 0x69, 0x67,
+	0x69, 0x6e, 0x46, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x22, 0x2c, 0x0a, 0x06, 0x50, 0x61,
+	0x72, 0x65, 0x6e, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x02, 0x69, 0x70, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x04, 0x70, 0x6f, 0x72, 0x74, 0x32, 0xba, 0x03, 0x0a, 0x07, 0x4d, 0x67, 0x6d,
+	0x74, 0x41, 0x70, 0x69, 0x12, 0x4b, 0x0a, 0x0c, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x44, 0x73,
+	0x4c, 0x69, 0x73, 0x74, 0x12, 0x1c, 0x2e, 0x6d, 0x67, 0x6d, 0x74, 0x41, 0x70, 0x69, 0x2e, 0x55,
+	0x70, 0x64, 0x61, 0x74, 0x65, 0x44, 0x73, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x6d, 0x67, 0x6d, 0x74, 0x41, 0x70, 0x69, 0x2e, 0x55, 0x70, 0x64,
+	0x61, 0x74, 0x65, 0x44, 0x73, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x57, 0x0a, 0x10, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69,
+	0x67, 0x4e, 0x6f, 0x64, 0x65, 0x12, 0x20, 0x2e, 0x6d, 0x67, 0x6d, 0x74, 0x41, 0x70, 0x69, 0x2e,
+	0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x4e, 0x6f, 0x64, 0x65,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e, 0x6d, 0x67, 0x6d, 0x74, 0x41, 0x70,
+	0x69, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x4e, 0x6f,
+	0x64, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x54, 0x0a, 0x0f, 0x49, 0x6e,
+	0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x43, 0x61, 0x63, 0x68, 0x65, 0x12, 0x1f, 0x2e,
+	0x6d, 0x67, 0x6d, 0x74, 0x41, 0x70, 0x69, 0x2e, 0x49, 0x6e, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61,
+	0x74, 0x65, 0x43, 0x61, 0x63, 0x68, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x20,
+	0x2e, 0x6d, 0x67, 0x6d, 0x74, 0x41, 0x70, 0x69, 0x2e, 0x49, 0x6e, 0x76, 0x61, 0x6c, 0x69, 0x64,
+	0x61, 0x74, 0x65, 0x43, 0x61, 0x63, 0x68, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x66, 0x0a, 0x15, 0x49, 0x6e, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x43, 0x61,
+	0x63, 0x68, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x25, 0x2e, 0x6d, 0x67, 0x6d, 0x74,
+	0x41, 0x70, 0x69, 0x2e, 0x49, 0x6e, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x43, 0x61,
+	0x63, 0x68, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x26, 0x2e, 0x6d, 0x67, 0x6d, 0x74, 0x41, 0x70, 0x69, 0x2e, 0x49, 0x6e, 0x76, 0x61, 0x6c,
+	0x69, 0x64, 0x61, 0x74, 0x65, 0x43, 0x61, 0x63, 0x68, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4b, 0x0a, 0x0c, 0x4f, 0x72, 0x69, 0x67,
+	0x69, 0x6e, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x12, 0x1c, 0x2e, 0x6d, 0x67, 0x6d, 0x74, 0x41,
+	0x70, 0x69, 0x2e, 0x4f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x6d, 0x67, 0x6d, 0x74, 0x41, 0x70, 0x69,
+	0x2e, 0x4f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x15, 0x5a, 0x13, 0x63, 0x64, 0x6e, 0x2f, 0x63, 0x6f, 0x6d,
+	0x6d, 0x6f, 0x6e, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x62, 0x06, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -814,7 +2781,7 @@ func file_mgmtApi_proto_rawDescGZIP() []byte {
 	return file_mgmtApi_proto_rawDescData
 }
 
-var file_mgmtApi_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_mgmtApi_proto_msgTypes = make([]protoimpl.MessageInfo, 33)
 var file_mgmtApi_proto_goTypes = []any{
 	(*UpdateDsListRequest)(nil),           // 0: mgmtApi.UpdateDsListRequest
 	(*UpdateDsListResponse)(nil),          // 1: mgmtApi.UpdateDsListResponse
@@ -824,30 +2791,73 @@ var file_mgmtApi_proto_goTypes = []any{
 	(*InvalidateCacheResponse)(nil),       // 5: mgmtApi.InvalidateCacheResponse
 	(*InvalidateCacheStatusRequest)(nil),  // 6: mgmtApi.InvalidateCacheStatusRequest
 	(*InvalidateCacheStatusResponse)(nil), // 7: mgmtApi.InvalidateCacheStatusResponse
-	(*Config)(nil),                        // 8: mgmtApi.Config
-	(*DeliveryService)(nil),               // 9: mgmtApi.DeliveryService
-	(*RewriteRule)(nil),                   // 10: mgmtApi.RewriteRule
-	(*CacheNode)(nil),                     // 11: mgmtApi.CacheNode
+	(*OriginHealthRequest)(nil),           // 8: mgmtApi.OriginHealthRequest
+	(*OriginHealthResponse)(nil),          // 9: mgmtApi.OriginHealthResponse
+	(*OriginStatus)(nil),                  // 10: mgmtApi.OriginStatus
+	(*Config)(nil),                        // 11: mgmtApi.Config
+	(*DeliveryService)(nil),               // 12: mgmtApi.DeliveryService
+	(*OriginTLS)(nil),                     // 13: mgmtApi.OriginTLS
+	(*Upstream)(nil),                      // 14: mgmtApi.Upstream
+	(*RetryPolicy)(nil),                   // 15: mgmtApi.RetryPolicy
+	(*CircuitBreaker)(nil),                // 16: mgmtApi.CircuitBreaker
+	(*OriginGroup)(nil),                   // 17: mgmtApi.OriginGroup
+	(*Origin)(nil),                        // 18: mgmtApi.Origin
+	(*ErrorPage)(nil),                     // 19: mgmtApi.ErrorPage
+	(*Cors)(nil),                          // 20: mgmtApi.Cors
+	(*RedirectRule)(nil),                  // 21: mgmtApi.RedirectRule
+	(*UrlRewriteRule)(nil),                // 22: mgmtApi.UrlRewriteRule
+	(*RateLimit)(nil),                     // 23: mgmtApi.RateLimit
+	(*AccessControl)(nil),                 // 24: mgmtApi.AccessControl
+	(*TokenAuth)(nil),                     // 25: mgmtApi.TokenAuth
+	(*TokenKey)(nil),                      // 26: mgmtApi.TokenKey
+	(*Compression)(nil),                   // 27: mgmtApi.Compression
+	(*CacheKey)(nil),                      // 28: mgmtApi.CacheKey
+	(*TLSConfig)(nil),                     // 29: mgmtApi.TLSConfig
+	(*RewriteRule)(nil),                   // 30: mgmtApi.RewriteRule
+	(*CacheNode)(nil),                     // 31: mgmtApi.CacheNode
+	(*Parent)(nil),                        // 32: mgmtApi.Parent
 }
 var file_mgmtApi_proto_depIdxs = []int32{
-	9,  // 0: mgmtApi.UpdateDsListRequest.serviceList:type_name -> mgmtApi.DeliveryService
-	11, // 1: mgmtApi.UpdateConfigNodeRequest.node:type_name -> mgmtApi.CacheNode
-	9,  // 2: mgmtApi.Config.service_list:type_name -> mgmtApi.DeliveryService
-	11, // 3: mgmtApi.Config.node:type_name -> mgmtApi.CacheNode
-	10, // 4: mgmtApi.DeliveryService.rewriteRules:type_name -> mgmtApi.RewriteRule
-	0,  // 5: mgmtApi.MgmtApi.UpdateDsList:input_type -> mgmtApi.UpdateDsListRequest
-	2,  // 6: mgmtApi.MgmtApi.UpdateConfigNode:input_type -> mgmtApi.UpdateConfigNodeRequest
-	4,  // 7: mgmtApi.MgmtApi.InvalidateCache:input_type -> mgmtApi.InvalidateCacheRequest
-	6,  // 8: mgmtApi.MgmtApi.InvalidateCacheStatus:input_type -> mgmtApi.InvalidateCacheStatusRequest
-	1,  // 9: mgmtApi.MgmtApi.UpdateDsList:output_type -> mgmtApi.UpdateDsListResponse
-	3,  // 10: mgmtApi.MgmtApi.UpdateConfigNode:output_type -> mgmtApi.UpdateConfigNodeResponse
-	5,  // 11: mgmtApi.MgmtApi.InvalidateCache:output_type -> mgmtApi.InvalidateCacheResponse
-	7,  // 12: mgmtApi.MgmtApi.InvalidateCacheStatus:output_type -> mgmtApi.InvalidateCacheStatusResponse
-	9,  // [9:13] is the sub-list for method output_type
-	5,  // [5:9] is the sub-list for method input_type
-	5,  // [5:5] is the sub-list for extension type_name
-	5,  // [5:5] is the sub-list for extension extendee
-	0,  // [0:5] is the sub-list for field type_name
+	12, // 0: mgmtApi.UpdateDsListRequest.serviceList:type_name -> mgmtApi.DeliveryService
+	31, // 1: mgmtApi.UpdateConfigNodeRequest.node:type_name -> mgmtApi.CacheNode
+	10, // 2: mgmtApi.OriginHealthResponse.origins:type_name -> mgmtApi.OriginStatus
+	12, // 3: mgmtApi.Config.service_list:type_name -> mgmtApi.DeliveryService
+	31, // 4: mgmtApi.Config.node:type_name -> mgmtApi.CacheNode
+	30, // 5: mgmtApi.DeliveryService.rewriteRules:type_name -> mgmtApi.RewriteRule
+	28, // 6: mgmtApi.DeliveryService.cacheKey:type_name -> mgmtApi.CacheKey
+	29, // 7: mgmtApi.DeliveryService.tls:type_name -> mgmtApi.TLSConfig
+	27, // 8: mgmtApi.DeliveryService.compression:type_name -> mgmtApi.Compression
+	25, // 9: mgmtApi.DeliveryService.tokenAuth:type_name -> mgmtApi.TokenAuth
+	24, // 10: mgmtApi.DeliveryService.acl:type_name -> mgmtApi.AccessControl
+	23, // 11: mgmtApi.DeliveryService.rateLimit:type_name -> mgmtApi.RateLimit
+	22, // 12: mgmtApi.DeliveryService.urlRewrites:type_name -> mgmtApi.UrlRewriteRule
+	21, // 13: mgmtApi.DeliveryService.redirects:type_name -> mgmtApi.RedirectRule
+	20, // 14: mgmtApi.DeliveryService.cors:type_name -> mgmtApi.Cors
+	19, // 15: mgmtApi.DeliveryService.errorPages:type_name -> mgmtApi.ErrorPage
+	17, // 16: mgmtApi.DeliveryService.originGroup:type_name -> mgmtApi.OriginGroup
+	15, // 17: mgmtApi.DeliveryService.retry:type_name -> mgmtApi.RetryPolicy
+	16, // 18: mgmtApi.DeliveryService.circuitBreaker:type_name -> mgmtApi.CircuitBreaker
+	14, // 19: mgmtApi.DeliveryService.upstream:type_name -> mgmtApi.Upstream
+	13, // 20: mgmtApi.DeliveryService.originTLS:type_name -> mgmtApi.OriginTLS
+	18, // 21: mgmtApi.OriginGroup.origins:type_name -> mgmtApi.Origin
+	26, // 22: mgmtApi.TokenAuth.keys:type_name -> mgmtApi.TokenKey
+	19, // 23: mgmtApi.CacheNode.errorPages:type_name -> mgmtApi.ErrorPage
+	32, // 24: mgmtApi.CacheNode.parents:type_name -> mgmtApi.Parent
+	0,  // 25: mgmtApi.MgmtApi.UpdateDsList:input_type -> mgmtApi.UpdateDsListRequest
+	2,  // 26: mgmtApi.MgmtApi.UpdateConfigNode:input_type -> mgmtApi.UpdateConfigNodeRequest
+	4,  // 27: mgmtApi.MgmtApi.InvalidateCache:input_type -> mgmtApi.InvalidateCacheRequest
+	6,  // 28: mgmtApi.MgmtApi.InvalidateCacheStatus:input_type -> mgmtApi.InvalidateCacheStatusRequest
+	8,  // 29: mgmtApi.MgmtApi.OriginHealth:input_type -> mgmtApi.OriginHealthRequest
+	1,  // 30: mgmtApi.MgmtApi.UpdateDsList:output_type -> mgmtApi.UpdateDsListResponse
+	3,  // 31: mgmtApi.MgmtApi.UpdateConfigNode:output_type -> mgmtApi.UpdateConfigNodeResponse
+	5,  // 32: mgmtApi.MgmtApi.InvalidateCache:output_type -> mgmtApi.InvalidateCacheResponse
+	7,  // 33: mgmtApi.MgmtApi.InvalidateCacheStatus:output_type -> mgmtApi.InvalidateCacheStatusResponse
+	9,  // 34: mgmtApi.MgmtApi.OriginHealth:output_type -> mgmtApi.OriginHealthResponse
+	30, // [30:35] is the sub-list for method output_type
+	25, // [25:30] is the sub-list for method input_type
+	25, // [25:25] is the sub-list for extension type_name
+	25, // [25:25] is the sub-list for extension extendee
+	0,  // [0:25] is the sub-list for field type_name
 }
 
 func init() { file_mgmtApi_proto_init() }
@@ -861,7 +2871,7 @@ func file_mgmtApi_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_mgmtApi_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   12,
+			NumMessages:   33,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -23,6 +23,7 @@ const (
 	MgmtApi_UpdateConfigNode_FullMethodName      = "/mgmtApi.MgmtApi/UpdateConfigNode"
 	MgmtApi_InvalidateCache_FullMethodName       = "/mgmtApi.MgmtApi/InvalidateCache"
 	MgmtApi_InvalidateCacheStatus_FullMethodName = "/mgmtApi.MgmtApi/InvalidateCacheStatus"
+	MgmtApi_OriginHealth_FullMethodName          = "/mgmtApi.MgmtApi/OriginHealth"
 )
 
 // MgmtApiClient is the client API for MgmtApi service.
@@ -39,6 +40,8 @@ type MgmtApiClient interface {
 	InvalidateCache(ctx context.Context, in *InvalidateCacheRequest, opts ...grpc.CallOption) (*InvalidateCacheResponse, error)
 	// Gets Invalidation status
 	InvalidateCacheStatus(ctx context.Context, in *InvalidateCacheStatusRequest, opts ...grpc.CallOption) (*InvalidateCacheStatusResponse, error)
+	// Gets the health of the origins probed by the cache node
+	OriginHealth(ctx context.Context, in *OriginHealthRequest, opts ...grpc.CallOption) (*OriginHealthResponse, error)
 }
 
 type mgmtApiClient struct {
@@ -89,6 +92,16 @@ func (c *mgmtApiClient) InvalidateCacheStatus(ctx context.Context, in *Invalidat
 	return out, nil
 }
 
+func (c *mgmtApiClient) OriginHealth(ctx context.Context, in *OriginHealthRequest, opts ...grpc.CallOption) (*OriginHealthResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(OriginHealthResponse)
+	err := c.cc.Invoke(ctx, MgmtApi_OriginHealth_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // MgmtApiServer is the server API for MgmtApi service.
 // All implementations must embed UnimplementedMgmtApiServer
 // for forward compatibility.
@@ -103,6 +116,8 @@ type MgmtApiServer interface {
 	InvalidateCache(context.Context, *InvalidateCacheRequest) (*InvalidateCacheResponse, error)
 	// Gets Invalidation status
 	InvalidateCacheStatus(context.Context, *InvalidateCacheStatusRequest) (*InvalidateCacheStatusResponse, error)
+	// Gets the health of the origins probed by the cache node
+	OriginHealth(context.Context, *OriginHealthRequest) (*OriginHealthResponse, error)
 	mustEmbedUnimplementedMgmtApiServer()
 }
 
@@ -125,6 +140,9 @@ func (UnimplementedMgmtApiServer) InvalidateCache(context.Context, *InvalidateCa
 func (UnimplementedMgmtApiServer) InvalidateCacheStatus(context.Context, *InvalidateCacheStatusRequest) (*InvalidateCacheStatusResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method InvalidateCacheStatus not implemented")
 }
+func (UnimplementedMgmtApiServer) OriginHealth(context.Context, *OriginHealthRequest) (*OriginHealthResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method OriginHealth not implemented")
+}
 func (UnimplementedMgmtApiServer) mustEmbedUnimplementedMgmtApiServer() {}
 func (UnimplementedMgmtApiServer) testEmbeddedByValue()                 {}
 
@@ -218,6 +236,24 @@ func _MgmtApi_InvalidateCacheStatus_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MgmtApi_OriginHealth_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OriginHealthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MgmtApiServer).OriginHealth(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MgmtApi_OriginHealth_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MgmtApiServer).OriginHealth(ctx, req.(*OriginHealthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // MgmtApi_ServiceDesc is the grpc.ServiceDesc for MgmtApi service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -241,6 +277,10 @@ var MgmtApi_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "InvalidateCacheStatus",
 			Handler:    _MgmtApi_InvalidateCacheStatus_Handler,
 		},
+		{
+			MethodName: "OriginHealth",
+			Handler:    _MgmtApi_OriginHealth_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "mgmtApi.proto",
@@ -2,13 +2,20 @@ package apiServer
 
 import (
 	"bytes"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
 	"math/rand"
 	"net/http"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"strings"
 	"time"
+	"unicode"
 
 	"github.com/hcl/cdn/common/config"
 	"github.com/hcl/cdn/configServer/cacheCommander"
@@ -27,6 +34,8 @@ func RegisterHandlers(r *mux.Router) {
 	r.HandleFunc("/ds/{name}", handleDeliveryServiceByNameGet).Methods("GET")
 	r.HandleFunc("/ds/{name}", handleDeliveryServiceByNamePut).Methods("PUT")
 	r.HandleFunc("/ds/{name}", handleDeliveryServiceByNameDelete).Methods("DELETE")
+	r.HandleFunc("/ds/{name}/redirects", handleRedirectsGet).Methods("GET")
+	r.HandleFunc("/ds/{name}/redirects", handleRedirectsPut).Methods("PUT")
 	slog.Info("Added /cn")
 	r.HandleFunc("/cn", handleCacheNodesGet).Methods("GET")
 	r.HandleFunc("/cn", handleCacheNodesPost).Methods("POST")
@@ -66,11 +75,9 @@ func handleDeliveryServicesPost(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Missing required fields", http.StatusBadRequest)
 		return
 	}
-	for _, rule := range newService.RewriteRules {
-		if rule.HeaderName == "" || rule.Operation < 0 || rule.Operation > 2 {
-			http.Error(w, "Invalid rewrite rule", http.StatusBadRequest)
-			return
-		}
+	if msg := validDeliveryService(&newService); msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
 	}
 	if inMemConfig == nil {
 		http.Error(w, "Internal error: InMemConfig not initialized", http.StatusInternalServerError)
@@ -93,6 +100,284 @@ func handleDeliveryServicesPost(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("Delivery service added"))
 }
+
+// validDeliveryService checks a DS added or updated, it returns the error message of the first invalid
+// setting or "" if the DS is valid
+func validDeliveryService(ds *config.DeliveryService) string {
+	for _, rule := range ds.RewriteRules {
+		if !validRewriteRule(&rule) {
+			return "Invalid rewrite rule"
+		}
+	}
+	if ds.StaleWhileRevalidate < 0 || ds.StaleIfError < 0 {
+		return "Invalid stale serving duration"
+	}
+	if ds.CacheKey.QueryMode < config.CacheKeyQueryIgnoreAll || ds.CacheKey.QueryMode > config.CacheKeyQueryDenyList {
+		return "Invalid cache key query mode"
+	}
+	if ds.TLS.Certificate != "" || ds.TLS.PrivateKey != "" {
+		if _, err := tls.X509KeyPair([]byte(ds.TLS.Certificate), []byte(ds.TLS.PrivateKey)); err != nil {
+			return "Invalid TLS certificate"
+		}
+	}
+	if ds.Compression.MinSize < 0 {
+		return "Invalid compression minimum size"
+	}
+	if !validURLRewrites(ds.URLRewrites) {
+		return "Invalid URL rewrite rule"
+	}
+	if !validRedirects(ds.Redirects) {
+		return "Invalid redirect rule"
+	}
+	if redirectLoop(ds) {
+		return "Redirect loop"
+	}
+	if !validCORS(&ds.CORS) {
+		return "Invalid CORS policy"
+	}
+	if !validErrorPages(ds.ErrorPages, true) {
+		return "Invalid error page"
+	}
+	if !validTokenAuth(&ds.TokenAuth) {
+		return "Invalid token authentication keys"
+	}
+	if !validAccessControl(&ds.ACL) {
+		return "Invalid access control list"
+	}
+	if !validRateLimit(&ds.RateLimit) {
+		return "Invalid rate limit"
+	}
+	if !validOriginGroup(&ds.OriginGroup) {
+		return "Invalid origin group"
+	}
+	if !validRetry(&ds.Retry) || ds.CircuitBreaker.FailureThreshold < 0 || ds.CircuitBreaker.Cooldown < 0 {
+		return "Invalid retry policy or circuit breaker"
+	}
+	if !validUpstream(&ds.Upstream) {
+		return "Invalid upstream timeouts"
+	}
+	if !validOriginTLS(&ds.OriginTLS) {
+		return "Invalid origin TLS settings"
+	}
+	return ""
+}
+
+// validRewriteRule checks the operation, the phase and the regexes of the header rewrite rule
+func validRewriteRule(rule *config.RewriteRule) bool {
+	if rule.HeaderName == "" || rule.Operation < config.HdrReWriteOpAdd || rule.Operation > config.HdrReWriteOpReplace {
+		return false
+	}
+	if rule.Phase < config.HdrReWritePhaseResponse || rule.Phase > config.HdrReWritePhaseClient {
+		return false
+	}
+	if (rule.Operation == config.HdrReWriteOpReplace && rule.Pattern == "") || (rule.IfHeaderMatch != "" && rule.IfHeader == "") {
+		return false
+	}
+	for _, expr := range []string{rule.Pattern, rule.IfHeaderMatch, rule.IfPath} {
+		if _, err := regexp.Compile(expr); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// validURLRewrites checks the patterns compile and the replacements are given
+func validURLRewrites(rules []config.URLRewriteRule) bool {
+	for _, rule := range rules {
+		if rule.Pattern == "" || rule.Replacement == "" {
+			return false
+		}
+		if _, err := regexp.Compile(rule.Pattern); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// validCORS checks an enabled policy allows some origins and the methods & headers are tokens
+func validCORS(cors *config.CORS) bool {
+	if cors.MaxAge < 0 || (cors.Enabled && len(cors.AllowOrigins) == 0) {
+		return false
+	}
+	for _, list := range [][]string{cors.AllowOrigins, cors.AllowMethods, cors.AllowHeaders, cors.ExposeHeaders} {
+		for _, item := range list {
+			if item == "" || strings.ContainsAny(item, " ,\t") {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// error statuses of the pages, a code or a class
+var errorPageStatus = regexp.MustCompile(`^[45]([0-9][0-9]|xx|XX)$`)
+
+// validErrorPages checks the pages are given for error statuses and have a body, pages of the cache
+// nodes are inline as there is no DS to fetch them from
+func validErrorPages(pages []config.ErrorPage, allowURL bool) bool {
+	for _, page := range pages {
+		if !errorPageStatus.MatchString(page.Status) {
+			return false
+		}
+		if page.URL != "" && (!allowURL || !strings.HasPrefix(page.URL, "/")) {
+			return false
+		}
+		if page.URL == "" && page.Content == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// validTokenAuth checks the signing keys, an enabled policy needs at least one key and key ids are unique
+func validTokenAuth(tokenAuth *config.TokenAuth) bool {
+	if tokenAuth.Enabled && len(tokenAuth.Keys) == 0 {
+		return false
+	}
+	ids := make(map[string]bool)
+	for _, key := range tokenAuth.Keys {
+		if key.Secret == "" || ids[key.ID] {
+			return false
+		}
+		ids[key.ID] = true
+	}
+	return true
+}
+
+// validAccessControl checks the CIDRs and the two letter country codes of the lists
+func validAccessControl(acl *config.AccessControl) bool {
+	for _, cidrs := range [][]string{acl.AllowCIDRs, acl.DenyCIDRs} {
+		for _, cidr := range cidrs {
+			if _, err := netip.ParsePrefix(cidr); err != nil {
+				return false
+			}
+		}
+	}
+	for _, countries := range [][]string{acl.AllowCountries, acl.DenyCountries} {
+		for _, country := range countries {
+			if len(country) != 2 || strings.IndexFunc(country, func(r rune) bool { return !unicode.IsLetter(r) }) >= 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// validRateLimit checks the limits are not negative, 0 disables a limit
+func validRateLimit(limit *config.RateLimit) bool {
+	return limit.ClientRate >= 0 && limit.ClientBurst >= 0 && limit.DSRate >= 0 && limit.DSBurst >= 0 && limit.MaxConcurrent >= 0
+}
+
+// validOriginGroup checks the origins are distinct http(s) hosts with at least one primary, the probe
+// settings are not negative and the failover statuses are 5xx. The origins have no path, the path of the
+// OriginURL maps the paths whatever the origin.
+func validOriginGroup(group *config.OriginGroup) bool {
+	if group.HealthInterval < 0 || group.HealthTimeout < 0 || group.UnhealthyAfter < 0 || group.HealthyAfter < 0 {
+		return false
+	}
+	if group.HealthPath != "" && !strings.HasPrefix(group.HealthPath, "/") {
+		return false
+	}
+	urls := make(map[string]bool)
+	primary := len(group.Origins) == 0
+	for _, origin := range group.Origins {
+		u, err := url.Parse(origin.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || origin.Weight < 0 || urls[u.Host] {
+			return false
+		}
+		if (u.Path != "" && u.Path != "/") || u.RawQuery != "" {
+			return false
+		}
+		urls[u.Host] = true
+		primary = primary || !origin.Backup
+	}
+	for _, status := range group.FailoverStatuses {
+		if status < 500 || status > 599 {
+			return false
+		}
+	}
+	return primary
+}
+
+// validRetry checks the retried statuses are 5xx and the errors are known
+func validRetry(retry *config.RetryPolicy) bool {
+	if retry.MaxAttempts < 0 || retry.Backoff < 0 || retry.MaxBackoff < 0 {
+		return false
+	}
+	for _, status := range retry.Statuses {
+		if status < 500 || status > 599 {
+			return false
+		}
+	}
+	for _, kind := range retry.Errors {
+		if kind != "connect" && kind != "timeout" && kind != "reset" {
+			return false
+		}
+	}
+	return true
+}
+
+// validUpstream checks the timeouts & the connections of the upstream fetches are not negative
+func validUpstream(upstream *config.Upstream) bool {
+	return upstream.ConnectTimeout >= 0 && upstream.TLSHandshakeTimeout >= 0 && upstream.TTFBTimeout >= 0 &&
+		upstream.IdleReadTimeout >= 0 && upstream.TotalTimeout >= 0 && upstream.MaxConnsPerHost >= 0
+}
+
+// validOriginTLS checks the CA bundle holds certificates, the minimum version is known and the client
+// certificate matches its key
+func validOriginTLS(originTLS *config.OriginTLS) bool {
+	if originTLS.CABundle != "" && !x509.NewCertPool().AppendCertsFromPEM([]byte(originTLS.CABundle)) {
+		return false
+	}
+	switch originTLS.MinVersion {
+	case "", "1.0", "1.1", "1.2", "1.3":
+	default:
+		return false
+	}
+	if originTLS.ClientCertificate != "" || originTLS.ClientKey != "" {
+		if _, err := tls.X509KeyPair([]byte(originTLS.ClientCertificate), []byte(originTLS.ClientKey)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// validCacheNode checks a cache node added or updated, it returns the error message of the first invalid
+// setting or "" if the node is valid
+func validCacheNode(node *config.CacheNode) string {
+	if node.TLSPort < 0 {
+		return "Invalid TLS port"
+	}
+	if node.MaxConcurrentStreams < 0 {
+		return "Invalid max concurrent streams"
+	}
+	if node.CollapseTimeout < 0 {
+		return "Invalid collapse timeout"
+	}
+	if !validErrorPages(node.ErrorPages, false) {
+		return "Invalid error page"
+	}
+	if !validParents(node) {
+		return "Invalid parents"
+	}
+	return ""
+}
+
+// validParents checks the parents of the node are distinct, have a port and are not the node itself
+func validParents(node *config.CacheNode) bool {
+	seen := make(map[config.Parent]bool)
+	for _, parent := range node.Parents {
+		if parent.IP == "" || parent.Port <= 0 || parent.Port > 65535 || seen[parent] {
+			return false
+		}
+		if parent.IP == node.IP && parent.Port == node.Port {
+			return false
+		}
+		seen[parent] = true
+	}
+	return true
+}
+
 func handleDeliveryServiceByNameGet(w http.ResponseWriter, r *http.Request) {
 	if inMemConfig == nil {
 		http.Error(w, "Internal error: InMemConfig not initialized", http.StatusInternalServerError)
@@ -106,9 +391,42 @@ func handleDeliveryServiceByNameGet(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Not Found", http.StatusNotFound)
 		return
 	}
+	redactSecrets(resp)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(resp)
 }
+
+// redactSecrets blanks the private keys & token secrets of a copy of the DS, they are only pushed to the
+// cache nodes and saved to the file
+func redactSecrets(ds *config.DeliveryService) {
+	ds.TLS.PrivateKey = ""
+	ds.OriginTLS.ClientKey = ""
+	keys := make([]config.TokenKey, len(ds.TokenAuth.Keys))
+	for idx, key := range ds.TokenAuth.Keys {
+		keys[idx] = config.TokenKey{ID: key.ID}
+	}
+	ds.TokenAuth.Keys = keys
+}
+
+// keepSecrets fills the secrets left out of an updated DS, as returned by GET, with the current ones
+func keepSecrets(updated *config.DeliveryService, current *config.DeliveryService) {
+	if updated.TLS.PrivateKey == "" && updated.TLS.Certificate != "" {
+		updated.TLS.PrivateKey = current.TLS.PrivateKey
+	}
+	if updated.OriginTLS.ClientKey == "" && updated.OriginTLS.ClientCertificate != "" {
+		updated.OriginTLS.ClientKey = current.OriginTLS.ClientKey
+	}
+	for idx := range updated.TokenAuth.Keys {
+		if updated.TokenAuth.Keys[idx].Secret != "" {
+			continue
+		}
+		for _, key := range current.TokenAuth.Keys {
+			if key.ID == updated.TokenAuth.Keys[idx].ID {
+				updated.TokenAuth.Keys[idx].Secret = key.Secret
+			}
+		}
+	}
+}
 func handleDeliveryServiceByNamePut(w http.ResponseWriter, r *http.Request) {
 	if inMemConfig == nil {
 		http.Error(w, "Internal error: InMemConfig not initialized", http.StatusInternalServerError)
@@ -126,6 +444,13 @@ func handleDeliveryServiceByNamePut(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Name in the URL does not match the name in the body", http.StatusBadRequest)
 		return
 	}
+	if current, err := inMemConfig.GetDsDetail(name); err == nil {
+		keepSecrets(&updatedService, current)
+	}
+	if msg := validDeliveryService(&updatedService); msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
 	err := inMemConfig.UpdateDs(&updatedService)
 	if err != nil {
 		http.Error(w, "Not Found", http.StatusNotFound)
@@ -193,6 +518,11 @@ func handleCacheNodesPost(w http.ResponseWriter, r *http.Request) {
         http.Error(w, "Missing required fields", http.StatusBadRequest)
         return
     }
+    if msg := validCacheNode(&newNode); msg != "" {
+        slog.Error("CN Post : Invalid fields", "data", newNode, "error", msg)
+        http.Error(w, msg, http.StatusBadRequest)
+        return
+    }
     if inMemConfig == nil {
         slog.Error("CN Post : inMemory Config not available")
         http.Error(w, "Internal error: InMemConfig not initialized", http.StatusInternalServerError)
@@ -242,6 +572,10 @@ func handleCacheNodeByNamePut(w http.ResponseWriter, r *http.Request) {
         http.Error(w, "Name in the URL does not match the name in the body", http.StatusBadRequest)
         return
     }
+    if msg := validCacheNode(&updatedNode); msg != "" {
+        http.Error(w, msg, http.StatusBadRequest)
+        return
+    }
     err := inMemConfig.UpdateCn(&updatedNode)
     if err != nil {
         http.Error(w, "Not Found", http.StatusNotFound)
@@ -227,6 +227,69 @@ func TestHandleDeliveryServiceByNamePut(t *testing.T) {
 	log.Println("TestHandleDeliveryServiceByNamePut completed successfully")
 }
 
+func TestHandleDeliveryServiceByNamePutInvalid(t *testing.T) {
+	setup()
+	router := mux.NewRouter()
+	router.HandleFunc("/ds/{name}", handleDeliveryServiceByNamePut).Methods("PUT")
+
+	// PUT is validated like POST
+	for _, service := range []config.DeliveryService{
+		{StaleIfError: -1},
+		{CacheKey: config.CacheKey{QueryMode: 9}},
+		{Compression: config.Compression{MinSize: -1}},
+		{TLS: config.TLSConfig{Certificate: "bad", PrivateKey: "bad"}},
+		{OriginGroup: config.OriginGroup{Origins: []config.Origin{{URL: "http://origin1.com"}, {URL: "http://backup.com/v2", Backup: true}}}},
+	} {
+		service.Name, service.ClientURL, service.OriginURL = "service1", "http://client1.com", "http://origin1.com"
+		body, _ := json.Marshal(service)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest("PUT", "/ds/service1", bytes.NewBuffer(body)))
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("expected %v rejected, got %v", service, rr.Code)
+		}
+	}
+	if version, _ := inMemConfig.GetDsNames(); version != 1 {
+		t.Errorf("expected no update, got version %d", version)
+	}
+}
+
+func TestHandleDeliveryServiceSecrets(t *testing.T) {
+	setup()
+	inMemConfig.UpdateDs(&config.DeliveryService{
+		Name:      "service1",
+		ClientURL: "http://client1.com",
+		OriginURL: "http://origin1.com",
+		TokenAuth: config.TokenAuth{Enabled: true, Keys: []config.TokenKey{{ID: "k1", Secret: "s1"}}},
+	})
+	router := mux.NewRouter()
+	router.HandleFunc("/ds/{name}", handleDeliveryServiceByNameGet).Methods("GET")
+	router.HandleFunc("/ds/{name}", handleDeliveryServiceByNamePut).Methods("PUT")
+
+	// GET does not return the secrets
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", "/ds/service1", nil))
+	var service config.DeliveryService
+	if err := json.NewDecoder(rr.Body).Decode(&service); err != nil {
+		t.Fatal(err)
+	}
+	if len(service.TokenAuth.Keys) != 1 || service.TokenAuth.Keys[0].ID != "k1" || service.TokenAuth.Keys[0].Secret != "" {
+		t.Errorf("expected the token secret redacted, got %v", service.TokenAuth.Keys)
+	}
+
+	// PUT of the DS as returned by GET keeps the secrets
+	service.OriginURL = "http://origin1-updated.com"
+	body, _ := json.Marshal(service)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("PUT", "/ds/service1", bytes.NewBuffer(body)))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+	stored, _ := inMemConfig.GetDsDetail("service1")
+	if stored.OriginURL != "http://origin1-updated.com" || stored.TokenAuth.Keys[0].Secret != "s1" {
+		t.Errorf("expected the update with the secret kept, got %v", stored)
+	}
+}
+
 func TestHandleDeliveryServiceByNameDelete(t *testing.T) {
 	setup()
 	log.Println("Starting TestHandleDeliveryServiceByNameDelete")
@@ -318,3 +381,34 @@ func TestHandleCacheNodesGet(t *testing.T) {
 }
 
 
+
+func TestHandleCacheNodeInvalid(t *testing.T) {
+	setup()
+	router := mux.NewRouter()
+	router.HandleFunc("/cn", handleCacheNodesPost).Methods("POST")
+	router.HandleFunc("/cn/{name}", handleCacheNodeByNamePut).Methods("PUT")
+
+	// POST and PUT validate the node alike
+	for _, node := range []config.CacheNode{
+		{TLSPort: -1},
+		{MaxConcurrentStreams: -1},
+		{CollapseTimeout: -1},
+		{Parents: []config.Parent{{IP: "127.0.0.1", Port: 0}}},
+	} {
+		node.Name, node.IP, node.Port, node.Type = "node1", "127.0.0.2", 8080, config.CacheNodeEdge
+		body, _ := json.Marshal(node)
+		for _, req := range []*http.Request{
+			httptest.NewRequest("POST", "/cn", bytes.NewBuffer(body)),
+			httptest.NewRequest("PUT", "/cn/node1", bytes.NewBuffer(body)),
+		} {
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+			if rr.Code != http.StatusBadRequest {
+				t.Errorf("expected %s of %+v rejected, got %v", req.Method, node, rr.Code)
+			}
+		}
+	}
+	if version, _ := inMemConfig.GetCnNames(); version != 1 {
+		t.Errorf("expected no update, got version %d", version)
+	}
+}
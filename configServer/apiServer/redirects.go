@@ -0,0 +1,143 @@
+package apiServer
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/hcl/cdn/common/config"
+	"github.com/hcl/cdn/configServer/configPusher"
+	"github.com/hcl/cdn/configServer/configSaver"
+)
+
+// redirects longer than this on the host of the DS are reported as a loop
+const maxRedirectHops = 10
+
+// the groups of the targets, probed with a path segment when checking for loops
+var targetGroups = regexp.MustCompile(`\$(\d+|\{\w+\})`)
+
+// validRedirects checks the sources compile, the targets are given and the status codes are redirects
+func validRedirects(rules []config.RedirectRule) bool {
+	for _, rule := range rules {
+		if rule.Source == "" || rule.Target == "" {
+			return false
+		}
+		if _, err := regexp.Compile(rule.Source); err != nil {
+			return false
+		}
+		switch rule.StatusCode {
+		case 0, http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// redirectPath returns the path the client asks for after the redirect to location, false when the
+// location leaves the host of the DS
+func redirectPath(location string, host string) (string, bool) {
+	target, err := url.Parse(location)
+	if err != nil {
+		return "", false
+	}
+	if target.Host != "" && !strings.EqualFold(target.Host, host) {
+		return "", false
+	}
+	return target.EscapedPath(), true
+}
+
+// redirectLoop follows the redirects from the target of each rule, as the edge answers them, and reports
+// a chain coming back to a path or longer than maxRedirectHops. The groups of the first target are probed with "x".
+// The rules must be valid.
+func redirectLoop(ds *config.DeliveryService) bool {
+	clientURL, err := url.Parse(ds.ClientURL)
+	if err != nil {
+		return false
+	}
+	sources := make([]*regexp.Regexp, len(ds.Redirects))
+	for i, rule := range ds.Redirects {
+		sources[i] = regexp.MustCompile(rule.Source)
+	}
+
+	for _, rule := range ds.Redirects {
+		path, ok := redirectPath(targetGroups.ReplaceAllString(rule.Target, "x"), clientURL.Host)
+		seen := make(map[string]bool)
+		for hops := 0; ok; hops++ {
+			if seen[path] || hops >= maxRedirectHops {
+				slog.Info("Redirect loop", "ds", ds.Name, "source", rule.Source, "path", path)
+				return true
+			}
+			seen[path] = true
+			ok = false
+			for i, next := range sources {
+				if match := next.FindStringSubmatchIndex(path); match != nil {
+					location := string(next.ExpandString(nil, ds.Redirects[i].Target, path, match))
+					path, ok = redirectPath(location, clientURL.Host)
+					break
+				}
+			}
+		}
+	}
+	return false
+}
+
+func handleRedirectsGet(w http.ResponseWriter, r *http.Request) {
+	if inMemConfig == nil {
+		http.Error(w, "Internal error: InMemConfig not initialized", http.StatusInternalServerError)
+		return
+	}
+	name := mux.Vars(r)["name"]
+	ds, err := inMemConfig.GetDsDetail(name)
+	if err != nil {
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return
+	}
+	rules := ds.Redirects
+	if rules == nil {
+		rules = []config.RedirectRule{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rules)
+}
+
+// handleRedirectsPut replaces the redirect rules of the DS
+func handleRedirectsPut(w http.ResponseWriter, r *http.Request) {
+	if inMemConfig == nil {
+		http.Error(w, "Internal error: InMemConfig not initialized", http.StatusInternalServerError)
+		return
+	}
+	name := mux.Vars(r)["name"]
+	slog.Info("Handling PUT request for redirects", "ds", name)
+	var rules []config.RedirectRule
+	if err := json.NewDecoder(r.Body).Decode(&rules); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	if !validRedirects(rules) {
+		http.Error(w, "Invalid redirect rule", http.StatusBadRequest)
+		return
+	}
+	ds, err := inMemConfig.GetDsDetail(name)
+	if err != nil {
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return
+	}
+	ds.Redirects = rules
+	if redirectLoop(ds) {
+		http.Error(w, "Redirect loop", http.StatusBadRequest)
+		return
+	}
+	if err := inMemConfig.UpdateDs(ds); err != nil {
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return
+	}
+	configSaver.SaveDSToFile()
+	configPusher.PushDsUpdate(r.Context())
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Redirects updated"))
+}
@@ -0,0 +1,72 @@
+package apiServer
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/hcl/cdn/common/config"
+)
+
+func TestRedirectLoop(t *testing.T) {
+	cases := []struct {
+		rules []config.RedirectRule
+		loop  bool
+	}{
+		{[]config.RedirectRule{{Source: `^/old/(.*)$`, Target: "/new/$1"}}, false},
+		{[]config.RedirectRule{{Source: `^/old/(.*)$`, Target: "/new/$1"}, {Source: `^/new/(.*)$`, Target: "/old/$1"}}, true},
+		{[]config.RedirectRule{{Source: `^/(.*)$`, Target: "/index/$1"}}, true},
+		{[]config.RedirectRule{{Source: `^/a$`, Target: "/b"}, {Source: `^/b$`, Target: "/c"}, {Source: `^/c$`, Target: "/a"}}, true},
+		{[]config.RedirectRule{{Source: `^/(.*)$`, Target: "https://www.client1.com/$1"}}, false},
+		{[]config.RedirectRule{{Source: `^/(.*)$`, Target: "https://client1.com/$1"}}, true},
+		{[]config.RedirectRule{{Source: `^/promo$`, Target: "/offers?id=1", PreserveQuery: true}}, false},
+	}
+	for i, c := range cases {
+		ds := &config.DeliveryService{Name: "service1", ClientURL: "http://client1.com", Redirects: c.rules}
+		if got := redirectLoop(ds); got != c.loop {
+			t.Errorf("case %d: got loop %v, want %v", i, got, c.loop)
+		}
+	}
+}
+
+func TestHandleRedirectsPut(t *testing.T) {
+	setup()
+	router := mux.NewRouter()
+	router.HandleFunc("/ds/{name}/redirects", handleRedirectsPut).Methods("PUT")
+	router.HandleFunc("/ds/{name}/redirects", handleRedirectsGet).Methods("GET")
+
+	put := func(name string, rules []config.RedirectRule) int {
+		body, _ := json.Marshal(rules)
+		req, _ := http.NewRequest("PUT", "/ds/"+name+"/redirects", bytes.NewBuffer(body))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr.Code
+	}
+	if code := put("service1", []config.RedirectRule{{Source: `^/a$`, Target: "/b", StatusCode: 200}}); code != http.StatusBadRequest {
+		t.Errorf("expected invalid status code to be rejected, got %d", code)
+	}
+	if code := put("service1", []config.RedirectRule{{Source: `^/a$`, Target: "/b"}, {Source: `^/b$`, Target: "/a"}}); code != http.StatusBadRequest {
+		t.Errorf("expected loop to be rejected, got %d", code)
+	}
+	if code := put("unknown", []config.RedirectRule{}); code != http.StatusNotFound {
+		t.Errorf("expected unknown DS to be not found, got %d", code)
+	}
+	rules := []config.RedirectRule{{Source: `^/blog/(.*)$`, Target: "https://blog.client1.com/$1", StatusCode: 301, PreserveQuery: true}}
+	if code := put("service1", rules); code != http.StatusOK {
+		t.Fatalf("expected redirects to be updated, got %d", code)
+	}
+
+	req, _ := http.NewRequest("GET", "/ds/service1/redirects", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	var got []config.RedirectRule
+	if err := json.NewDecoder(rr.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0] != rules[0] {
+		t.Errorf("unexpected redirects %v", got)
+	}
+}
@@ -3,6 +3,7 @@ module github.com/hcl/cdn
 go 1.23.4
 
 require (
+	github.com/andybalholm/brotli v1.1.1
 	github.com/gorilla/mux v1.8.1
 	google.golang.org/grpc v1.69.4
 	google.golang.org/protobuf v1.36.3
@@ -20,7 +21,7 @@ require (
 
 require (
 	github.com/prometheus/client_golang v1.20.5
-	golang.org/x/net v0.34.0 // indirect
+	golang.org/x/net v0.34.0
 	golang.org/x/sys v0.29.0 // indirect
 	golang.org/x/text v0.21.0 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20250115164207-1a7da9e5054f // indirect